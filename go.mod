@@ -7,8 +7,11 @@ toolchain go1.24.7
 require (
 	github.com/BurntSushi/toml v1.5.0
 	github.com/gdamore/tcell/v2 v2.7.4
+	github.com/godbus/dbus/v5 v5.2.2
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
 	github.com/rivo/tview v0.0.0-20241103174730-c76f7879f592
+	go.etcd.io/bbolt v1.4.3
+	golang.org/x/term v0.29.0
 )
 
 require (
@@ -17,6 +20,5 @@ require (
 	github.com/mattn/go-runewidth v0.0.16 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	golang.org/x/sys v0.36.0 // indirect
-	golang.org/x/term v0.29.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 )
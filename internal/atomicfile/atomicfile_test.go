@@ -0,0 +1,95 @@
+package atomicfile
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func entries(t *testing.T, dir string) []string {
+	t.Helper()
+	list, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range list {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestWriteFileReplaces(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sub", "state.json")
+
+	if err := WriteFile(path, []byte("one"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []byte("two"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "two" {
+		t.Fatalf("contents = %q, %v", data, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("mode = %o, want 600", perm)
+	}
+	if names := entries(t, filepath.Dir(path)); len(names) != 1 {
+		t.Fatalf("temporary files left behind: %v", names)
+	}
+}
+
+func TestWriteFailureKeepsOriginal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	if err := WriteFile(path, []byte("original"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	boom := errors.New("disk full")
+	err := Write(path, 0o644, func(w io.Writer) error {
+		_, _ = w.Write([]byte("partial"))
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("Write() = %v, want %v", err, boom)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "original" {
+		t.Fatalf("original replaced by %q", data)
+	}
+	if names := entries(t, dir); len(names) != 1 {
+		t.Fatalf("temporary files left behind: %v", names)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	if err := os.WriteFile(src, []byte("backup"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, []byte("corrupt"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Copy(dst, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "backup" {
+		t.Fatalf("dst = %q", data)
+	}
+	if err := Copy(dst, filepath.Join(dir, "missing"), 0o644); err == nil {
+		t.Fatal("Copy from a missing file succeeded")
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "backup" {
+		t.Fatalf("failed copy changed dst to %q", data)
+	}
+}
@@ -0,0 +1,294 @@
+// Package cli implements the non-interactive subcommands
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/daemon"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/library"
+	"github.com/IvelOt/youtui-player/internal/search"
+)
+
+const (
+	ExitOK         = 0
+	ExitFailed     = 1
+	ExitUsage      = 2
+	ExitNotRunning = 3
+)
+
+const ctlUsage = `usage: youtui-player ctl <command> [args]
+
+commands:
+  play               resume, or start the playlist
+  pause              pause playback
+  toggle             toggle pause
+  stop               stop playback
+  next               skip to the next track
+  prev               go back to the previous track
+  seek <[+|-]time>   seek by or to a position (30, +30, -10, 1:30)
+  vol <[+|-]n>       set or change the volume (0-130)
+  status [--json]    print the current track and position
+  add <url|file>...  append videos, playlists or local files to the playlist
+  queue <query>      search YouTube and append the first result
+
+exit status: 0 ok, 1 command failed, 2 usage error, 3 no running instance
+`
+
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+func Ctl(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(stderr, ctlUsage)
+		if len(args) == 0 {
+			return ExitUsage
+		}
+		return ExitOK
+	}
+
+	client, err := daemon.Dial(config.GetSocketPath())
+	if err != nil {
+		fmt.Fprintf(stderr, "youtui-player: %v\n", err)
+		return ExitNotRunning
+	}
+	defer client.Close()
+
+	return exitCode(runCtl(client, args[0], args[1:], stdout), ctlUsage, stderr)
+}
+
+func exitCode(err error, usageText string, stderr io.Writer) int {
+	var usage usageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &usage):
+		fmt.Fprintf(stderr, "youtui-player: %v\n\n%s", err, usageText)
+		return ExitUsage
+	case errors.Is(err, daemon.ErrNotRunning):
+		fmt.Fprintf(stderr, "youtui-player: %v\n", err)
+		return ExitNotRunning
+	default:
+		fmt.Fprintf(stderr, "youtui-player: %v\n", err)
+		return ExitFailed
+	}
+}
+
+func runCtl(c engine.Controller, cmd string, args []string, stdout io.Writer) error {
+	switch cmd {
+	case "play":
+		return engine.Resume(c)
+	case "pause":
+		return engine.Pause(c)
+	case "toggle":
+		return c.TogglePause()
+	case "stop":
+		return c.Stop()
+	case "next":
+		return c.Next()
+	case "prev", "previous":
+		return c.Previous()
+	case "seek":
+		if len(args) != 1 {
+			return usageError("seek needs a position")
+		}
+		seconds, relative, err := parseSeek(args[0])
+		if err != nil {
+			return err
+		}
+		return c.Seek(seconds, !relative)
+	case "vol", "volume":
+		if len(args) != 1 {
+			return usageError("vol needs a value")
+		}
+		return setVolume(c, args[0])
+	case "status":
+		return printStatus(c, args, stdout)
+	case "add":
+		if len(args) == 0 {
+			return usageError("add needs at least one URL or file")
+		}
+		return addTracks(c, args, stdout)
+	case "queue":
+		query := strings.TrimSpace(strings.Join(args, " "))
+		if query == "" {
+			return usageError("queue needs a search query")
+		}
+		return queueSearch(c, query, stdout)
+	}
+	return usageError("unknown command: " + cmd)
+}
+
+func parseSeek(arg string) (seconds float64, relative bool, err error) {
+	sign := 1.0
+	switch {
+	case strings.HasPrefix(arg, "+"):
+		relative, arg = true, arg[1:]
+	case strings.HasPrefix(arg, "-"):
+		relative, sign, arg = true, -1, arg[1:]
+	}
+
+	for part := range strings.SplitSeq(arg, ":") {
+		n, perr := strconv.ParseFloat(part, 64)
+		if perr != nil || n < 0 {
+			return 0, false, usageError("invalid position: " + arg)
+		}
+		seconds = seconds*60 + n
+	}
+	return sign * seconds, relative, nil
+}
+
+func setVolume(c engine.Controller, arg string) error {
+	n, err := strconv.Atoi(strings.TrimSuffix(arg, "%"))
+	if err != nil {
+		return usageError("invalid volume: " + arg)
+	}
+	if strings.HasPrefix(arg, "+") || strings.HasPrefix(arg, "-") {
+		st, err := c.State()
+		if err != nil {
+			return err
+		}
+		n += st.Volume
+	}
+	return c.SetVolume(min(max(n, 0), engine.MaxVolume))
+}
+
+func printStatus(c engine.Controller, args []string, stdout io.Writer) error {
+	asJSON := false
+	for _, arg := range args {
+		if arg != "--json" {
+			return usageError("unknown status flag: " + arg)
+		}
+		asJSON = true
+	}
+
+	st, err := c.State()
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(st)
+	}
+
+	if !st.Playing {
+		fmt.Fprintln(stdout, "stopped")
+		return nil
+	}
+	state := "playing"
+	if st.Paused {
+		state = "paused"
+	}
+	title := st.Track.Title
+	if st.Track.Author != "" {
+		title += " - " + st.Track.Author
+	}
+	fmt.Fprintf(stdout, "%s  %s/%s  vol %d%%  %s\n", state,
+		formatTime(st.Position), formatTime(st.Duration), st.Volume, title)
+	return nil
+}
+
+func formatTime(seconds float64) string {
+	if seconds <= 0 {
+		return "00:00"
+	}
+	return search.HumanDuration(int(seconds))
+}
+
+func addTracks(c engine.Controller, args []string, stdout io.Writer) error {
+	var tracks []engine.Track
+	for _, arg := range args {
+		found, err := resolveTracks(arg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+		tracks = append(tracks, found...)
+	}
+	if err := c.AddTracks(tracks); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "added %d track(s)\n", len(tracks))
+	return nil
+}
+
+func resolveTracks(arg string) ([]engine.Track, error) {
+	if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+		path, err := filepath.Abs(arg)
+		if err != nil {
+			return nil, err
+		}
+		title := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		return []engine.Track{{Title: title, URL: library.FileURL(path)}}, nil
+	}
+
+	if !search.IsYouTubeURL(arg) {
+		return nil, errors.New("not a YouTube URL or a local file")
+	}
+
+	if search.IsPlaylistURL(arg) {
+		ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+		defer cancel()
+		results, err := search.GetPlaylistVideos(ctx, arg, 200)
+		if err != nil {
+			return nil, err
+		}
+		return tracksFromResults(results, time.Now()), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	result, err := search.GetVideoDetails(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	return tracksFromResults([]search.Result{*result}, time.Now()), nil
+}
+
+func queueSearch(c engine.Controller, query string, stdout io.Writer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	results, err := search.SearchVideos(ctx, query, 1)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return errors.New("no results for " + strconv.Quote(query))
+	}
+
+	tracks := tracksFromResults(results[:1], time.Now())
+	if err := c.AddTracks(tracks); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "queued %s\n", tracks[0].Title)
+	return nil
+}
+
+func tracksFromResults(results []search.Result, addedAt time.Time) []engine.Track {
+	tracks := make([]engine.Track, len(results))
+	for i, r := range results {
+		tracks[i] = engine.Track{
+			Title:       r.Title,
+			Author:      r.Author,
+			URL:         r.URL,
+			Thumbnail:   r.Thumbnail,
+			Duration:    r.Duration,
+			PublishedAt: r.PublishedAt,
+			Description: r.Description,
+			AddedAt:     addedAt,
+		}
+	}
+	return tracks
+}
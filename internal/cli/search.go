@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/search"
+	"golang.org/x/term"
+)
+
+const ExitInterrupted = 130
+
+const searchUsage = `usage: youtui-player search <query> [--limit n] [--format tsv|json] [--play]
+       youtui-player playlist <url> [--limit n] [--format tsv|json] [--play]
+
+options:
+  --limit n       maximum number of results (search: 20, playlist: 200)
+  --format f      tsv (title, channel, duration, url) or json
+  --play          play the results as audio in the terminal instead of printing them
+`
+
+var errInterrupted = errors.New("interrupted")
+
+type listOptions struct {
+	limit  int
+	format string
+	play   bool
+}
+
+func Search(args []string, stdout, stderr io.Writer) int {
+	return runList(args, 20, stdout, stderr, func(ctx context.Context, query string, limit int) ([]search.Result, error) {
+		return search.SearchVideos(ctx, query, limit)
+	})
+}
+
+func Playlist(args []string, stdout, stderr io.Writer) int {
+	return runList(args, 200, stdout, stderr, func(ctx context.Context, url string, limit int) ([]search.Result, error) {
+		if !search.IsYouTubeURL(url) {
+			return nil, errors.New("not a YouTube URL: " + url)
+		}
+		return search.GetPlaylistVideos(ctx, url, limit)
+	})
+}
+
+func runList(args []string, defaultLimit int, stdout, stderr io.Writer, fetch func(context.Context, string, int) ([]search.Result, error)) int {
+	opts := listOptions{limit: defaultLimit, format: "tsv"}
+	fs := flag.NewFlagSet("youtui-player", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.IntVar(&opts.limit, "limit", opts.limit, "")
+	fs.StringVar(&opts.format, "format", opts.format, "")
+	fs.BoolVar(&opts.play, "play", false, "")
+
+	positional, err := parseInterspersed(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(stderr, searchUsage)
+		return ExitOK
+	}
+	if err == nil {
+		err = validateList(opts, positional)
+	}
+	if err != nil {
+		return exitCode(err, searchUsage, stderr)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	results, err := fetch(ctx, strings.Join(positional, " "), opts.limit)
+	cancel()
+	if err != nil {
+		return exitCode(err, searchUsage, stderr)
+	}
+	if len(results) > opts.limit {
+		results = results[:opts.limit]
+	}
+
+	tracks := tracksFromResults(results, time.Time{})
+	if opts.play {
+		err = playTracks(tracks, stdout)
+	} else {
+		err = writeTracks(tracks, opts.format, stdout)
+	}
+	if errors.Is(err, errInterrupted) {
+		return ExitInterrupted
+	}
+	return exitCode(err, searchUsage, stderr)
+}
+
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, usageError(err.Error())
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func validateList(opts listOptions, positional []string) error {
+	switch {
+	case len(positional) == 0:
+		return usageError("missing query or URL")
+	case opts.limit <= 0:
+		return usageError("--limit must be positive")
+	case opts.format != "tsv" && opts.format != "json":
+		return usageError("unknown format: " + opts.format)
+	}
+	return nil
+}
+
+func writeTracks(tracks []engine.Track, format string, w io.Writer) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tracks)
+	}
+
+	clean := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+	for _, t := range tracks {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			clean.Replace(t.Title), clean.Replace(t.Author), t.Duration, t.URL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func playTracks(tracks []engine.Track, w io.Writer) error {
+	if len(tracks) == 0 {
+		return errors.New("nothing to play")
+	}
+
+	eng := engine.New(engine.Options{PlayMode: engine.ModeAudio})
+	defer eng.Close()
+
+	events, cancel, err := eng.Subscribe()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if err := eng.SetPlaylist(tracks, 0); err != nil {
+		return err
+	}
+	if err := eng.Play(0); err != nil {
+		return err
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	line := newProgressLine(w)
+	defer line.done()
+
+	var session uint64
+	for {
+		select {
+		case <-sig:
+			return errInterrupted
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+			switch ev.Type {
+			case engine.EventStarted:
+				session = ev.Session
+				line.show(ev.State)
+			case engine.EventState:
+				line.update(ev.State)
+			case engine.EventEnded:
+				if ev.Session != session || ev.State.Playing {
+					continue
+				}
+				if ev.Blocked {
+					return errors.New("YouTube refused the stream (HTTP 403); try updating yt-dlp")
+				}
+				if ev.Error != "" {
+					return errors.New("mpv: " + ev.Error)
+				}
+				return nil
+			}
+		}
+	}
+}
+
+type progressLine struct {
+	w     io.Writer
+	fd    int
+	tty   bool
+	dirty bool
+}
+
+func newProgressLine(w io.Writer) *progressLine {
+	p := &progressLine{w: w, fd: -1}
+	if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		p.fd, p.tty = int(f.Fd()), true
+	}
+	return p
+}
+
+func (p *progressLine) text(st engine.State) string {
+	title := st.Track.Title
+	if st.Track.Author != "" {
+		title += " - " + st.Track.Author
+	}
+	duration := formatTime(st.Duration)
+	if st.Duration <= 0 && st.Track.Duration != "" {
+		duration = st.Track.Duration
+	}
+	return fmt.Sprintf("▶ %d/%d  %s / %s  %s", st.Index+1, st.PlaylistLen,
+		formatTime(st.Position), duration, title)
+}
+
+func (p *progressLine) show(st engine.State) {
+	if !p.tty {
+		fmt.Fprintln(p.w, p.text(st))
+		return
+	}
+	p.update(st)
+}
+
+func (p *progressLine) update(st engine.State) {
+	if !p.tty {
+		return
+	}
+	text := p.text(st)
+	if width, _, err := term.GetSize(p.fd); err == nil && width > 1 {
+		if runes := []rune(text); len(runes) >= width {
+			text = string(runes[:width-1])
+		}
+	}
+	fmt.Fprint(p.w, "\r\033[K"+text)
+	p.dirty = true
+}
+
+func (p *progressLine) done() {
+	if p.dirty {
+		fmt.Fprintln(p.w)
+	}
+}
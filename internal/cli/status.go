@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/daemon"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/nowplaying"
+)
+
+const statusUsage = `usage: youtui-player status [--follow] [--format plain|waybar|polybar|tmux|json] [--template t]
+
+options:
+  --follow        keep running and print a new line whenever the output changes
+  --format f      output for a status bar (default plain)
+  --template t    override [status] template from youtui.conf
+
+template fields: {title} {author} {track} {icon} {state} {position} {duration}
+                 {percent} {volume} {index} {count} {url}
+`
+
+const reconnectDelay = 2 * time.Second
+
+type statusFormatter func(tmpl string, st engine.State) string
+
+var statusFormats = map[string]statusFormatter{
+	"plain":   plainStatus,
+	"waybar":  waybarStatus,
+	"polybar": polybarStatus,
+	"tmux":    tmuxStatus,
+	"json":    jsonStatus,
+}
+
+func Status(args []string, stdout, stderr io.Writer) int {
+	cfg, _ := config.LoadConfig()
+	tmpl := cfg.Status.Template
+	if tmpl == "" {
+		tmpl = nowplaying.DefaultTemplate
+	}
+
+	var follow bool
+	format := "plain"
+	fs := flag.NewFlagSet("youtui-player", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&follow, "follow", false, "")
+	fs.StringVar(&format, "format", format, "")
+	fs.StringVar(&tmpl, "template", tmpl, "")
+
+	positional, err := parseInterspersed(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(stderr, statusUsage)
+		return ExitOK
+	}
+	if err == nil && len(positional) > 0 {
+		err = usageError("unexpected argument: " + positional[0])
+	}
+	formatter, ok := statusFormats[format]
+	if err == nil && !ok {
+		err = usageError("unknown format: " + format)
+	}
+	if err != nil {
+		return exitCode(err, statusUsage, stderr)
+	}
+
+	out := &statusWriter{w: stdout, render: func(st engine.State) string { return formatter(tmpl, st) }}
+	if !follow {
+		client, err := daemon.Dial(config.GetSocketPath())
+		if err != nil {
+			out.write(engine.State{})
+			return exitCode(err, statusUsage, stderr)
+		}
+		defer client.Close()
+		st, err := client.State()
+		if err != nil {
+			return exitCode(err, statusUsage, stderr)
+		}
+		out.write(st)
+		return ExitOK
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	for {
+		if interrupted := followStatus(out, sig); interrupted {
+			return ExitOK
+		}
+		out.write(engine.State{})
+		select {
+		case <-sig:
+			return ExitOK
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func followStatus(out *statusWriter, sig <-chan os.Signal) bool {
+	client, err := daemon.Dial(config.GetSocketPath())
+	if err != nil {
+		return false
+	}
+	defer client.Close()
+
+	events, cancel, err := client.Subscribe()
+	if err != nil {
+		return false
+	}
+	defer cancel()
+
+	if st, err := client.State(); err == nil {
+		out.write(st)
+	}
+	for {
+		select {
+		case <-sig:
+			return true
+		case ev, ok := <-events:
+			if !ok {
+				return false
+			}
+			out.write(ev.State)
+		}
+	}
+}
+
+type statusWriter struct {
+	w      io.Writer
+	render func(engine.State) string
+	last   *string
+}
+
+func (s *statusWriter) write(st engine.State) {
+	line := s.render(st)
+	if s.last != nil && *s.last == line {
+		return
+	}
+	s.last = &line
+	fmt.Fprintln(s.w, line)
+}
+
+func plainStatus(tmpl string, st engine.State) string {
+	if !st.Playing {
+		return ""
+	}
+	return nowplaying.Render(tmpl, nowplaying.FieldsFrom(st), nil)
+}
+
+func polybarStatus(tmpl string, st engine.State) string {
+	if !st.Playing {
+		return ""
+	}
+	return nowplaying.Render(tmpl, nowplaying.FieldsFrom(st), func(v string) string {
+		return strings.ReplaceAll(v, "%", "%%")
+	})
+}
+
+func tmuxStatus(tmpl string, st engine.State) string {
+	if !st.Playing {
+		return ""
+	}
+	return nowplaying.Render(tmpl, nowplaying.FieldsFrom(st), func(v string) string {
+		return strings.ReplaceAll(v, "#", "##")
+	})
+}
+
+func waybarStatus(tmpl string, st engine.State) string {
+	fields := nowplaying.FieldsFrom(st)
+	out := struct {
+		Text       string `json:"text"`
+		Tooltip    string `json:"tooltip"`
+		Alt        string `json:"alt"`
+		Class      string `json:"class"`
+		Percentage int    `json:"percentage"`
+	}{
+		Alt:        fields["state"],
+		Class:      fields["state"],
+		Percentage: nowplaying.Percent(st),
+	}
+	if st.Playing {
+		out.Text = nowplaying.Render(tmpl, fields, html.EscapeString)
+		out.Tooltip = nowplaying.Render("{track}\n{position} / {duration}", fields, html.EscapeString)
+	}
+	raw, _ := json.Marshal(out)
+	return string(raw)
+}
+
+func jsonStatus(tmpl string, st engine.State) string {
+	fields := nowplaying.FieldsFrom(st)
+	out := struct {
+		Text     string  `json:"text"`
+		State    string  `json:"state"`
+		Title    string  `json:"title"`
+		Author   string  `json:"author"`
+		URL      string  `json:"url"`
+		Position float64 `json:"position"`
+		Duration float64 `json:"duration"`
+		Volume   int     `json:"volume"`
+	}{
+		State:  fields["state"],
+		Title:  fields["title"],
+		Author: fields["author"],
+		URL:    fields["url"],
+		Volume: st.Volume,
+	}
+	if st.Playing {
+		out.Text = nowplaying.Render(tmpl, fields, nil)
+		out.Position = float64(int(st.Position))
+		out.Duration = float64(int(st.Duration))
+	}
+	raw, _ := json.Marshal(out)
+	return string(raw)
+}
@@ -2,17 +2,27 @@
 package config
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/BurntSushi/toml"
+	"github.com/IvelOt/youtui-player/internal/atomicfile"
 )
 
 type Config struct {
-	Theme    ThemeConfig    `toml:"theme"`
-	UI       UIConfig       `toml:"ui"`
-	Playback PlaybackConfig `toml:"playback"`
+	Theme         ThemeConfig         `toml:"theme"`
+	UI            UIConfig            `toml:"ui"`
+	Playback      PlaybackConfig      `toml:"playback"`
+	Download      DownloadConfig      `toml:"download"`
+	Library       LibraryConfig       `toml:"library"`
+	Status        StatusConfig        `toml:"status"`
+	NowPlaying    NowPlayingConfig    `toml:"nowplaying"`
+	Hooks         HooksConfig         `toml:"hooks"`
+	Notifications NotificationsConfig `toml:"notifications"`
+	Scrobble      ScrobbleConfig      `toml:"scrobble"`
+	Discord       DiscordConfig       `toml:"discord"`
 }
 
 type ThemeConfig struct {
@@ -30,6 +40,31 @@ type PlaybackConfig struct {
 	VideoCodec   string `toml:"video_codec,omitempty"`
 }
 
+type DownloadConfig struct {
+	Dir              string `toml:"dir,omitempty"`
+	Concurrency      int    `toml:"concurrency,omitempty"`
+	AudioFormat      string `toml:"audio_format,omitempty"`
+	AudioBitrate     string `toml:"audio_bitrate,omitempty"`
+	FilenameTemplate string `toml:"filename_template,omitempty"`
+	PlaylistName     string `toml:"playlist_name,omitempty"`
+}
+
+type LibraryConfig struct {
+	Dirs []string `toml:"dirs,omitempty"`
+}
+
+type StatusConfig struct {
+	Template string `toml:"template,omitempty"`
+}
+
+type NowPlayingConfig struct {
+	Enabled     bool              `toml:"enabled,omitempty"`
+	Dir         string            `toml:"dir,omitempty"`
+	Files       map[string]string `toml:"files,omitempty"`
+	Thumbnail   string            `toml:"thumbnail,omitempty"`
+	EverySecond bool              `toml:"every_second,omitempty"`
+}
+
 func GetConfigDir() string {
 	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
 		return filepath.Join(xdg, "youtui-player")
@@ -41,10 +76,126 @@ func GetConfigDir() string {
 	return filepath.Join(home, ".config", "youtui-player")
 }
 
+func GetDataDir() string {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, "youtui-player")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "share", "youtui-player")
+}
+
+func GetHistoryPath() string {
+	return filepath.Join(GetDataDir(), "history.jsonl")
+}
+
+func GetExportDir() string {
+	return filepath.Join(GetDataDir(), "exports")
+}
+
+func GetDownloadDir(cfg DownloadConfig) string {
+	if dir := strings.TrimSpace(cfg.Dir); dir != "" {
+		return expandHome(dir)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(GetDataDir(), "downloads")
+	}
+	return filepath.Join(home, "Music", "youtui-player")
+}
+
+type HooksConfig struct {
+	Timeout       int    `toml:"timeout,omitempty"`
+	OnStart       string `toml:"on_start,omitempty"`
+	OnEnd         string `toml:"on_end,omitempty"`
+	OnPause       string `toml:"on_pause,omitempty"`
+	OnResume      string `toml:"on_resume,omitempty"`
+	OnPlaylistEnd string `toml:"on_playlist_end,omitempty"`
+	OnError       string `toml:"on_error,omitempty"`
+}
+
+type NotificationsConfig struct {
+	Enabled bool     `toml:"enabled,omitempty"`
+	Events  []string `toml:"events,omitempty"`
+}
+
+type ScrobbleConfig struct {
+	LastFM       LastFMConfig       `toml:"lastfm"`
+	ListenBrainz ListenBrainzConfig `toml:"listenbrainz"`
+}
+
+type LastFMConfig struct {
+	Enabled    bool   `toml:"enabled,omitempty"`
+	APIKey     string `toml:"api_key,omitempty"`
+	APISecret  string `toml:"api_secret,omitempty"`
+	SessionKey string `toml:"session_key,omitempty"`
+	Username   string `toml:"username,omitempty"`
+	Password   string `toml:"password,omitempty"`
+	BaseURL    string `toml:"base_url,omitempty"`
+}
+
+type ListenBrainzConfig struct {
+	Enabled bool   `toml:"enabled,omitempty"`
+	Token   string `toml:"token,omitempty"`
+	BaseURL string `toml:"base_url,omitempty"`
+}
+
+type DiscordConfig struct {
+	Enabled  bool   `toml:"enabled,omitempty"`
+	ClientID string `toml:"client_id,omitempty"`
+}
+
+func (c ScrobbleConfig) hasSecrets() bool {
+	return c.LastFM.APISecret != "" || c.LastFM.SessionKey != "" || c.LastFM.Password != "" || c.ListenBrainz.Token != ""
+}
+
+func GetScrobbleQueuePath() string {
+	return filepath.Join(GetDataDir(), "scrobble_queue.json")
+}
+
+func GetNowPlayingDir(cfg NowPlayingConfig) string {
+	if dir := strings.TrimSpace(cfg.Dir); dir != "" {
+		return expandHome(dir)
+	}
+	return filepath.Join(GetDataDir(), "nowplaying")
+}
+
+func GetLibraryDirs(cfg *Config) []string {
+	dirs := []string{}
+	seen := map[string]bool{}
+	for _, dir := range append(cfg.Library.Dirs, GetDownloadDir(cfg.Download)) {
+		dir = filepath.Clean(expandHome(strings.TrimSpace(dir)))
+		if dir == "." || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
+
 func GetConfigPath() string {
 	return filepath.Join(GetConfigDir(), "youtui.conf")
 }
 
+func GetSmartPlaylistsPath() string {
+	return filepath.Join(GetConfigDir(), "smart_playlists.toml")
+}
+
 func LoadConfig() (*Config, error) {
 	configPath := GetConfigPath()
 
@@ -60,16 +211,25 @@ func LoadConfig() (*Config, error) {
 			VideoQuality: "best",
 			VideoCodec:   "",
 		},
+		Download: DownloadConfig{
+			Concurrency:  2,
+			AudioFormat:  "mp3",
+			AudioBitrate: "192K",
+			PlaylistName: "YouTui Playlist",
+		},
 	}
 
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+	info, err := os.Stat(configPath)
+	if os.IsNotExist(err) {
 		return cfg, nil
 	}
 
-	_, err := toml.DecodeFile(configPath, cfg)
-	if err != nil {
+	if _, err := toml.DecodeFile(configPath, cfg); err != nil {
 		return cfg, err
 	}
+	if info != nil && info.Mode().Perm()&0o077 != 0 && cfg.Scrobble.hasSecrets() {
+		_ = os.Chmod(configPath, 0o600)
+	}
 
 	if strings.TrimSpace(cfg.UI.Language) == "" {
 		cfg.UI.Language = detectDefaultLanguage()
@@ -80,30 +240,10 @@ func LoadConfig() (*Config, error) {
 	return cfg, nil
 }
 
-func SaveConfig(cfg *Config) (err error) {
-	configPath := GetConfigPath()
-
-	dir := filepath.Dir(configPath)
-	if err = os.MkdirAll(dir, 0o755); err != nil {
-		return err
-	}
-
-	f, err := os.Create(configPath)
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if cerr := f.Close(); cerr != nil && err == nil {
-			err = cerr
-		}
-	}()
-
-	encoder := toml.NewEncoder(f)
-	if err = encoder.Encode(cfg); err != nil {
-		return err
-	}
-	return nil
+func SaveConfig(cfg *Config) error {
+	return atomicfile.Write(GetConfigPath(), 0o600, func(w io.Writer) error {
+		return toml.NewEncoder(w).Encode(cfg)
+	})
 }
 
 func detectDefaultLanguage() string {
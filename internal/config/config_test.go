@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSaveConfigIsPrivate(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg, _ := LoadConfig()
+	cfg.Scrobble.ListenBrainz = ListenBrainzConfig{Enabled: true, Token: "secret"}
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(GetConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("config written with %o, want 600", perm)
+	}
+}
+
+func TestLoadConfigTightensSecrets(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := GetConfigPath()
+
+	write := func(data string) {
+		t.Helper()
+		if err := os.MkdirAll(GetConfigDir(), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	perm := func() os.FileMode {
+		t.Helper()
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Mode().Perm()
+	}
+
+	write("[theme]\nactive = \"nord\"\n")
+	if _, err := LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if got := perm(); got != 0o644 {
+		t.Fatalf("config without secrets changed to %o", got)
+	}
+
+	write("[scrobble.lastfm]\napi_key = \"k\"\napi_secret = \"s\"\n")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Scrobble.LastFM.APISecret != "s" {
+		t.Fatalf("api_secret = %q", cfg.Scrobble.LastFM.APISecret)
+	}
+	if got := perm(); got != 0o600 {
+		t.Fatalf("config with secrets left at %o, want 600", got)
+	}
+}
@@ -3,9 +3,9 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
-	"time"
 )
 
 type PlayerState struct {
@@ -29,17 +29,65 @@ type Track struct {
 	Duration    string `json:"duration"`
 	PublishedAt string `json:"published_at"`
 	Description string `json:"description"`
+	AddedAt     int64  `json:"added_at,omitempty"`
 }
 
-func GetStatePath() string {
+func GetStateDir() string {
 	if xdg := os.Getenv("XDG_STATE_HOME"); xdg != "" {
-		return filepath.Join(xdg, "youtui-player", "state.json")
+		return filepath.Join(xdg, "youtui-player")
 	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return ""
 	}
-	return filepath.Join(home, ".local", "state", "youtui-player", "state.json")
+	return filepath.Join(home, ".local", "state", "youtui-player")
+}
+
+func GetStatePath() string {
+	dir := GetStateDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "state.json")
+}
+
+func GetLibraryCachePath() string {
+	dir := GetStateDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "library.json")
+}
+
+func GetDownloadQueuePath() string {
+	dir := GetStateDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "downloads.json")
+}
+
+func GetStorePath() string {
+	dir := GetStateDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "youtui.db")
+}
+
+func GetRatingsPath() string {
+	dir := GetStateDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "ratings.json")
+}
+
+func GetSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "youtui-player.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("youtui-player-%d.sock", os.Getuid()))
 }
 
 func LoadState() (*PlayerState, error) {
@@ -69,33 +117,3 @@ func LoadState() (*PlayerState, error) {
 
 	return &state, nil
 }
-
-func SaveState(state *PlayerState) error {
-	statePath := GetStatePath()
-	dir := filepath.Dir(statePath)
-
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return err
-	}
-
-	state.LastSaved = getCurrentTimestamp()
-
-	data, err := json.MarshalIndent(state, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(statePath, data, 0o644)
-}
-
-func ClearState() error {
-	statePath := GetStatePath()
-	if _, err := os.Stat(statePath); os.IsNotExist(err) {
-		return nil
-	}
-	return os.Remove(statePath)
-}
-
-func getCurrentTimestamp() string {
-	return filepath.Base(time.Now().Format("2006-01-02 15:04:05")) // Placeholder - u can use time.Now().Format()
-}
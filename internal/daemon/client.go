@@ -0,0 +1,308 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/history"
+)
+
+var errClosed = errors.New("connection to the player closed")
+
+type Client struct {
+	conn net.Conn
+
+	wmu sync.Mutex
+	enc *json.Encoder
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan response
+	events  *engine.EventQueue
+	err     error
+	done    chan struct{}
+}
+
+func Dial(path string) (*Client, error) {
+	conn, err := net.DialTimeout("unix", path, 2*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotRunning, err)
+	}
+
+	c := &Client{
+		conn:    conn,
+		enc:     json.NewEncoder(conn),
+		pending: map[uint64]chan response{},
+		done:    make(chan struct{}),
+	}
+	go c.read()
+	return c, nil
+}
+
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) read() {
+	scanner := bufio.NewScanner(c.conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		var msg response
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+
+		if msg.ID == nil {
+			if msg.Method == "event" {
+				c.dispatchEvent(msg.Params)
+			}
+			continue
+		}
+
+		c.mu.Lock()
+		ch := c.pending[*msg.ID]
+		delete(c.pending, *msg.ID)
+		c.mu.Unlock()
+		if ch != nil {
+			ch <- msg
+		}
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = errClosed
+	}
+
+	c.mu.Lock()
+	c.err = err
+	for id, ch := range c.pending {
+		delete(c.pending, id)
+		close(ch)
+	}
+	if c.events != nil {
+		c.events.Close()
+		c.events = nil
+	}
+	c.mu.Unlock()
+	close(c.done)
+}
+
+func (c *Client) dispatchEvent(raw json.RawMessage) {
+	var ev engine.Event
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.events == nil {
+		return
+	}
+	c.events.Push(ev)
+}
+
+func (c *Client) call(method string, params, result any) error {
+	var raw json.RawMessage
+	if params != nil {
+		var err error
+		if raw, err = json.Marshal(params); err != nil {
+			return err
+		}
+	}
+
+	ch := make(chan response, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	c.wmu.Lock()
+	err := c.enc.Encode(request{JSONRPC: "2.0", ID: &id, Method: method, Params: raw})
+	c.wmu.Unlock()
+	if err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return err
+	}
+
+	msg, ok := <-ch
+	if !ok {
+		return errClosed
+	}
+	if msg.Error != nil {
+		return fromRPCError(msg.Error)
+	}
+	if result != nil && len(msg.Result) > 0 {
+		return json.Unmarshal(msg.Result, result)
+	}
+	return nil
+}
+
+func (c *Client) Play(idx int) error {
+	return c.call("play", indexParams{Index: idx}, nil)
+}
+
+func (c *Client) PlayTrack(track engine.Track) error {
+	return c.call("play_track", trackParams{Track: track}, nil)
+}
+
+func (c *Client) TogglePause() error {
+	return c.call("toggle_pause", nil, nil)
+}
+
+func (c *Client) Stop() error {
+	return c.call("stop", nil, nil)
+}
+
+func (c *Client) Next() error {
+	return c.call("next", nil, nil)
+}
+
+func (c *Client) Previous() error {
+	return c.call("previous", nil, nil)
+}
+
+func (c *Client) Seek(seconds float64, absolute bool) error {
+	return c.call("seek", seekParams{Seconds: seconds, Absolute: absolute}, nil)
+}
+
+func (c *Client) SetVolume(volume int) error {
+	return c.call("set_volume", volumeParams{Volume: volume}, nil)
+}
+
+func (c *Client) SetPlaylist(tracks []engine.Track, current int) error {
+	return c.call("set_playlist", playlistParams{Tracks: tracks, Current: current}, nil)
+}
+
+func (c *Client) EditPlaylist(base uint64, tracks []engine.Track) (uint64, error) {
+	var version uint64
+	err := c.call("edit_playlist", editParams{Base: base, Tracks: tracks}, &version)
+	return version, err
+}
+
+func (c *Client) AddTracks(tracks []engine.Track) error {
+	return c.call("add_tracks", playlistParams{Tracks: tracks}, nil)
+}
+
+func (c *Client) SetPlaylistMode(mode engine.PlaylistMode) error {
+	return c.call("set_playlist_mode", modeParams{Mode: mode}, nil)
+}
+
+func (c *Client) SetPlayMode(mode engine.PlayMode) error {
+	return c.call("set_play_mode", playModeParams{Mode: mode}, nil)
+}
+
+func (c *Client) SetVideo(quality, codec string) error {
+	return c.call("set_video", videoParams{Quality: quality, Codec: codec}, nil)
+}
+
+func (c *Client) State() (engine.State, error) {
+	var st engine.State
+	err := c.call("state", nil, &st)
+	return st, err
+}
+
+func (c *Client) Playlist() ([]engine.Track, error) {
+	var tracks []engine.Track
+	err := c.call("playlist", nil, &tracks)
+	return tracks, err
+}
+
+func (c *Client) Upcoming(n int) ([]int, error) {
+	var order []int
+	err := c.call("upcoming", countParams{N: n}, &order)
+	return order, err
+}
+
+func (c *Client) History() ([]history.Entry, error) {
+	var entries []history.Entry
+	err := c.call("history", nil, &entries)
+	return entries, err
+}
+
+func (c *Client) ClearHistory() error {
+	return c.call("clear_history", nil, nil)
+}
+
+func (c *Client) Incognito() (bool, error) {
+	var on bool
+	err := c.call("incognito", nil, &on)
+	return on, err
+}
+
+func (c *Client) SetIncognito(on bool) error {
+	return c.call("set_incognito", incognitoParams{On: on}, nil)
+}
+
+func (c *Client) LoadSession() (*config.PlayerState, error) {
+	state := &config.PlayerState{CurrentTrackIdx: -1}
+	err := c.call("load_session", nil, state)
+	return state, err
+}
+
+func (c *Client) SaveSession(state *config.PlayerState) error {
+	return c.call("save_session", state, nil)
+}
+
+func (c *Client) Offline() (map[string]string, error) {
+	paths := map[string]string{}
+	err := c.call("offline", nil, &paths)
+	return paths, err
+}
+
+func (c *Client) AddOffline(id, path string) error {
+	return c.call("add_offline", offlineParams{ID: id, Path: path}, nil)
+}
+
+func (c *Client) Subscribe() (<-chan engine.Event, func(), error) {
+	q := engine.NewEventQueue()
+	c.mu.Lock()
+	if c.events != nil {
+		c.mu.Unlock()
+		q.Stop()
+		return nil, nil, errors.New("already subscribed")
+	}
+	c.events = q
+	c.mu.Unlock()
+
+	if err := c.call("subscribe", nil, nil); err != nil {
+		c.mu.Lock()
+		if c.events == q {
+			c.events = nil
+		}
+		c.mu.Unlock()
+		q.Stop()
+		return nil, nil, err
+	}
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			_ = c.call("unsubscribe", nil, nil)
+			c.mu.Lock()
+			if c.events == q {
+				c.events = nil
+			}
+			c.mu.Unlock()
+			q.Stop()
+		})
+	}
+	return q.C(), cancel, nil
+}
@@ -0,0 +1,126 @@
+// Package daemon serves the player engine over a Unix socket using JSON-RPC 2.0
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/IvelOt/youtui-player/internal/engine"
+)
+
+const (
+	codeParse          = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternal       = -32603
+)
+
+var ErrNotRunning = errors.New("no running instance")
+
+var engineErrors = []error{
+	engine.ErrNothingPlaying,
+	engine.ErrPlaylistEmpty,
+	engine.ErrLastTrack,
+	engine.ErrFirstTrack,
+	engine.ErrNoTrack,
+	engine.ErrSocatMissing,
+	engine.ErrPlaylistChanged,
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *uint64         `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *uint64         `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+func toRPCError(err error) *rpcError {
+	var rerr *rpcError
+	if errors.As(err, &rerr) {
+		return rerr
+	}
+	for i, known := range engineErrors {
+		if errors.Is(err, known) {
+			return &rpcError{Code: -32001 - i, Message: known.Error()}
+		}
+	}
+	return &rpcError{Code: codeInternal, Message: err.Error()}
+}
+
+func fromRPCError(rerr *rpcError) error {
+	if i := -32001 - rerr.Code; i >= 0 && i < len(engineErrors) {
+		return engineErrors[i]
+	}
+	return rerr
+}
+
+type indexParams struct {
+	Index int `json:"index"`
+}
+
+type trackParams struct {
+	Track engine.Track `json:"track"`
+}
+
+type seekParams struct {
+	Seconds  float64 `json:"seconds"`
+	Absolute bool    `json:"absolute,omitempty"`
+}
+
+type volumeParams struct {
+	Volume int `json:"volume"`
+}
+
+type playlistParams struct {
+	Tracks  []engine.Track `json:"tracks"`
+	Current int            `json:"current"`
+}
+
+type editParams struct {
+	Base   uint64         `json:"base"`
+	Tracks []engine.Track `json:"tracks"`
+}
+
+type modeParams struct {
+	Mode engine.PlaylistMode `json:"mode"`
+}
+
+type playModeParams struct {
+	Mode engine.PlayMode `json:"mode"`
+}
+
+type videoParams struct {
+	Quality string `json:"quality"`
+	Codec   string `json:"codec"`
+}
+
+type countParams struct {
+	N int `json:"n"`
+}
+
+type incognitoParams struct {
+	On bool `json:"on"`
+}
+
+type offlineParams struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+}
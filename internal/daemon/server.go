@@ -0,0 +1,329 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/persist"
+)
+
+const maxMessageSize = 64 << 20
+
+type handler func(params json.RawMessage) (any, error)
+
+type Server struct {
+	ctrl     engine.Controller
+	data     persist.Data
+	ln       net.Listener
+	path     string
+	handlers map[string]handler
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+}
+
+func Listen(path string, ctrl engine.Controller, data persist.Data) (*Server, error) {
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("another instance is listening on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+
+	s := &Server{ctrl: ctrl, data: data, ln: ln, path: path, conns: map[net.Conn]struct{}{}}
+	s.handlers = s.handlerTable()
+	return s, nil
+}
+
+func (s *Server) Path() string {
+	return s.path
+}
+
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		go s.serveConn(conn)
+	}
+}
+
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	err := s.ln.Close()
+	_ = os.Remove(s.path)
+	return err
+}
+
+type session struct {
+	conn   net.Conn
+	mu     sync.Mutex
+	enc    *json.Encoder
+	cancel func()
+}
+
+func (c *session) write(msg response) {
+	msg.JSONRPC = "2.0"
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = c.enc.Encode(msg)
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	c := &session{conn: conn, enc: json.NewEncoder(conn)}
+	defer func() {
+		if c.cancel != nil {
+			c.cancel()
+		}
+		_ = conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			c.write(response{Error: &rpcError{Code: codeParse, Message: err.Error()}})
+			continue
+		}
+
+		result, err := s.dispatch(c, req)
+		if req.ID == nil {
+			continue
+		}
+		if err != nil {
+			c.write(response{ID: req.ID, Error: toRPCError(err)})
+			continue
+		}
+		raw, err := json.Marshal(result)
+		if err != nil {
+			c.write(response{ID: req.ID, Error: toRPCError(err)})
+			continue
+		}
+		c.write(response{ID: req.ID, Result: raw})
+	}
+}
+
+func (s *Server) dispatch(c *session, req request) (any, error) {
+	switch req.Method {
+	case "subscribe":
+		return s.subscribe(c)
+	case "unsubscribe":
+		if c.cancel != nil {
+			c.cancel()
+			c.cancel = nil
+		}
+		return true, nil
+	}
+
+	h, ok := s.handlers[req.Method]
+	if !ok {
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+	return h(req.Params)
+}
+
+func (s *Server) subscribe(c *session) (any, error) {
+	if c.cancel != nil {
+		return true, nil
+	}
+	events, cancel, err := s.ctrl.Subscribe()
+	if err != nil {
+		return nil, err
+	}
+	canceled := make(chan struct{})
+	var once sync.Once
+	c.cancel = func() {
+		once.Do(func() {
+			close(canceled)
+			cancel()
+		})
+	}
+
+	go func() {
+		for ev := range events {
+			raw, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			c.write(response{Method: "event", Params: raw})
+		}
+		select {
+		case <-canceled:
+		default:
+			_ = c.conn.Close()
+		}
+	}()
+	return true, nil
+}
+
+func decode[T any](params json.RawMessage) (T, error) {
+	var v T
+	if len(params) == 0 {
+		return v, nil
+	}
+	if err := json.Unmarshal(params, &v); err != nil {
+		return v, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return v, nil
+}
+
+func (s *Server) handlerTable() map[string]handler {
+	ok := func(err error) (any, error) {
+		if err != nil {
+			return nil, err
+		}
+		return true, nil
+	}
+
+	return map[string]handler{
+		"play": func(p json.RawMessage) (any, error) {
+			args, err := decode[indexParams](p)
+			if err != nil {
+				return nil, err
+			}
+			return ok(s.ctrl.Play(args.Index))
+		},
+		"play_track": func(p json.RawMessage) (any, error) {
+			args, err := decode[trackParams](p)
+			if err != nil {
+				return nil, err
+			}
+			return ok(s.ctrl.PlayTrack(args.Track))
+		},
+		"toggle_pause": func(json.RawMessage) (any, error) { return ok(s.ctrl.TogglePause()) },
+		"stop":         func(json.RawMessage) (any, error) { return ok(s.ctrl.Stop()) },
+		"next":         func(json.RawMessage) (any, error) { return ok(s.ctrl.Next()) },
+		"previous":     func(json.RawMessage) (any, error) { return ok(s.ctrl.Previous()) },
+		"seek": func(p json.RawMessage) (any, error) {
+			args, err := decode[seekParams](p)
+			if err != nil {
+				return nil, err
+			}
+			return ok(s.ctrl.Seek(args.Seconds, args.Absolute))
+		},
+		"set_volume": func(p json.RawMessage) (any, error) {
+			args, err := decode[volumeParams](p)
+			if err != nil {
+				return nil, err
+			}
+			return ok(s.ctrl.SetVolume(args.Volume))
+		},
+		"set_playlist": func(p json.RawMessage) (any, error) {
+			args, err := decode[playlistParams](p)
+			if err != nil {
+				return nil, err
+			}
+			return ok(s.ctrl.SetPlaylist(args.Tracks, args.Current))
+		},
+		"edit_playlist": func(p json.RawMessage) (any, error) {
+			args, err := decode[editParams](p)
+			if err != nil {
+				return nil, err
+			}
+			return s.ctrl.EditPlaylist(args.Base, args.Tracks)
+		},
+		"add_tracks": func(p json.RawMessage) (any, error) {
+			args, err := decode[playlistParams](p)
+			if err != nil {
+				return nil, err
+			}
+			return ok(s.ctrl.AddTracks(args.Tracks))
+		},
+		"set_playlist_mode": func(p json.RawMessage) (any, error) {
+			args, err := decode[modeParams](p)
+			if err != nil {
+				return nil, err
+			}
+			return ok(s.ctrl.SetPlaylistMode(args.Mode))
+		},
+		"set_play_mode": func(p json.RawMessage) (any, error) {
+			args, err := decode[playModeParams](p)
+			if err != nil {
+				return nil, err
+			}
+			return ok(s.ctrl.SetPlayMode(args.Mode))
+		},
+		"set_video": func(p json.RawMessage) (any, error) {
+			args, err := decode[videoParams](p)
+			if err != nil {
+				return nil, err
+			}
+			return ok(s.ctrl.SetVideo(args.Quality, args.Codec))
+		},
+		"state":    func(json.RawMessage) (any, error) { return s.ctrl.State() },
+		"playlist": func(json.RawMessage) (any, error) { return s.ctrl.Playlist() },
+		"upcoming": func(p json.RawMessage) (any, error) {
+			args, err := decode[countParams](p)
+			if err != nil {
+				return nil, err
+			}
+			return s.ctrl.Upcoming(args.N)
+		},
+		"history":       func(json.RawMessage) (any, error) { return s.data.History() },
+		"clear_history": func(json.RawMessage) (any, error) { return ok(s.data.ClearHistory()) },
+		"incognito":     func(json.RawMessage) (any, error) { return s.data.Incognito() },
+		"set_incognito": func(p json.RawMessage) (any, error) {
+			args, err := decode[incognitoParams](p)
+			if err != nil {
+				return nil, err
+			}
+			return ok(s.data.SetIncognito(args.On))
+		},
+		"load_session": func(json.RawMessage) (any, error) { return s.data.LoadSession() },
+		"save_session": func(p json.RawMessage) (any, error) {
+			args, err := decode[config.PlayerState](p)
+			if err != nil {
+				return nil, err
+			}
+			return ok(s.data.SaveSession(&args))
+		},
+		"offline": func(json.RawMessage) (any, error) { return s.data.Offline() },
+		"add_offline": func(p json.RawMessage) (any, error) {
+			args, err := decode[offlineParams](p)
+			if err != nil {
+				return nil, err
+			}
+			return ok(s.data.AddOffline(args.ID, args.Path))
+		},
+	}
+}
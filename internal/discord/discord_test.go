@@ -0,0 +1,261 @@
+package discord
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/enginetest"
+)
+
+type frame struct {
+	op   uint32
+	data map[string]any
+}
+
+type fakeDiscord struct {
+	t      *testing.T
+	ln     net.Listener
+	frames chan frame
+
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func startDiscord(t *testing.T) *fakeDiscord {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+
+	ln, err := net.Listen("unix", filepath.Join(dir, "discord-ipc-0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &fakeDiscord{t: t, ln: ln, frames: make(chan frame, 32)}
+	t.Cleanup(func() {
+		_ = ln.Close()
+		d.drop()
+	})
+	go d.accept()
+	return d
+}
+
+func (d *fakeDiscord) accept() {
+	for {
+		conn, err := d.ln.Accept()
+		if err != nil {
+			return
+		}
+		d.mu.Lock()
+		d.conns = append(d.conns, conn)
+		d.mu.Unlock()
+		go d.serve(conn)
+	}
+}
+
+func (d *fakeDiscord) serve(conn net.Conn) {
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(conn, header[:]); err != nil {
+			return
+		}
+		op := binary.LittleEndian.Uint32(header[0:])
+		data := make([]byte, binary.LittleEndian.Uint32(header[4:]))
+		if _, err := io.ReadFull(conn, data); err != nil {
+			return
+		}
+		var payload map[string]any
+		if err := json.Unmarshal(data, &payload); err != nil {
+			d.t.Errorf("op %d: invalid JSON %q: %v", op, data, err)
+			return
+		}
+		d.frames <- frame{op: op, data: payload}
+
+		switch op {
+		case opHandshake:
+			d.reply(conn, map[string]any{"cmd": "DISPATCH", "evt": "READY", "data": map[string]any{"v": 1}})
+		case opFrame:
+			d.reply(conn, map[string]any{"cmd": payload["cmd"], "nonce": payload["nonce"], "data": map[string]any{}})
+		case opClose:
+			return
+		}
+	}
+}
+
+func (d *fakeDiscord) reply(conn net.Conn, payload any) {
+	data, _ := json.Marshal(payload)
+	header := make([]byte, 8)
+	binary.LittleEndian.PutUint32(header[0:], opFrame)
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(data)))
+	_, _ = conn.Write(append(header, data...))
+}
+
+func (d *fakeDiscord) drop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, conn := range d.conns {
+		_ = conn.Close()
+	}
+	d.conns = nil
+}
+
+func (d *fakeDiscord) next(t *testing.T, op uint32) map[string]any {
+	t.Helper()
+	f := enginetest.Receive(t, d.frames)
+	if f.op != op {
+		t.Fatalf("got op %d %v, want op %d", f.op, f.data, op)
+	}
+	return f.data
+}
+
+func (d *fakeDiscord) handshake(t *testing.T) {
+	t.Helper()
+	hello := d.next(t, opHandshake)
+	if hello["v"] != float64(1) || hello["client_id"] != "1234" {
+		t.Fatalf("handshake = %v", hello)
+	}
+}
+
+func (d *fakeDiscord) activity(t *testing.T) map[string]any {
+	t.Helper()
+	msg := d.next(t, opFrame)
+	if msg["cmd"] != "SET_ACTIVITY" {
+		t.Fatalf("cmd = %v", msg["cmd"])
+	}
+	args, _ := msg["args"].(map[string]any)
+	if args["pid"] != float64(os.Getpid()) {
+		t.Fatalf("args = %v", args)
+	}
+	activity, _ := args["activity"].(map[string]any)
+	return activity
+}
+
+func playing(id string, paused bool) engine.Event {
+	return enginetest.Playing(enginetest.Track(id, "Rick Astley"), paused)
+}
+
+func watch(t *testing.T) (*enginetest.Controller, *Presence, chan error) {
+	t.Helper()
+	ctrl := enginetest.NewController()
+	errs := make(chan error, 8)
+	p, err := Watch(ctrl, Options{
+		ClientID:    "1234",
+		ButtonLabel: func() string { return "Listen on YouTube" },
+		OnError:     func(err error) { errs <- err },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctrl, p, errs
+}
+
+func TestFrameLayout(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	c := &Conn{conn: client}
+
+	go func() { _ = c.write(opFrame, map[string]string{"cmd": "SET_ACTIVITY"}) }()
+	var buf [8]byte
+	if _, err := io.ReadFull(server, buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"cmd":"SET_ACTIVITY"}`
+	if op := binary.LittleEndian.Uint32(buf[0:]); op != opFrame {
+		t.Fatalf("op = %d, want %d", op, opFrame)
+	}
+	if size := binary.LittleEndian.Uint32(buf[4:]); int(size) != len(want) {
+		t.Fatalf("length = %d, want %d", size, len(want))
+	}
+	body := make([]byte, len(want))
+	if _, err := io.ReadFull(server, body); err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != want {
+		t.Fatalf("body = %s, want %s", body, want)
+	}
+}
+
+func TestPresenceShowsAndClearsActivity(t *testing.T) {
+	d := startDiscord(t)
+	ctrl, p, _ := watch(t)
+
+	ctrl.Send(playing("dQw4w9WgXcQ", false))
+	d.handshake(t)
+	a := d.activity(t)
+	if a["type"] != float64(activityListening) || a["details"] != "dQw4w9WgXcQ" || a["state"] != "Rick Astley" {
+		t.Fatalf("activity = %v", a)
+	}
+	ts, _ := a["timestamps"].(map[string]any)
+	start, _ := ts["start"].(float64)
+	end, _ := ts["end"].(float64)
+	if end-start != 200000 || time.Since(time.UnixMilli(int64(start))) < 10*time.Second {
+		t.Fatalf("timestamps = %v", ts)
+	}
+	buttons, _ := a["buttons"].([]any)
+	if len(buttons) != 1 || buttons[0].(map[string]any)["url"] != "https://www.youtube.com/watch?v=dQw4w9WgXcQ" {
+		t.Fatalf("buttons = %v", a["buttons"])
+	}
+
+	ctrl.Send(playing("dQw4w9WgXcQ", true))
+	if a := d.activity(t); a != nil {
+		t.Fatalf("pause sent activity %v, want it cleared", a)
+	}
+
+	ctrl.Send(playing("yPYZpwSpKmA", false))
+	if a := d.activity(t); a["details"] != "yPYZpwSpKmA" {
+		t.Fatalf("resumed activity = %v", a)
+	}
+
+	ctrl.Send(engine.Event{Type: engine.EventState})
+	if a := d.activity(t); a != nil {
+		t.Fatalf("stop sent activity %v, want it cleared", a)
+	}
+
+	p.Close()
+	d.next(t, opClose)
+}
+
+func TestPresenceReconnectsAfterDrop(t *testing.T) {
+	d := startDiscord(t)
+	ctrl, p, errs := watch(t)
+	defer p.Close()
+
+	ctrl.Send(playing("dQw4w9WgXcQ", false))
+	d.handshake(t)
+	d.activity(t)
+
+	d.drop()
+	ctrl.Send(playing("yPYZpwSpKmA", false))
+	enginetest.Receive(t, errs)
+
+	ctrl.Send(playing("yPYZpwSpKmA", false))
+	d.handshake(t)
+	if a := d.activity(t); a["details"] != "yPYZpwSpKmA" {
+		t.Fatalf("activity after reconnect = %v", a)
+	}
+}
+
+func TestClip(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"  short  ", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"much too long", 5, "much…"},
+		{"ação ação", 4, "açã…"},
+	}
+	for _, tt := range tests {
+		if got := clip(tt.in, tt.n); got != tt.want {
+			t.Errorf("clip(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Package discord shows the playing track as Discord Rich Presence
+package discord
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	opHandshake = 0
+	opFrame     = 1
+	opClose     = 2
+	opPing      = 3
+	opPong      = 4
+)
+
+const (
+	ioTimeout = 5 * time.Second
+	maxFrame  = 1 << 20
+)
+
+var ErrNotRunning = errors.New("discord is not running")
+
+type Activity struct {
+	Type       int         `json:"type"`
+	Details    string      `json:"details,omitempty"`
+	State      string      `json:"state,omitempty"`
+	Timestamps *Timestamps `json:"timestamps,omitempty"`
+	Assets     *Assets     `json:"assets,omitempty"`
+	Buttons    []Button    `json:"buttons,omitempty"`
+}
+
+type Timestamps struct {
+	Start int64 `json:"start,omitempty"`
+	End   int64 `json:"end,omitempty"`
+}
+
+type Assets struct {
+	LargeImage string `json:"large_image,omitempty"`
+	LargeText  string `json:"large_text,omitempty"`
+}
+
+type Button struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+type Conn struct {
+	conn net.Conn
+
+	mu    sync.Mutex
+	nonce int
+}
+
+type message struct {
+	Cmd   string          `json:"cmd"`
+	Evt   string          `json:"evt,omitempty"`
+	Nonce string          `json:"nonce"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Args  any             `json:"args,omitempty"`
+}
+
+type errorData struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func SocketPaths() []string {
+	var dirs []string
+	for _, env := range []string{"XDG_RUNTIME_DIR", "TMPDIR", "TMP", "TEMP"} {
+		if dir := os.Getenv(env); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	dirs = append(dirs, "/tmp")
+
+	var paths []string
+	for _, dir := range dirs {
+		for _, sub := range []string{"", "app/com.discordapp.Discord", "snap.discord"} {
+			for i := 0; i < 10; i++ {
+				paths = append(paths, filepath.Join(dir, sub, "discord-ipc-"+strconv.Itoa(i)))
+			}
+		}
+	}
+	return paths
+}
+
+func Dial(clientID string) (*Conn, error) {
+	last := ErrNotRunning
+	for _, path := range SocketPaths() {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		c, err := DialPath(path, clientID)
+		if err == nil {
+			return c, nil
+		}
+		if !errors.Is(err, syscall.ECONNREFUSED) && !errors.Is(err, syscall.ENOENT) {
+			last = err
+		}
+	}
+	return nil, last
+}
+
+func DialPath(path, clientID string) (*Conn, error) {
+	nc, err := net.DialTimeout("unix", path, ioTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &Conn{conn: nc}
+	if err := c.handshake(clientID); err != nil {
+		_ = nc.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Conn) handshake(clientID string) error {
+	if err := c.write(opHandshake, map[string]any{"v": 1, "client_id": clientID}); err != nil {
+		return err
+	}
+	msg, err := c.read()
+	if err != nil {
+		return err
+	}
+	if msg.Evt != "READY" {
+		return responseError(msg)
+	}
+	return nil
+}
+
+func (c *Conn) SetActivity(a *Activity) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nonce++
+	nonce := strconv.Itoa(c.nonce)
+	args := map[string]any{"pid": os.Getpid()}
+	if a != nil {
+		args["activity"] = a
+	}
+	if err := c.write(opFrame, message{Cmd: "SET_ACTIVITY", Nonce: nonce, Args: args}); err != nil {
+		return err
+	}
+	for {
+		msg, err := c.read()
+		if err != nil {
+			return err
+		}
+		if msg.Nonce != nonce {
+			continue
+		}
+		if msg.Evt == "ERROR" {
+			return responseError(msg)
+		}
+		return nil
+	}
+}
+
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = c.write(opClose, map[string]any{})
+	return c.conn.Close()
+}
+
+func (c *Conn) write(op uint32, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	frame := make([]byte, 8+len(data))
+	binary.LittleEndian.PutUint32(frame[0:], op)
+	binary.LittleEndian.PutUint32(frame[4:], uint32(len(data)))
+	copy(frame[8:], data)
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(ioTimeout))
+	_, err = c.conn.Write(frame)
+	return err
+}
+
+func (c *Conn) read() (message, error) {
+	for {
+		_ = c.conn.SetReadDeadline(time.Now().Add(ioTimeout))
+		var header [8]byte
+		if _, err := io.ReadFull(c.conn, header[:]); err != nil {
+			return message{}, err
+		}
+		op := binary.LittleEndian.Uint32(header[0:])
+		size := binary.LittleEndian.Uint32(header[4:])
+		if size > maxFrame {
+			return message{}, fmt.Errorf("discord frame too large: %d bytes", size)
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(c.conn, data); err != nil {
+			return message{}, err
+		}
+
+		switch op {
+		case opPing:
+			if err := c.write(opPong, json.RawMessage(data)); err != nil {
+				return message{}, err
+			}
+		case opClose:
+			var reason errorData
+			_ = json.Unmarshal(data, &reason)
+			return message{}, fmt.Errorf("discord closed the connection: %s", reason.Message)
+		case opFrame:
+			var msg message
+			if err := json.Unmarshal(data, &msg); err != nil {
+				return message{}, err
+			}
+			return msg, nil
+		}
+	}
+}
+
+func responseError(msg message) error {
+	var data errorData
+	_ = json.Unmarshal(msg.Data, &data)
+	if data.Message == "" {
+		return fmt.Errorf("discord: unexpected response %s/%s", msg.Cmd, msg.Evt)
+	}
+	return fmt.Errorf("discord: error %d: %s", data.Code, data.Message)
+}
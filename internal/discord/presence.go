@@ -0,0 +1,192 @@
+package discord
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/engine"
+)
+
+const (
+	activityListening = 2
+	reconnectInterval = 15 * time.Second
+	driftTolerance    = 2 * time.Second
+	maxText           = 128
+	maxLabel          = 32
+)
+
+type Options struct {
+	ClientID    string
+	ButtonLabel func() string
+	StateText   func(engine.Track) string
+	OnError     func(error)
+}
+
+type Presence struct {
+	opts   Options
+	cancel func()
+	done   chan struct{}
+
+	conn    *Conn
+	want    *Activity
+	key     string
+	start   time.Time
+	pending bool
+	shown   bool
+	lastErr string
+	redial  time.Time
+}
+
+func Watch(ctrl engine.Controller, opts Options) (*Presence, error) {
+	events, cancel, err := ctrl.Subscribe()
+	if err != nil {
+		return nil, err
+	}
+	p := &Presence{opts: opts, cancel: cancel, done: make(chan struct{})}
+	initial, _ := ctrl.State()
+	go p.run(events, initial)
+	return p, nil
+}
+
+func (p *Presence) Close() {
+	p.cancel()
+	<-p.done
+}
+
+func (p *Presence) run(events <-chan engine.Event, initial engine.State) {
+	defer close(p.done)
+	retry := time.NewTicker(reconnectInterval)
+	defer retry.Stop()
+
+	p.update(initial)
+	p.flush()
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				p.disconnect()
+				return
+			}
+			p.update(ev.State)
+		case <-retry.C:
+		}
+		p.flush()
+	}
+}
+
+func (p *Presence) update(st engine.State) {
+	if !st.Playing || st.Paused {
+		if p.want != nil || p.key != "" {
+			p.want, p.key, p.pending = nil, "", true
+		}
+		return
+	}
+
+	start := time.Now().Add(-time.Duration(st.Position * float64(time.Second)))
+	key := st.Track.URL + "\x00" + time.Duration(st.Duration*float64(time.Second)).String()
+	if key == p.key && absDuration(start.Sub(p.start)) < driftTolerance {
+		return
+	}
+	p.key, p.start, p.pending = key, start, true
+	p.want = p.activity(st, start)
+}
+
+func (p *Presence) activity(st engine.State, start time.Time) *Activity {
+	a := &Activity{
+		Type:       activityListening,
+		Details:    clip(st.Track.Title, maxText),
+		Timestamps: &Timestamps{Start: start.UnixMilli()},
+	}
+	if st.Duration > 0 {
+		a.Timestamps.End = start.Add(time.Duration(st.Duration * float64(time.Second))).UnixMilli()
+	}
+	if st.Track.Author != "" {
+		state := st.Track.Author
+		if p.opts.StateText != nil {
+			state = p.opts.StateText(st.Track)
+		}
+		a.State = clip(state, maxText)
+	}
+	if isWebURL(st.Track.Thumbnail) {
+		a.Assets = &Assets{LargeImage: st.Track.Thumbnail, LargeText: a.Details}
+	}
+	if isWebURL(st.Track.URL) && p.opts.ButtonLabel != nil {
+		a.Buttons = []Button{{Label: clip(p.opts.ButtonLabel(), maxLabel), URL: st.Track.URL}}
+	}
+	return a
+}
+
+func (p *Presence) flush() {
+	if !p.pending {
+		return
+	}
+	if p.want == nil && !p.shown {
+		p.pending = false
+		return
+	}
+	if p.conn == nil {
+		if time.Now().Before(p.redial) {
+			return
+		}
+		conn, err := Dial(p.opts.ClientID)
+		if err != nil {
+			p.redial = time.Now().Add(reconnectInterval)
+			if !errors.Is(err, ErrNotRunning) {
+				p.report(err)
+			}
+			if p.want == nil {
+				p.pending = false
+			}
+			return
+		}
+		p.conn = conn
+	}
+	if err := p.conn.SetActivity(p.want); err != nil {
+		p.report(err)
+		_ = p.conn.Close()
+		p.conn = nil
+		return
+	}
+	p.pending, p.shown, p.lastErr = false, p.want != nil, ""
+}
+
+func (p *Presence) disconnect() {
+	if p.conn == nil {
+		return
+	}
+	if p.shown {
+		_ = p.conn.SetActivity(nil)
+	}
+	_ = p.conn.Close()
+	p.conn = nil
+}
+
+func (p *Presence) report(err error) {
+	if err.Error() == p.lastErr {
+		return
+	}
+	p.lastErr = err.Error()
+	if p.opts.OnError != nil {
+		p.opts.OnError(err)
+	}
+}
+
+func clip(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+func isWebURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,551 @@
+// Package download
+package download
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/atomicfile"
+)
+
+type Kind string
+
+const (
+	KindAudio Kind = "audio"
+	KindVideo Kind = "video"
+)
+
+type Status string
+
+const (
+	StatusQueued   Status = "queued"
+	StatusRunning  Status = "running"
+	StatusPaused   Status = "paused"
+	StatusDone     Status = "done"
+	StatusFailed   Status = "failed"
+	StatusCanceled Status = "canceled"
+)
+
+type Request struct {
+	URL          string
+	Title        string
+	Author       string
+	Kind         Kind
+	AudioFormat  string
+	AudioBitrate string
+	VideoFormat  string
+	Album        string
+	TrackNumber  int
+}
+
+type Job struct {
+	ID           string    `json:"id"`
+	URL          string    `json:"url"`
+	Title        string    `json:"title"`
+	Author       string    `json:"author"`
+	Kind         Kind      `json:"kind"`
+	AudioFormat  string    `json:"audio_format,omitempty"`
+	AudioBitrate string    `json:"audio_bitrate,omitempty"`
+	VideoFormat  string    `json:"video_format,omitempty"`
+	Album        string    `json:"album,omitempty"`
+	TrackNumber  int       `json:"track_number,omitempty"`
+	Status       Status    `json:"status"`
+	Progress     float64   `json:"progress"`
+	Speed        float64   `json:"-"`
+	ETA          int       `json:"-"`
+	Processing   bool      `json:"-"`
+	Path         string    `json:"path,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	FinishedAt   time.Time `json:"finished_at,omitzero"`
+}
+
+func (j Job) Active() bool {
+	return j.Status == StatusQueued || j.Status == StatusRunning
+}
+
+type Options struct {
+	Dir              string
+	Concurrency      int
+	QueuePath        string
+	FilenameTemplate string
+}
+
+type Manager struct {
+	opts Options
+
+	mu      sync.Mutex
+	jobs    []*Job
+	procs   map[string]func()
+	parts   map[string][]string
+	stopped map[string]Status
+	removed map[string]bool
+	notify  func(Job)
+	seq     int
+	closed  bool
+
+	pending   []Job
+	notifying bool
+}
+
+var ErrDuplicate = errors.New("download already queued")
+
+func NewManager(opts Options) *Manager {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 2
+	}
+	if opts.FilenameTemplate == "" {
+		opts.FilenameTemplate = DefaultFilenameTemplate
+	}
+	m := &Manager{
+		opts:    opts,
+		procs:   map[string]func(){},
+		parts:   map[string][]string{},
+		stopped: map[string]Status{},
+		removed: map[string]bool{},
+	}
+	m.load()
+	return m
+}
+
+func (m *Manager) SetNotify(fn func(Job)) {
+	m.mu.Lock()
+	m.notify = fn
+	m.mu.Unlock()
+}
+
+func (m *Manager) Dir() string {
+	return m.opts.Dir
+}
+
+func (m *Manager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.schedule()
+}
+
+func (m *Manager) Enqueue(req Request) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, j := range m.jobs {
+		if j.URL == req.URL && j.Kind == req.Kind && (j.Active() || j.Status == StatusPaused) {
+			return *j, ErrDuplicate
+		}
+	}
+
+	m.seq++
+	job := &Job{
+		ID:           strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.Itoa(m.seq),
+		URL:          req.URL,
+		Title:        req.Title,
+		Author:       req.Author,
+		Kind:         req.Kind,
+		AudioFormat:  req.AudioFormat,
+		AudioBitrate: req.AudioBitrate,
+		VideoFormat:  req.VideoFormat,
+		Album:        req.Album,
+		TrackNumber:  req.TrackNumber,
+		Status:       StatusQueued,
+		CreatedAt:    time.Now(),
+	}
+	m.jobs = append(m.jobs, job)
+	m.changed(job)
+	m.schedule()
+	m.save()
+	return *job, nil
+}
+
+func (m *Manager) Jobs() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := make([]Job, len(m.jobs))
+	for i, j := range m.jobs {
+		jobs[i] = *j
+	}
+	return jobs
+}
+
+func (m *Manager) Counts() (active, total int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, j := range m.jobs {
+		if j.Active() {
+			active++
+		}
+	}
+	return active, len(m.jobs)
+}
+
+func (m *Manager) Pause(id string) {
+	m.interrupt(id, StatusPaused)
+}
+
+func (m *Manager) Cancel(id string) {
+	m.interrupt(id, StatusCanceled)
+}
+
+func (m *Manager) Resume(id string) {
+	m.requeue(id, StatusPaused)
+}
+
+func (m *Manager) Retry(id string) {
+	m.requeue(id, StatusFailed, StatusCanceled)
+}
+
+func (m *Manager) Remove(id string) {
+	m.interrupt(id, StatusCanceled)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job := m.find(id)
+	if job == nil {
+		return
+	}
+	if job.Active() {
+		m.removed[id] = true
+		return
+	}
+	m.drop(id)
+	m.save()
+}
+
+func (m *Manager) drop(id string) {
+	for i, j := range m.jobs {
+		if j.ID == id {
+			m.jobs = append(m.jobs[:i], m.jobs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (m *Manager) ClearFinished() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.jobs[:0]
+	removed := 0
+	for _, j := range m.jobs {
+		if j.Status == StatusDone || j.Status == StatusCanceled {
+			removed++
+			continue
+		}
+		kept = append(kept, j)
+	}
+	m.jobs = kept
+	m.save()
+	return removed
+}
+
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	m.closed = true
+	for id, stop := range m.procs {
+		m.stopped[id] = StatusQueued
+		stop()
+	}
+	for _, j := range m.jobs {
+		if j.Status == StatusRunning {
+			j.Status = StatusQueued
+		}
+	}
+	m.save()
+	m.mu.Unlock()
+}
+
+func (m *Manager) interrupt(id string, status Status) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job := m.find(id)
+	if job == nil {
+		return
+	}
+	switch job.Status {
+	case StatusRunning:
+		m.stopped[id] = status
+		if stop := m.procs[id]; stop != nil {
+			stop()
+		}
+	case StatusQueued, StatusPaused:
+		if status == StatusPaused && job.Status == StatusPaused {
+			return
+		}
+		job.Status = status
+		if status == StatusCanceled {
+			m.removeParts(id)
+		}
+		m.changed(job)
+		m.save()
+	}
+}
+
+func (m *Manager) requeue(id string, from ...Status) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job := m.find(id)
+	if job == nil {
+		return
+	}
+	for _, s := range from {
+		if job.Status == s {
+			job.Status = StatusQueued
+			job.Error = ""
+			if s != StatusPaused {
+				job.Progress = 0
+			}
+			m.changed(job)
+			m.schedule()
+			m.save()
+			return
+		}
+	}
+}
+
+func (m *Manager) find(id string) *Job {
+	for _, j := range m.jobs {
+		if j.ID == id {
+			return j
+		}
+	}
+	return nil
+}
+
+func (m *Manager) schedule() {
+	if m.closed {
+		return
+	}
+	for _, j := range m.jobs {
+		if len(m.procs) >= m.opts.Concurrency {
+			return
+		}
+		if j.Status == StatusQueued {
+			m.start(j)
+		}
+	}
+}
+
+func (m *Manager) start(job *Job) {
+	if err := os.MkdirAll(m.opts.Dir, 0o755); err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+		m.changed(job)
+		return
+	}
+
+	cmd := exec.Command("yt-dlp", buildArgs(*job, m.opts.Dir, m.opts.FilenameTemplate)...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+		m.changed(job)
+		return
+	}
+	cmd.Stderr = cmd.Stdout
+
+	if err := cmd.Start(); err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+		m.changed(job)
+		return
+	}
+
+	job.Status = StatusRunning
+	job.Error = ""
+	job.Processing = false
+	m.procs[job.ID] = func() { killGroup(cmd) }
+	m.changed(job)
+
+	go m.run(job, cmd, stdout)
+}
+
+func (m *Manager) run(job *Job, cmd *exec.Cmd, stdout io.Reader) {
+	var lastLine, uploadDate string
+	var lastNotify time.Time
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		m.mu.Lock()
+		switch ev := parseLine(line); ev.kind {
+		case lineProgress:
+			job.Progress = ev.progress
+			job.Speed = ev.speed
+			job.ETA = ev.eta
+			if time.Since(lastNotify) > 250*time.Millisecond {
+				lastNotify = time.Now()
+				m.changed(job)
+			}
+		case lineDestination:
+			m.parts[job.ID] = append(m.parts[job.ID], ev.path)
+		case lineProcessing:
+			job.Processing = true
+			m.changed(job)
+		case lineFile:
+			job.Path = ev.path
+		case lineDate:
+			uploadDate = ev.value
+		default:
+			lastLine = line
+		}
+		m.mu.Unlock()
+	}
+
+	err := cmd.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, interrupted := m.stopped[job.ID]
+	if err == nil && !interrupted && job.Kind == KindAudio && job.Path != "" {
+		ctx, cancel := context.WithCancel(context.Background())
+		m.procs[job.ID] = cancel
+		job.Processing = true
+		m.changed(job)
+		tagged := *job
+		m.mu.Unlock()
+
+		tagErr := tagFile(ctx, tagged.Path, tagsFor(tagged, uploadDate), fetchCover(ctx, tagged.URL))
+
+		m.mu.Lock()
+		cancel()
+		if _, stopped := m.stopped[job.ID]; tagErr != nil && !stopped {
+			job.Error = "tags: " + tagErr.Error()
+		}
+	}
+	delete(m.procs, job.ID)
+
+	stoppedAs, interrupted := m.stopped[job.ID]
+	delete(m.stopped, job.ID)
+	job.Speed = 0
+	job.ETA = 0
+	job.Processing = false
+
+	switch {
+	case interrupted:
+		job.Status = stoppedAs
+		if stoppedAs == StatusCanceled {
+			m.removeParts(job.ID)
+			if err == nil && job.Path != "" {
+				_ = os.Remove(job.Path)
+			}
+			job.Progress = 0
+		}
+	case err != nil:
+		job.Status = StatusFailed
+		job.Error = errorMessage(lastLine, err)
+	default:
+		job.Status = StatusDone
+		job.Progress = 100
+		job.FinishedAt = time.Now()
+		delete(m.parts, job.ID)
+	}
+
+	if m.removed[job.ID] {
+		delete(m.removed, job.ID)
+		m.drop(job.ID)
+	}
+	m.changed(job)
+	m.schedule()
+	m.save()
+}
+
+func (m *Manager) removeParts(id string) {
+	for _, p := range m.parts[id] {
+		_ = os.Remove(p + ".part")
+		_ = os.Remove(p + ".ytdl")
+	}
+	delete(m.parts, id)
+}
+
+func (m *Manager) changed(job *Job) {
+	if m.notify == nil {
+		return
+	}
+	m.pending = append(m.pending, *job)
+	if !m.notifying {
+		m.notifying = true
+		go m.deliver()
+	}
+}
+
+func (m *Manager) deliver() {
+	for {
+		m.mu.Lock()
+		jobs, notify := m.pending, m.notify
+		m.pending = nil
+		if len(jobs) == 0 || notify == nil {
+			m.notifying = false
+			m.mu.Unlock()
+			return
+		}
+		m.mu.Unlock()
+
+		for _, job := range jobs {
+			notify(job)
+		}
+	}
+}
+
+func (m *Manager) load() {
+	if m.opts.QueuePath == "" {
+		return
+	}
+	data, err := os.ReadFile(m.opts.QueuePath)
+	if err != nil {
+		return
+	}
+	var jobs []*Job
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return
+	}
+	for _, j := range jobs {
+		if j.Status == StatusRunning {
+			j.Status = StatusQueued
+		}
+	}
+	m.jobs = jobs
+}
+
+func (m *Manager) save() {
+	if m.opts.QueuePath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(m.jobs, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = atomicfile.WriteFile(m.opts.QueuePath, data, 0o644)
+}
+
+func killGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM); err != nil {
+		_ = cmd.Process.Kill()
+	}
+}
+
+func errorMessage(lastLine string, err error) string {
+	if msg, ok := strings.CutPrefix(lastLine, "ERROR: "); ok {
+		return msg
+	}
+	if lastLine != "" {
+		return lastLine
+	}
+	return err.Error()
+}
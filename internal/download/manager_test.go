@@ -0,0 +1,131 @@
+package download
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func fakeTools(t *testing.T, out string) {
+	t.Helper()
+	dir := t.TempDir()
+	scripts := map[string]string{
+		"yt-dlp": `for last; do :; done
+file="$FAKE_OUT/$(basename "$last").mp3"
+printf audio > "$file"
+echo "[ExtractAudio] Destination: $file"
+echo "[youtui:file] $file"
+`,
+		"ffmpeg": `case "$*" in
+*slow*) touch "$FAKE_OUT/tagging"; exec sleep 5 ;;
+esac
+for last; do :; done
+printf tagged > "$last"
+`,
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("FAKE_OUT", out)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func waitJob(t *testing.T, m *Manager, id string, cond func(Job) bool) Job {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		for _, j := range m.Jobs() {
+			if j.ID == id && cond(j) {
+				return j
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s did not reach the expected state: %+v", id, m.Jobs())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestManagerHoldsSlotWhileTagging(t *testing.T) {
+	out := t.TempDir()
+	fakeTools(t, out)
+
+	var mu sync.Mutex
+	var seen []Job
+	m := NewManager(Options{Dir: out, Concurrency: 1})
+	m.SetNotify(func(j Job) {
+		mu.Lock()
+		seen = append(seen, j)
+		mu.Unlock()
+	})
+
+	slow, err := m.Enqueue(Request{URL: "https://example.com/slow", Title: "Slow", Kind: KindAudio})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, err := m.Enqueue(Request{URL: "https://example.com/fast", Title: "Fast", Kind: KindAudio})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitJob(t, m, slow.ID, func(j Job) bool { return j.Processing })
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if _, err := os.Stat(filepath.Join(out, "tagging")); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("ffmpeg never started")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if j := waitJob(t, m, fast.ID, func(Job) bool { return true }); j.Status != StatusQueued {
+		t.Fatalf("second job %s while the first is tagging, want it queued", j.Status)
+	}
+
+	start := time.Now()
+	m.Cancel(slow.ID)
+	waitJob(t, m, slow.ID, func(j Job) bool { return j.Status == StatusCanceled })
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("cancel waited %s for tagging to finish", elapsed)
+	}
+	if _, err := os.Stat(filepath.Join(out, "slow.mp3")); !os.IsNotExist(err) {
+		t.Fatalf("canceled download left behind: %v", err)
+	}
+
+	done := waitJob(t, m, fast.ID, func(j Job) bool { return j.Status == StatusDone })
+	if data, _ := os.ReadFile(done.Path); string(data) != "tagged" {
+		t.Fatalf("finished file = %q, want it tagged", data)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	final := map[string]Status{}
+	for _, j := range seen {
+		if s := final[j.ID]; s == StatusDone || s == StatusCanceled {
+			t.Fatalf("notification %s/%s delivered after %s", j.ID, j.Status, s)
+		}
+		final[j.ID] = j.Status
+	}
+	if final[slow.ID] != StatusCanceled || final[fast.ID] != StatusDone {
+		t.Fatalf("last notifications = %v", final)
+	}
+}
+
+func TestJobOmitsZeroFinishedAt(t *testing.T) {
+	data, err := json.Marshal(Job{ID: "1", Status: StatusQueued, CreatedAt: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "finished_at") {
+		t.Fatalf("queued job persisted finished_at: %s", data)
+	}
+}
@@ -0,0 +1,224 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/search"
+)
+
+type Tags struct {
+	Title   string
+	Artist  string
+	Album   string
+	Track   int
+	Date    string
+	Comment string
+}
+
+func tagsFor(job Job, uploadDate string) Tags {
+	t := Tags{
+		Title:   job.Title,
+		Artist:  job.Author,
+		Album:   job.Album,
+		Track:   job.TrackNumber,
+		Comment: job.URL,
+	}
+	if len(uploadDate) == 8 {
+		t.Date = uploadDate[0:4] + "-" + uploadDate[4:6] + "-" + uploadDate[6:8]
+	}
+	return t
+}
+
+func (t Tags) metadata() []string {
+	fields := []struct{ key, value string }{
+		{"title", t.Title},
+		{"artist", t.Artist},
+		{"album", t.Album},
+		{"date", t.Date},
+		{"comment", t.Comment},
+	}
+	if t.Track > 0 {
+		fields = append(fields, struct{ key, value string }{"track", strconv.Itoa(t.Track)})
+	}
+
+	var kv []string
+	for _, f := range fields {
+		if f.value != "" {
+			kv = append(kv, f.key+"="+f.value)
+		}
+	}
+	return kv
+}
+
+func tagArgs(in, out string, tags Tags, cover string, picture string) []string {
+	ext := strings.ToLower(filepath.Ext(in))
+	oggLike := ext == ".opus" || ext == ".ogg" || ext == ".oga"
+
+	args := []string{"-y", "-v", "error", "-i", in}
+	if cover != "" && !oggLike {
+		args = append(args, "-i", cover)
+	}
+
+	args = append(args, "-map", "0:a", "-c:a", "copy", "-map_metadata", "0")
+	if cover != "" && !oggLike {
+		args = append(args,
+			"-map", "1:v", "-c:v", "copy",
+			"-disposition:v", "attached_pic",
+			"-metadata:s:v", "title=Album cover",
+			"-metadata:s:v", "comment=Cover (front)",
+		)
+	}
+
+	for _, kv := range tags.metadata() {
+		args = append(args, "-metadata", kv)
+		if oggLike {
+			args = append(args, "-metadata:s:a:0", kv)
+		}
+	}
+	if oggLike && picture != "" {
+		args = append(args, "-metadata:s:a:0", "METADATA_BLOCK_PICTURE="+picture)
+	}
+	if ext == ".mp3" {
+		args = append(args, "-id3v2_version", "4")
+	}
+
+	return append(args, out)
+}
+
+func canTag(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3", ".m4a", ".flac", ".opus", ".ogg", ".oga":
+		return true
+	}
+	return false
+}
+
+func tagFile(ctx context.Context, path string, tags Tags, cover []byte) error {
+	if !canTag(path) {
+		return nil
+	}
+
+	dir, base := filepath.Split(path)
+	out := filepath.Join(dir, ".tagging-"+base)
+
+	var coverPath, picture string
+	if len(cover) > 0 {
+		f, err := os.CreateTemp("", "youtui-cover-*.jpg")
+		if err != nil {
+			return err
+		}
+		coverPath = f.Name()
+		defer func() {
+			_ = os.Remove(coverPath)
+		}()
+		_, werr := f.Write(cover)
+		cerr := f.Close()
+		if werr != nil {
+			return werr
+		}
+		if cerr != nil {
+			return cerr
+		}
+		picture = flacPicture(cover)
+	}
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", tagArgs(path, out, tags, coverPath, picture)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		_ = os.Remove(out)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("ffmpeg: %s", msg)
+		}
+		return fmt.Errorf("ffmpeg: %w", err)
+	}
+
+	return os.Rename(out, path)
+}
+
+func flacPicture(data []byte) string {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+
+	const mime = "image/jpeg"
+	var buf bytes.Buffer
+	write := func(v uint32) {
+		_ = binary.Write(&buf, binary.BigEndian, v)
+	}
+	write(3)
+	write(uint32(len(mime)))
+	buf.WriteString(mime)
+	write(0)
+	write(uint32(cfg.Width))
+	write(uint32(cfg.Height))
+	write(24)
+	write(0)
+	write(uint32(len(data)))
+	buf.Write(data)
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func fetchCover(ctx context.Context, videoURL string) []byte {
+	id := search.VideoID(videoURL)
+	if id == "" {
+		return nil
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://i.ytimg.com/vi/"+id+"/hqdefault.jpg", nil)
+	if err != nil {
+		return nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 5<<20))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func expandTemplate(tmpl string, job Job) string {
+	track := ""
+	if job.TrackNumber > 0 {
+		track = fmt.Sprintf("%02d", job.TrackNumber)
+	}
+	return strings.NewReplacer(
+		"{artist}", templateValue(job.Author),
+		"{album}", templateValue(job.Album),
+		"{track}", track,
+	).Replace(tmpl)
+}
+
+func templateValue(s string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", "%", "%%").Replace(strings.TrimSpace(s))
+}
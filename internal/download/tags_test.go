@@ -0,0 +1,413 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 20), G: uint8(y * 20), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestTagsFor(t *testing.T) {
+	job := Job{
+		Title:       "Never Gonna Give You Up",
+		Author:      "Rick Astley",
+		Album:       "Road trip",
+		TrackNumber: 3,
+		URL:         "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+	}
+
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"19871025", "1987-10-25"},
+		{"", ""},
+		{"NA", ""},
+		{"1987", ""},
+	}
+	for _, tt := range tests {
+		got := tagsFor(job, tt.date)
+		want := Tags{
+			Title:   job.Title,
+			Artist:  job.Author,
+			Album:   job.Album,
+			Track:   3,
+			Date:    tt.want,
+			Comment: job.URL,
+		}
+		if got != want {
+			t.Fatalf("tagsFor(%q) = %+v, want %+v", tt.date, got, want)
+		}
+	}
+}
+
+func TestTagArgs(t *testing.T) {
+	tags := Tags{Title: "Song", Artist: "Band", Track: 7, Date: "2024-01-02"}
+
+	tests := []struct {
+		name    string
+		in      string
+		cover   string
+		picture string
+		want    []string
+	}{
+		{
+			name:  "mp3 with cover",
+			in:    "a.mp3",
+			cover: "cover.jpg",
+			want: []string{
+				"-y", "-v", "error", "-i", "a.mp3", "-i", "cover.jpg",
+				"-map", "0:a", "-c:a", "copy", "-map_metadata", "0",
+				"-map", "1:v", "-c:v", "copy",
+				"-disposition:v", "attached_pic",
+				"-metadata:s:v", "title=Album cover",
+				"-metadata:s:v", "comment=Cover (front)",
+				"-metadata", "title=Song",
+				"-metadata", "artist=Band",
+				"-metadata", "date=2024-01-02",
+				"-metadata", "track=7",
+				"-id3v2_version", "4",
+				"out.mp3",
+			},
+		},
+		{
+			name: "m4a without cover",
+			in:   "a.M4A",
+			want: []string{
+				"-y", "-v", "error", "-i", "a.M4A",
+				"-map", "0:a", "-c:a", "copy", "-map_metadata", "0",
+				"-metadata", "title=Song",
+				"-metadata", "artist=Band",
+				"-metadata", "date=2024-01-02",
+				"-metadata", "track=7",
+				"out.mp3",
+			},
+		},
+		{
+			name:    "opus keeps the cover in a picture block",
+			in:      "a.opus",
+			cover:   "cover.jpg",
+			picture: "UElD",
+			want: []string{
+				"-y", "-v", "error", "-i", "a.opus",
+				"-map", "0:a", "-c:a", "copy", "-map_metadata", "0",
+				"-metadata", "title=Song", "-metadata:s:a:0", "title=Song",
+				"-metadata", "artist=Band", "-metadata:s:a:0", "artist=Band",
+				"-metadata", "date=2024-01-02", "-metadata:s:a:0", "date=2024-01-02",
+				"-metadata", "track=7", "-metadata:s:a:0", "track=7",
+				"-metadata:s:a:0", "METADATA_BLOCK_PICTURE=UElD",
+				"out.mp3",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tagArgs(tt.in, "out.mp3", tags, tt.cover, tt.picture)
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("tagArgs() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagsMetadataSkipsEmpty(t *testing.T) {
+	got := Tags{Title: "Only", Comment: "https://youtu.be/x"}.metadata()
+	want := []string{"title=Only", "comment=https://youtu.be/x"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("metadata() = %q, want %q", got, want)
+	}
+}
+
+func TestFlacPicture(t *testing.T) {
+	cover := testJPEG(t, 12, 7)
+	raw, err := base64.StdEncoding.DecodeString(flacPicture(cover))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := bytes.NewReader(raw)
+	next := func() uint32 {
+		var v uint32
+		if err := binary.Read(r, binary.BigEndian, &v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	text := func(n uint32) string {
+		b := make([]byte, n)
+		if _, err := r.Read(b); err != nil && n > 0 {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	if typ := next(); typ != 3 {
+		t.Fatalf("picture type = %d, want 3 (front cover)", typ)
+	}
+	if mime := text(next()); mime != "image/jpeg" {
+		t.Fatalf("mime = %q", mime)
+	}
+	if desc := text(next()); desc != "" {
+		t.Fatalf("description = %q", desc)
+	}
+	if w, h := next(), next(); w != 12 || h != 7 {
+		t.Fatalf("size = %dx%d, want 12x7", w, h)
+	}
+	if depth := next(); depth != 24 {
+		t.Fatalf("depth = %d", depth)
+	}
+	if colors := next(); colors != 0 {
+		t.Fatalf("colors = %d", colors)
+	}
+	n := next()
+	data := make([]byte, n)
+	if _, err := r.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, cover) || r.Len() != 0 {
+		t.Fatal("picture data does not match the cover")
+	}
+
+	if got := flacPicture([]byte("not an image")); got != "" {
+		t.Fatalf("flacPicture(garbage) = %q, want empty", got)
+	}
+}
+
+func TestExpandTemplate(t *testing.T) {
+	tests := []struct {
+		tmpl string
+		job  Job
+		want string
+	}{
+		{
+			"{artist}/{album}/{track} - %(title)s.%(ext)s",
+			Job{Author: "Rick Astley", Album: "Hits", TrackNumber: 4},
+			"Rick Astley/Hits/04 - %(title)s.%(ext)s",
+		},
+		{
+			"{artist}/%(title)s",
+			Job{Author: " AC/DC "},
+			"AC_DC/%(title)s",
+		},
+		{
+			"{album}/{track}%(title)s",
+			Job{Album: `100% a\b`},
+			"100%% a_b/%(title)s",
+		},
+		{
+			"%(title)s [%(id)s].%(ext)s",
+			Job{Author: "x"},
+			"%(title)s [%(id)s].%(ext)s",
+		},
+	}
+	for _, tt := range tests {
+		if got := expandTemplate(tt.tmpl, tt.job); got != tt.want {
+			t.Fatalf("expandTemplate(%q) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+}
+
+func fakeFFmpeg(t *testing.T, script string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ffmpeg"), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+func TestTagFile(t *testing.T) {
+	bin := fakeFFmpeg(t, `printf '%s\n' "$@" > "$(dirname "$0")/args"
+for last; do :; done
+printf tagged > "$last"
+`)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "song.flac")
+	if err := os.WriteFile(path, []byte("raw"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tagFile(context.Background(), path, Tags{Title: "Song"}, testJPEG(t, 4, 4)); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "tagged" {
+		t.Fatalf("file not replaced with the tagged copy: %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".tagging-song.flac")); !os.IsNotExist(err) {
+		t.Fatalf("temporary output left behind: %v", err)
+	}
+
+	raw, _ := os.ReadFile(filepath.Join(bin, "args"))
+	args := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if !slices.Contains(args, "attached_pic") || !slices.Contains(args, "title=Song") {
+		t.Fatalf("unexpected ffmpeg args %q", args)
+	}
+	coverIdx := slices.Index(args, "-i") + 2
+	if cover := args[coverIdx+1]; !strings.HasSuffix(cover, ".jpg") {
+		t.Fatalf("cover input = %q", cover)
+	} else if _, err := os.Stat(cover); !os.IsNotExist(err) {
+		t.Fatalf("temporary cover %s not removed", cover)
+	}
+}
+
+func TestTagFileFailureKeepsOriginal(t *testing.T) {
+	fakeFFmpeg(t, `for last; do :; done
+printf partial > "$last"
+echo "Invalid data found when processing input" >&2
+exit 1
+`)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "song.mp3")
+	if err := os.WriteFile(path, []byte("raw"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := tagFile(context.Background(), path, Tags{Title: "Song"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "Invalid data found") {
+		t.Fatalf("tagFile() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "raw" {
+		t.Fatalf("original changed: %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".tagging-song.mp3")); !os.IsNotExist(err) {
+		t.Fatal("partial output left behind")
+	}
+}
+
+func TestTagFileSkipsUnsupported(t *testing.T) {
+	fakeFFmpeg(t, "exit 1\n")
+	path := filepath.Join(t.TempDir(), "clip.webm")
+	if err := os.WriteFile(path, []byte("raw"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tagFile(context.Background(), path, Tags{Title: "Song"}, nil); err != nil {
+		t.Fatalf("tagFile(webm) = %v, want nil", err)
+	}
+}
+
+func TestTagFileRoundTrip(t *testing.T) {
+	for _, tool := range []string{"ffmpeg", "ffprobe"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not installed", tool)
+		}
+	}
+
+	tags := Tags{
+		Title:   "Never Gonna Give You Up",
+		Artist:  "Rick Astley",
+		Album:   "Road trip",
+		Track:   3,
+		Date:    "1987-10-25",
+		Comment: "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+	}
+	cover := testJPEG(t, 16, 16)
+
+	for _, tt := range []struct {
+		ext, codec string
+		picture    bool
+	}{
+		{".mp3", "libmp3lame", true},
+		{".m4a", "aac", true},
+		{".flac", "flac", true},
+		{".opus", "libopus", false},
+	} {
+		t.Run(tt.ext, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tone"+tt.ext)
+			gen := exec.Command("ffmpeg", "-y", "-v", "error",
+				"-f", "lavfi", "-i", "sine=frequency=440:duration=1",
+				"-c:a", tt.codec, path)
+			if out, err := gen.CombinedOutput(); err != nil {
+				t.Skipf("cannot encode %s: %v %s", tt.ext, err, out)
+			}
+
+			if err := tagFile(context.Background(), path, tags, cover); err != nil {
+				t.Fatal(err)
+			}
+
+			out, err := exec.Command("ffprobe", "-v", "error", "-print_format", "json",
+				"-show_format", "-show_streams", path).Output()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var probe struct {
+				Format struct {
+					Tags map[string]string `json:"tags"`
+				} `json:"format"`
+				Streams []struct {
+					CodecType   string            `json:"codec_type"`
+					Tags        map[string]string `json:"tags"`
+					Disposition map[string]int    `json:"disposition"`
+				} `json:"streams"`
+			}
+			if err := json.Unmarshal(out, &probe); err != nil {
+				t.Fatal(err)
+			}
+
+			got := map[string]string{}
+			collect := func(m map[string]string) {
+				for k, v := range m {
+					got[strings.ToLower(k)] = v
+				}
+			}
+			collect(probe.Format.Tags)
+			hasPicture := false
+			for _, s := range probe.Streams {
+				if s.CodecType == "audio" {
+					collect(s.Tags)
+				}
+				if s.CodecType == "video" && s.Disposition["attached_pic"] == 1 {
+					hasPicture = true
+				}
+			}
+
+			for key, want := range map[string]string{
+				"title":   tags.Title,
+				"artist":  tags.Artist,
+				"album":   tags.Album,
+				"comment": tags.Comment,
+			} {
+				if got[key] != want {
+					t.Errorf("%s = %q, want %q (all tags %v)", key, got[key], want, got)
+				}
+			}
+			if track := got["track"]; track != "3" && !strings.HasPrefix(track, "3/") {
+				t.Errorf("track = %q, want 3", track)
+			}
+			if !strings.HasPrefix(got["date"], "1987") {
+				t.Errorf("date = %q, want 1987-10-25", got["date"])
+			}
+			if tt.picture && !hasPicture {
+				t.Error("cover not attached")
+			}
+			if !tt.picture && got["metadata_block_picture"] == "" && !hasPicture {
+				t.Error("cover picture block missing")
+			}
+		})
+	}
+}
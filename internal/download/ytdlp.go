@@ -0,0 +1,119 @@
+package download
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	progressPrefix = "[youtui:progress]"
+	filePrefix     = "[youtui:file]"
+	datePrefix     = "[youtui:date]"
+
+	DefaultFilenameTemplate = "%(title)s [%(id)s].%(ext)s"
+)
+
+type lineKind int
+
+const (
+	lineOther lineKind = iota
+	lineProgress
+	lineDestination
+	lineProcessing
+	lineFile
+	lineDate
+)
+
+type lineEvent struct {
+	kind     lineKind
+	progress float64
+	speed    float64
+	eta      int
+	path     string
+	value    string
+}
+
+func buildArgs(job Job, dir, filenameTemplate string) []string {
+	args := []string{
+		"--newline",
+		"--no-colors",
+		"--no-playlist",
+		"--continue",
+		"--no-quiet",
+		"--progress",
+		"--progress-template", "download:" + progressPrefix +
+			" %(progress.downloaded_bytes)s %(progress.total_bytes)s %(progress.total_bytes_estimate)s %(progress.speed)s %(progress.eta)s",
+		"--print", "after_move:" + filePrefix + " %(filepath)s",
+		"--print", "after_move:" + datePrefix + " %(upload_date)s",
+		"--paths", dir,
+		"--output", expandTemplate(filenameTemplate, job),
+	}
+
+	if job.Kind == KindAudio {
+		args = append(args, "--format", "bestaudio/best", "--extract-audio")
+		if job.AudioFormat != "" {
+			args = append(args, "--audio-format", job.AudioFormat)
+		}
+		if job.AudioBitrate != "" {
+			args = append(args, "--audio-quality", job.AudioBitrate)
+		}
+	} else if job.VideoFormat != "" {
+		args = append(args, "--format", job.VideoFormat)
+	}
+
+	return append(args, "--", job.URL)
+}
+
+func parseLine(line string) lineEvent {
+	switch {
+	case strings.HasPrefix(line, progressPrefix):
+		return parseProgress(strings.Fields(strings.TrimPrefix(line, progressPrefix)))
+
+	case strings.HasPrefix(line, filePrefix):
+		return lineEvent{kind: lineFile, path: strings.TrimSpace(strings.TrimPrefix(line, filePrefix))}
+
+	case strings.HasPrefix(line, datePrefix):
+		return lineEvent{kind: lineDate, value: strings.TrimSpace(strings.TrimPrefix(line, datePrefix))}
+
+	case strings.HasPrefix(line, "[download] Destination: "):
+		return lineEvent{kind: lineDestination, path: strings.TrimPrefix(line, "[download] Destination: ")}
+
+	case strings.HasPrefix(line, "[ExtractAudio]"),
+		strings.HasPrefix(line, "[Merger]"),
+		strings.HasPrefix(line, "[VideoConvertor]"),
+		strings.HasPrefix(line, "[FixupM4a]"),
+		strings.HasPrefix(line, "[Metadata]"),
+		strings.HasPrefix(line, "[EmbedThumbnail]"):
+		return lineEvent{kind: lineProcessing}
+	}
+	return lineEvent{kind: lineOther}
+}
+
+func parseProgress(fields []string) lineEvent {
+	if len(fields) < 5 {
+		return lineEvent{kind: lineOther}
+	}
+	downloaded := parseNumber(fields[0])
+	total := parseNumber(fields[1])
+	if total <= 0 {
+		total = parseNumber(fields[2])
+	}
+
+	ev := lineEvent{
+		kind:  lineProgress,
+		speed: parseNumber(fields[3]),
+		eta:   int(parseNumber(fields[4])),
+	}
+	if total > 0 {
+		ev.progress = min(downloaded/total*100, 100)
+	}
+	return ev
+}
+
+func parseNumber(s string) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,809 @@
+// Package engine runs mpv and owns the playlist and the playback state
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const MaxVolume = 130
+
+var (
+	ErrNothingPlaying  = errors.New("nothing playing")
+	ErrPlaylistEmpty   = errors.New("playlist is empty")
+	ErrLastTrack       = errors.New("already at the last track")
+	ErrFirstTrack      = errors.New("already at the first track")
+	ErrNoTrack         = errors.New("no such track")
+	ErrSocatMissing    = errors.New("socat is not installed")
+	ErrPlaylistChanged = errors.New("playlist changed since it was read")
+)
+
+type Track struct {
+	Title       string    `json:"title"`
+	Author      string    `json:"author,omitempty"`
+	URL         string    `json:"url"`
+	Thumbnail   string    `json:"thumbnail,omitempty"`
+	Duration    string    `json:"duration,omitempty"`
+	PublishedAt string    `json:"published_at,omitempty"`
+	Description string    `json:"description,omitempty"`
+	AddedAt     time.Time `json:"added_at,omitzero"`
+}
+
+type PlaylistMode int
+
+const (
+	ModeNormal PlaylistMode = iota
+	ModeRepeatOne
+	ModeRepeatAll
+	ModeShuffle
+)
+
+func (m PlaylistMode) String() string {
+	switch m {
+	case ModeShuffle:
+		return " Shuffle"
+	case ModeRepeatOne:
+		return "󰑘 Repeat 1"
+	case ModeRepeatAll:
+		return "󰑖 Repeat All"
+	default:
+		return "▶ Normal"
+	}
+}
+
+var playlistModeNames = []string{"normal", "repeat_one", "repeat_all", "shuffle"}
+
+func (m PlaylistMode) MarshalText() ([]byte, error) {
+	if m < 0 || int(m) >= len(playlistModeNames) {
+		return nil, fmt.Errorf("invalid playlist mode %d", m)
+	}
+	return []byte(playlistModeNames[m]), nil
+}
+
+func (m *PlaylistMode) UnmarshalText(text []byte) error {
+	for i, name := range playlistModeNames {
+		if name == string(text) {
+			*m = PlaylistMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid playlist mode %q", text)
+}
+
+type PlayMode int
+
+const (
+	ModeAudio PlayMode = iota
+	ModeVideo
+)
+
+func (m PlayMode) String() string {
+	if m == ModeAudio {
+		return " Audio"
+	}
+	return "󰗃 Video"
+}
+
+func (m PlayMode) MarshalText() ([]byte, error) {
+	if m == ModeVideo {
+		return []byte("video"), nil
+	}
+	return []byte("audio"), nil
+}
+
+func (m *PlayMode) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "audio":
+		*m = ModeAudio
+	case "video":
+		*m = ModeVideo
+	default:
+		return fmt.Errorf("invalid play mode %q", text)
+	}
+	return nil
+}
+
+type State struct {
+	Playing     bool         `json:"playing"`
+	Paused      bool         `json:"paused"`
+	Track       Track        `json:"track"`
+	Index       int          `json:"index"`
+	Session     uint64       `json:"session"`
+	Local       bool         `json:"local"`
+	Position    float64      `json:"position"`
+	Duration    float64      `json:"duration"`
+	Volume      int          `json:"volume"`
+	Mode        PlaylistMode `json:"mode"`
+	PlayMode    PlayMode     `json:"play_mode"`
+	Quality     string       `json:"quality"`
+	Codec       string       `json:"codec"`
+	PlaylistLen int          `json:"playlist_length"`
+	Version     uint64       `json:"playlist_version"`
+}
+
+type EventType string
+
+const (
+	EventState    EventType = "state"
+	EventPlaylist EventType = "playlist"
+	EventStarted  EventType = "started"
+	EventEnded    EventType = "ended"
+	EventHistory  EventType = "history"
+)
+
+type Event struct {
+	Type     EventType `json:"type"`
+	State    State     `json:"state"`
+	Playlist []Track   `json:"playlist,omitempty"`
+	Session  uint64    `json:"session,omitempty"`
+	Track    *Track    `json:"track,omitempty"`
+	Index    int       `json:"index"`
+	Finished bool      `json:"finished,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Blocked  bool      `json:"blocked,omitempty"`
+}
+
+type Controller interface {
+	Play(idx int) error
+	PlayTrack(track Track) error
+	TogglePause() error
+	Stop() error
+	Next() error
+	Previous() error
+	Seek(seconds float64, absolute bool) error
+	SetVolume(volume int) error
+	SetPlaylist(tracks []Track, current int) error
+	EditPlaylist(base uint64, tracks []Track) (uint64, error)
+	AddTracks(tracks []Track) error
+	SetPlaylistMode(mode PlaylistMode) error
+	SetPlayMode(mode PlayMode) error
+	SetVideo(quality, codec string) error
+	State() (State, error)
+	Playlist() ([]Track, error)
+	Upcoming(n int) ([]int, error)
+	Subscribe() (<-chan Event, func(), error)
+}
+
+func Resume(c Controller) error {
+	st, err := c.State()
+	if err != nil {
+		return err
+	}
+	switch {
+	case st.Paused:
+		return c.TogglePause()
+	case st.Playing:
+		return nil
+	case st.PlaylistLen == 0:
+		return ErrPlaylistEmpty
+	}
+	return c.Play(min(max(st.Index, 0), st.PlaylistLen-1))
+}
+
+func Pause(c Controller) error {
+	st, err := c.State()
+	if err != nil {
+		return err
+	}
+	if !st.Playing {
+		return ErrNothingPlaying
+	}
+	if st.Paused {
+		return nil
+	}
+	return c.TogglePause()
+}
+
+type Options struct {
+	Resolve  func(Track) (string, bool)
+	PlayMode PlayMode
+	Quality  string
+	Codec    string
+	Volume   int
+}
+
+type Engine struct {
+	resolve func(Track) (string, bool)
+
+	mu       sync.Mutex
+	playlist []Track
+	version  uint64
+	current  int
+	mode     PlaylistMode
+	playMode PlayMode
+	quality  string
+	codec    string
+	volume   int
+	shuffle  *ShuffleBag
+
+	cmd      *exec.Cmd
+	socket   string
+	session  uint64
+	playing  bool
+	paused   bool
+	track    Track
+	local    bool
+	position float64
+	duration float64
+	stop     chan struct{}
+
+	subs    map[int]*EventQueue
+	nextSub int
+}
+
+func New(opts Options) *Engine {
+	resolve := opts.Resolve
+	if resolve == nil {
+		resolve = func(t Track) (string, bool) { return t.URL, false }
+	}
+	volume := opts.Volume
+	if volume <= 0 {
+		volume = 100
+	}
+	return &Engine{
+		resolve:  resolve,
+		current:  -1,
+		playMode: opts.PlayMode,
+		quality:  opts.Quality,
+		codec:    opts.Codec,
+		volume:   min(volume, MaxVolume),
+		shuffle:  NewShuffleBag(),
+		subs:     map[int]*EventQueue{},
+	}
+}
+
+func (e *Engine) Subscribe() (<-chan Event, func(), error) {
+	q := NewEventQueue()
+	e.mu.Lock()
+	id := e.nextSub
+	e.nextSub++
+	e.subs[id] = q
+	e.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			e.mu.Lock()
+			delete(e.subs, id)
+			e.mu.Unlock()
+			q.Stop()
+		})
+	}
+	return q.C(), cancel, nil
+}
+
+func (e *Engine) emitLocked(ev Event) {
+	for id, q := range e.subs {
+		if !q.Push(ev) {
+			delete(e.subs, id)
+		}
+	}
+}
+
+func (e *Engine) Notify(t EventType) {
+	e.mu.Lock()
+	e.emitLocked(Event{Type: t, State: e.stateLocked(), Index: e.current})
+	e.mu.Unlock()
+}
+
+func (e *Engine) emitState() {
+	e.mu.Lock()
+	e.emitLocked(Event{Type: EventState, State: e.stateLocked(), Index: e.current})
+	e.mu.Unlock()
+}
+
+func (e *Engine) emitPlaylistLocked() {
+	e.emitLocked(Event{
+		Type:     EventPlaylist,
+		State:    e.stateLocked(),
+		Playlist: append([]Track(nil), e.playlist...),
+		Index:    e.current,
+	})
+}
+
+func (e *Engine) stateLocked() State {
+	return State{
+		Playing:     e.playing,
+		Paused:      e.paused,
+		Track:       e.track,
+		Index:       e.current,
+		Session:     e.session,
+		Local:       e.local,
+		Position:    e.position,
+		Duration:    e.duration,
+		Volume:      e.volume,
+		Mode:        e.mode,
+		PlayMode:    e.playMode,
+		Quality:     e.quality,
+		Codec:       e.codec,
+		PlaylistLen: len(e.playlist),
+		Version:     e.version,
+	}
+}
+
+func (e *Engine) State() (State, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.stateLocked(), nil
+}
+
+func (e *Engine) Playlist() ([]Track, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Track(nil), e.playlist...), nil
+}
+
+func (e *Engine) Upcoming(n int) ([]int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.mode != ModeShuffle {
+		return nil, nil
+	}
+	return e.shuffle.Upcoming(n), nil
+}
+
+func (e *Engine) Play(idx int) error {
+	e.mu.Lock()
+	if idx < 0 || idx >= len(e.playlist) {
+		e.mu.Unlock()
+		return ErrNoTrack
+	}
+	track := e.playlist[idx]
+	e.mu.Unlock()
+
+	return e.start(track, idx)
+}
+
+func (e *Engine) PlayTrack(track Track) error {
+	return e.start(track, -1)
+}
+
+func (e *Engine) killLocked() {
+	if e.stop != nil {
+		close(e.stop)
+		e.stop = nil
+	}
+	if e.cmd != nil && e.cmd.Process != nil {
+		_ = e.cmd.Process.Kill()
+	}
+	e.cmd = nil
+	e.socket = ""
+}
+
+func (e *Engine) start(track Track, idx int) error {
+	target, local := e.resolve(track)
+	socket := filepath.Join(os.TempDir(), fmt.Sprintf("mpv-socket-%d", time.Now().UnixNano()))
+
+	e.mu.Lock()
+	e.killLocked()
+
+	cmd := exec.Command("mpv", mpvArgs(track, target, local, socket, e.volume, e.playMode, e.quality, e.codec)...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	if err := cmd.Start(); err != nil {
+		e.playing = false
+		e.paused = false
+		e.emitLocked(Event{Type: EventState, State: e.stateLocked(), Index: e.current})
+		e.mu.Unlock()
+		return fmt.Errorf("mpv: %w", err)
+	}
+
+	e.session++
+	session := e.session
+	stop := make(chan struct{})
+	e.cmd = cmd
+	e.socket = socket
+	e.stop = stop
+	e.playing = true
+	e.paused = false
+	e.track = track
+	e.local = local
+	e.current = idx
+	e.position = 0
+	e.duration = 0
+	if idx >= 0 && e.mode == ModeShuffle {
+		e.shuffle.Played(idx)
+	}
+	e.emitLocked(Event{Type: EventStarted, State: e.stateLocked(), Session: session, Track: &track, Index: idx})
+	e.mu.Unlock()
+
+	go e.poll(session, socket, stop)
+	go e.wait(cmd, session, track, idx, stderr)
+	return nil
+}
+
+func (e *Engine) poll(session uint64, socket string, stop chan struct{}) {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.refresh(session, socket)
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (e *Engine) refresh(session uint64, socket string) {
+	pos, posOK := mpvProperty(socket, "time-pos")
+	dur, durOK := mpvProperty(socket, "duration")
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.session != session || !e.playing {
+		return
+	}
+	if posOK {
+		e.position = pos
+	}
+	if durOK && dur > 0 {
+		e.duration = dur
+	}
+	e.emitLocked(Event{Type: EventState, State: e.stateLocked(), Index: e.current})
+}
+
+func (e *Engine) wait(cmd *exec.Cmd, session uint64, track Track, idx int, stderr *bytes.Buffer) {
+	err := cmd.Wait()
+
+	ended := Event{Type: EventEnded, Session: session, Track: &track, Index: idx, Finished: err == nil}
+
+	e.mu.Lock()
+	if e.session != session || e.cmd != cmd {
+		ended.Finished = false
+		ended.State = e.stateLocked()
+		e.emitLocked(ended)
+		e.mu.Unlock()
+		return
+	}
+
+	if err != nil {
+		e.killLocked()
+		e.playing = false
+		e.paused = false
+		ended.Error = err.Error()
+		ended.Blocked = strings.Contains(stderr.String(), "403")
+		ended.State = e.stateLocked()
+		e.emitLocked(ended)
+		e.mu.Unlock()
+		return
+	}
+
+	next, ok := e.autoNextLocked(idx)
+	if !ok {
+		e.killLocked()
+		e.playing = false
+		e.paused = false
+		ended.State = e.stateLocked()
+		e.emitLocked(ended)
+		e.mu.Unlock()
+		return
+	}
+	nextTrack := e.playlist[next]
+	ended.State = e.stateLocked()
+	e.emitLocked(ended)
+	e.mu.Unlock()
+
+	_ = e.start(nextTrack, next)
+}
+
+func (e *Engine) autoNextLocked(idx int) (int, bool) {
+	if idx < 0 || len(e.playlist) == 0 {
+		return 0, false
+	}
+
+	switch e.mode {
+	case ModeRepeatOne:
+		if idx < len(e.playlist) {
+			return idx, true
+		}
+	case ModeShuffle:
+		if next, ok := e.shuffle.Next(); ok && next < len(e.playlist) {
+			return next, true
+		}
+	default:
+		next := idx + 1
+		if next < len(e.playlist) {
+			return next, true
+		}
+		if e.mode == ModeRepeatAll {
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+func (e *Engine) TogglePause() error {
+	e.mu.Lock()
+	playing, socket := e.playing, e.socket
+	e.mu.Unlock()
+
+	if !playing || socket == "" {
+		return ErrNothingPlaying
+	}
+	if err := mpvCommand(socket, `["cycle", "pause"]`); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	if e.socket == socket {
+		e.paused = !e.paused
+	}
+	e.emitLocked(Event{Type: EventState, State: e.stateLocked(), Index: e.current})
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *Engine) Stop() error {
+	e.mu.Lock()
+	e.killLocked()
+	e.playing = false
+	e.paused = false
+	e.current = -1
+	e.position = 0
+	e.duration = 0
+	e.emitLocked(Event{Type: EventState, State: e.stateLocked(), Index: e.current})
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *Engine) Close() {
+	e.mu.Lock()
+	e.killLocked()
+	e.playing = false
+	for id, q := range e.subs {
+		delete(e.subs, id)
+		q.Close()
+	}
+	e.mu.Unlock()
+}
+
+func (e *Engine) Next() error {
+	e.mu.Lock()
+	if len(e.playlist) == 0 {
+		e.mu.Unlock()
+		return ErrPlaylistEmpty
+	}
+	if !e.playing {
+		e.mu.Unlock()
+		return ErrNothingPlaying
+	}
+
+	next := 0
+	switch {
+	case e.current < 0:
+	case e.mode == ModeShuffle:
+		n, ok := e.shuffle.Next()
+		if !ok || n >= len(e.playlist) {
+			e.mu.Unlock()
+			return ErrLastTrack
+		}
+		next = n
+	default:
+		next = e.current + 1
+		if next >= len(e.playlist) {
+			if e.mode != ModeRepeatAll {
+				e.mu.Unlock()
+				return ErrLastTrack
+			}
+			next = 0
+		}
+	}
+	track := e.playlist[next]
+	e.mu.Unlock()
+
+	return e.start(track, next)
+}
+
+func (e *Engine) Previous() error {
+	e.mu.Lock()
+	if len(e.playlist) == 0 {
+		e.mu.Unlock()
+		return ErrPlaylistEmpty
+	}
+	if !e.playing {
+		e.mu.Unlock()
+		return ErrNothingPlaying
+	}
+
+	prev := len(e.playlist) - 1
+	switch {
+	case e.current < 0:
+	case e.mode == ModeShuffle:
+		p, ok := e.shuffle.Previous()
+		if !ok || p >= len(e.playlist) {
+			e.mu.Unlock()
+			return ErrFirstTrack
+		}
+		prev = p
+	default:
+		prev = e.current - 1
+		if prev < 0 {
+			if e.mode != ModeRepeatAll {
+				e.mu.Unlock()
+				return ErrFirstTrack
+			}
+			prev = len(e.playlist) - 1
+		}
+	}
+	track := e.playlist[prev]
+	e.mu.Unlock()
+
+	return e.start(track, prev)
+}
+
+func (e *Engine) Seek(seconds float64, absolute bool) error {
+	e.mu.Lock()
+	playing, socket, session := e.playing, e.socket, e.session
+	e.mu.Unlock()
+
+	if !playing || socket == "" {
+		return ErrNothingPlaying
+	}
+
+	mode := "relative"
+	if absolute {
+		mode = "absolute"
+	}
+	if err := mpvCommand(socket, fmt.Sprintf(`["seek", %g, "%s"]`, seconds, mode)); err != nil {
+		return err
+	}
+
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		e.refresh(session, socket)
+	}()
+	return nil
+}
+
+func (e *Engine) SetVolume(volume int) error {
+	volume = min(max(volume, 0), MaxVolume)
+
+	e.mu.Lock()
+	e.volume = volume
+	playing, socket := e.playing, e.socket
+	e.mu.Unlock()
+
+	var err error
+	if playing && socket != "" {
+		err = mpvCommand(socket, fmt.Sprintf(`["set_property", "volume", %d]`, volume))
+	}
+	e.emitState()
+	return err
+}
+
+func (e *Engine) SetPlaylist(tracks []Track, current int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if current >= len(tracks) {
+		current = -1
+	}
+	e.replacePlaylistLocked(tracks, current)
+	return nil
+}
+
+func (e *Engine) EditPlaylist(base uint64, tracks []Track) (uint64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if base != e.version {
+		return e.version, ErrPlaylistChanged
+	}
+	e.replacePlaylistLocked(tracks, -1)
+	return e.version, nil
+}
+
+func (e *Engine) replacePlaylistLocked(tracks []Track, current int) {
+	if e.current >= 0 {
+		current = remapIndex(e.playlist, tracks, e.current)
+	}
+
+	order, ok := matchOrder(e.playlist, tracks)
+	e.playlist = append([]Track(nil), tracks...)
+	e.current = current
+	e.version++
+	if ok {
+		e.shuffle.Remap(order)
+		e.shuffle.Grow(len(e.playlist))
+	} else {
+		e.shuffle.Reset(len(e.playlist), e.current)
+	}
+	e.emitPlaylistLocked()
+}
+
+func remapIndex(old, tracks []Track, idx int) int {
+	if idx >= len(old) {
+		return -1
+	}
+	url := old[idx].URL
+	skip := 0
+	for _, t := range old[:idx] {
+		if t.URL == url {
+			skip++
+		}
+	}
+	for i, t := range tracks {
+		if t.URL != url {
+			continue
+		}
+		if skip == 0 {
+			return i
+		}
+		skip--
+	}
+	return -1
+}
+
+func matchOrder(old, tracks []Track) ([]int, bool) {
+	positions := map[string][]int{}
+	for i, t := range old {
+		positions[t.URL] = append(positions[t.URL], i)
+	}
+
+	order := make([]int, 0, len(tracks))
+	for i, t := range tracks {
+		idxs := positions[t.URL]
+		if len(idxs) == 0 {
+			for _, rest := range tracks[i:] {
+				if len(positions[rest.URL]) > 0 {
+					return nil, false
+				}
+			}
+			return order, true
+		}
+		order = append(order, idxs[0])
+		positions[t.URL] = idxs[1:]
+	}
+	return order, true
+}
+
+func (e *Engine) AddTracks(tracks []Track) error {
+	if len(tracks) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	e.playlist = append(e.playlist, tracks...)
+	e.version++
+	e.shuffle.Grow(len(e.playlist))
+	e.emitPlaylistLocked()
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *Engine) SetPlaylistMode(mode PlaylistMode) error {
+	e.mu.Lock()
+	if mode == ModeShuffle && e.mode != ModeShuffle {
+		e.shuffle.Reset(len(e.playlist), e.current)
+	}
+	e.mode = mode
+	e.emitLocked(Event{Type: EventState, State: e.stateLocked(), Index: e.current})
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *Engine) SetPlayMode(mode PlayMode) error {
+	e.mu.Lock()
+	e.playMode = mode
+	e.emitLocked(Event{Type: EventState, State: e.stateLocked(), Index: e.current})
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *Engine) SetVideo(quality, codec string) error {
+	e.mu.Lock()
+	e.quality = quality
+	e.codec = codec
+	e.emitLocked(Event{Type: EventState, State: e.stateLocked(), Index: e.current})
+	e.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+)
+
+func tracks(urls ...string) []Track {
+	out := make([]Track, len(urls))
+	for i, u := range urls {
+		out[i] = Track{Title: u, URL: u}
+	}
+	return out
+}
+
+func TestSetPlaylistKeepsEngineCurrent(t *testing.T) {
+	e := New(Options{})
+	defer e.Close()
+	if err := e.SetPlaylist(tracks("a", "b", "c"), 0); err != nil {
+		t.Fatal(err)
+	}
+	e.current = 2
+
+	if err := e.SetPlaylist(tracks("c", "a", "b"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := e.State(); st.Index != 0 {
+		t.Fatalf("current = %d, want 0 where the playing track moved", st.Index)
+	}
+
+	if err := e.SetPlaylist(tracks("a", "b"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := e.State(); st.Index != -1 {
+		t.Fatalf("current = %d, want -1 once the playing track is removed", st.Index)
+	}
+
+	if err := e.SetPlaylist(tracks("x", "y"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := e.State(); st.Index != 1 {
+		t.Fatalf("current = %d, want the caller's index with nothing current", st.Index)
+	}
+}
+
+func TestEditPlaylistRejectsStaleBase(t *testing.T) {
+	e := New(Options{})
+	defer e.Close()
+	if err := e.SetPlaylist(tracks("a", "b", "c"), 0); err != nil {
+		t.Fatal(err)
+	}
+	st, _ := e.State()
+	base := st.Version
+
+	if err := e.AddTracks(tracks("d")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.EditPlaylist(base, tracks("c", "b", "a")); !errors.Is(err, ErrPlaylistChanged) {
+		t.Fatalf("EditPlaylist on a stale base = %v, want ErrPlaylistChanged", err)
+	}
+	if got, _ := e.Playlist(); len(got) != 4 || got[3].URL != "d" {
+		t.Fatalf("playlist = %v, want the added track kept", got)
+	}
+
+	st, _ = e.State()
+	e.current = 1
+	version, err := e.EditPlaylist(st.Version, tracks("d", "c", "b", "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, _ = e.State()
+	if version != st.Version || version <= base {
+		t.Fatalf("version = %d, state version = %d", version, st.Version)
+	}
+	if st.Index != 2 {
+		t.Fatalf("current = %d, want 2 where the playing track moved", st.Index)
+	}
+}
+
+func TestRemapIndexDuplicates(t *testing.T) {
+	old := tracks("a", "b", "a", "c")
+	tests := []struct {
+		tracks []Track
+		idx    int
+		want   int
+	}{
+		{tracks("c", "a", "b", "a"), 2, 3},
+		{tracks("c", "a", "b", "a"), 0, 1},
+		{tracks("b", "a", "c"), 2, -1},
+		{tracks("a", "b", "a", "c"), 4, -1},
+	}
+	for _, tt := range tests {
+		if got := remapIndex(old, tt.tracks, tt.idx); got != tt.want {
+			t.Errorf("remapIndex(%d into %v) = %d, want %d", tt.idx, tt.tracks, got, tt.want)
+		}
+	}
+}
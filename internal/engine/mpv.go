@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+func BuildYtdlFormat(quality, codec string) string {
+	var codecFilter string
+	switch codec {
+	case "vp9":
+		codecFilter = "[vcodec^=vp9]"
+	case "av1":
+		codecFilter = "[vcodec^=av01]"
+	}
+
+	if quality == "tct" {
+		return "bestvideo[height<=360]+bestaudio/best[height<=360]"
+	}
+
+	if quality == "best" || quality == "" {
+		if codecFilter == "" {
+			return "bestvideo+bestaudio/best"
+		}
+		return fmt.Sprintf("bestvideo%s+bestaudio/bestvideo+bestaudio/best", codecFilter)
+	}
+
+	if codecFilter == "" {
+		return fmt.Sprintf("bestvideo[height<=%s]+bestaudio/best[height<=%s]", quality, quality)
+	}
+
+	return fmt.Sprintf(
+		"bestvideo%s[height<=%s]+bestaudio/bestvideo[height<=%s]+bestaudio/best[height<=%s]",
+		codecFilter, quality, quality, quality,
+	)
+}
+
+func TerminalVideoArgs(target string, local bool) []string {
+	args := []string{
+		"--vo=tct",
+		"--vo-tct-algo=half-blocks",
+		"--vo-tct-256=yes",
+		"--really-quiet",
+	}
+	if !local {
+		args = append(args,
+			"--script-opts=ytdl_hook-ytdl_path=yt-dlp",
+			"--ytdl-format="+BuildYtdlFormat("tct", ""),
+		)
+	}
+	return append(args, target)
+}
+
+func mpvArgs(track Track, target string, local bool, socket string, volume int, mode PlayMode, quality, codec string) []string {
+	args := []string{
+		"--no-terminal",
+		fmt.Sprintf("--title=%s", track.Title),
+		fmt.Sprintf("--input-ipc-server=%s", socket),
+		fmt.Sprintf("--volume=%d", volume),
+	}
+	if !local {
+		args = append(args, "--script-opts=ytdl_hook-ytdl_path=yt-dlp")
+	}
+
+	switch {
+	case mode == ModeAudio && local:
+		args = append(args, "--no-video")
+	case mode == ModeAudio:
+		args = append(args, "--no-video", "--ytdl-format=bestaudio")
+	case !local:
+		args = append(args, "--ytdl-format="+BuildYtdlFormat(quality, codec))
+	}
+
+	return append(args, target)
+}
+
+func mpvCommand(socket, command string) error {
+	cmd := exec.Command("sh", "-c", fmt.Sprintf(`echo '{ "command": %s }' | socat - "%s" 2>&1`, command, socket))
+	output, err := cmd.CombinedOutput()
+	if err != nil && strings.Contains(string(output), "not found") {
+		return ErrSocatMissing
+	}
+	return err
+}
+
+func mpvProperty(socket, name string) (float64, bool) {
+	cmd := exec.Command("sh", "-c", fmt.Sprintf("echo '{ \"command\": [\"get_property\", \"%s\"] }' | socat - UNIX-CONNECT:%s 2>/dev/null | grep -o '\"data\":[0-9.]*' | cut -d: -f2", name, socket))
+	out, _ := cmd.Output()
+	if len(out) == 0 {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	return v, err == nil
+}
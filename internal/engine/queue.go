@@ -0,0 +1,102 @@
+package engine
+
+import "sync"
+
+const eventQueueLimit = 1024
+
+type EventQueue struct {
+	mu      sync.Mutex
+	pending []Event
+	closed  bool
+	wake    chan struct{}
+	stop    chan struct{}
+	stopped bool
+	out     chan Event
+}
+
+func NewEventQueue() *EventQueue {
+	q := &EventQueue{
+		wake: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+		out:  make(chan Event),
+	}
+	go q.run()
+	return q
+}
+
+func (q *EventQueue) C() <-chan Event {
+	return q.out
+}
+
+func (q *EventQueue) Push(ev Event) bool {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return false
+	}
+	if n := len(q.pending); ev.Type == EventState && n > 0 && q.pending[n-1].Type == EventState {
+		q.pending[n-1] = ev
+	} else if n >= eventQueueLimit {
+		q.mu.Unlock()
+		q.Stop()
+		return false
+	} else {
+		q.pending = append(q.pending, ev)
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+func (q *EventQueue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *EventQueue) Stop() {
+	q.mu.Lock()
+	q.closed = true
+	if !q.stopped {
+		q.stopped = true
+		close(q.stop)
+	}
+	q.mu.Unlock()
+}
+
+func (q *EventQueue) run() {
+	defer close(q.out)
+	for {
+		q.mu.Lock()
+		if len(q.pending) == 0 {
+			closed := q.closed
+			q.mu.Unlock()
+			if closed {
+				return
+			}
+			select {
+			case <-q.wake:
+				continue
+			case <-q.stop:
+				return
+			}
+		}
+		ev := q.pending[0]
+		q.pending = q.pending[1:]
+		q.mu.Unlock()
+
+		select {
+		case q.out <- ev:
+		case <-q.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func collect(t *testing.T, q *EventQueue) []Event {
+	t.Helper()
+	var got []Event
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case ev, ok := <-q.C():
+			if !ok {
+				return got
+			}
+			got = append(got, ev)
+		case <-timeout:
+			t.Fatalf("queue not closed; received %d events", len(got))
+		}
+	}
+}
+
+func TestEventQueueCoalescesState(t *testing.T) {
+	q := NewEventQueue()
+	q.Push(Event{Type: EventState, Index: 1})
+	q.Push(Event{Type: EventState, Index: 2})
+	q.Push(Event{Type: EventStarted, Index: 3})
+	q.Push(Event{Type: EventState, Index: 4})
+	q.Push(Event{Type: EventState, Index: 5})
+	q.Push(Event{Type: EventEnded, Index: 6})
+	q.Close()
+
+	got := collect(t, q)
+	var track []int
+	states := 0
+	for _, ev := range got {
+		if ev.Type == EventState {
+			states++
+			continue
+		}
+		track = append(track, ev.Index)
+	}
+	if len(track) != 2 || track[0] != 3 || track[1] != 6 {
+		t.Fatalf("track events = %v, want [3 6]", track)
+	}
+	if states > 3 {
+		t.Fatalf("got %d state events, want them coalesced", states)
+	}
+	if last := got[len(got)-2]; last.Type != EventState || last.Index != 5 {
+		t.Fatalf("last state = %+v, want index 5", last)
+	}
+}
+
+func TestEventQueuePushNeverBlocks(t *testing.T) {
+	q := NewEventQueue()
+	defer q.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			q.Push(Event{Type: EventStarted, Index: i})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Push blocked without a reader")
+	}
+
+	for i := 0; i < 100; i++ {
+		if ev := <-q.C(); ev.Index != i {
+			t.Fatalf("event %d has index %d", i, ev.Index)
+		}
+	}
+}
+
+func TestEventQueueDropsStalledReader(t *testing.T) {
+	q := NewEventQueue()
+	for i := 0; i <= eventQueueLimit+1; i++ {
+		q.Push(Event{Type: EventStarted, Index: i})
+	}
+	if q.Push(Event{Type: EventStarted}) {
+		t.Fatal("Push succeeded on an overflowed queue")
+	}
+	collect(t, q)
+}
+
+func TestEventQueueStop(t *testing.T) {
+	q := NewEventQueue()
+	q.Push(Event{Type: EventStarted})
+	q.Stop()
+	collect(t, q)
+	if q.Push(Event{Type: EventStarted}) {
+		t.Fatal("Push succeeded after Stop")
+	}
+}
+
+func TestEngineSubscribeDeliversTrackEvents(t *testing.T) {
+	e := New(Options{})
+	events, cancel, err := e.Subscribe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	for i := 0; i < 2000; i++ {
+		e.emitState()
+	}
+	e.Notify(EventHistory)
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case ev := <-events:
+			if ev.Type == EventHistory {
+				return
+			}
+		case <-timeout:
+			t.Fatal("history event lost behind state events")
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+const shuffleHistoryLimit = 500
+
+type ShuffleBag struct {
+	pending []int
+	history []int
+	cursor  int
+	size    int
+}
+
+func NewShuffleBag() *ShuffleBag {
+	return &ShuffleBag{cursor: -1}
+}
+
+func (b *ShuffleBag) Reset(n, current int) {
+	b.size = n
+	b.history = nil
+	b.cursor = -1
+	if current >= 0 && current < n {
+		b.history = []int{current}
+		b.cursor = 0
+	}
+	b.refill()
+}
+
+func (b *ShuffleBag) refill() {
+	b.pending = b.pending[:0]
+	played := b.current()
+	for _, i := range rand.Perm(b.size) {
+		if i != played || b.size == 1 {
+			b.pending = append(b.pending, i)
+		}
+	}
+}
+
+func (b *ShuffleBag) current() int {
+	if b.cursor >= 0 && b.cursor < len(b.history) {
+		return b.history[b.cursor]
+	}
+	return -1
+}
+
+func (b *ShuffleBag) Next() (int, bool) {
+	if b.size == 0 {
+		return 0, false
+	}
+	if b.cursor < len(b.history)-1 {
+		b.cursor++
+		return b.history[b.cursor], true
+	}
+	if len(b.pending) == 0 {
+		b.refill()
+		if len(b.pending) == 0 {
+			return 0, false
+		}
+	}
+	next := b.pending[0]
+	b.pending = b.pending[1:]
+	b.push(next)
+	return next, true
+}
+
+func (b *ShuffleBag) Previous() (int, bool) {
+	if b.cursor <= 0 {
+		return 0, false
+	}
+	b.cursor--
+	return b.history[b.cursor], true
+}
+
+func (b *ShuffleBag) Played(idx int) {
+	if idx < 0 || idx >= b.size || idx == b.current() {
+		return
+	}
+	if i := slices.Index(b.pending, idx); i >= 0 {
+		b.pending = slices.Delete(b.pending, i, i+1)
+	}
+	b.history = b.history[:b.cursor+1]
+	b.push(idx)
+}
+
+func (b *ShuffleBag) push(idx int) {
+	b.history = append(b.history, idx)
+	if len(b.history) > shuffleHistoryLimit {
+		b.history = b.history[len(b.history)-shuffleHistoryLimit:]
+	}
+	b.cursor = len(b.history) - 1
+}
+
+func (b *ShuffleBag) Grow(n int) {
+	for i := b.size; i < n; i++ {
+		pos := rand.IntN(len(b.pending) + 1)
+		b.pending = slices.Insert(b.pending, pos, i)
+	}
+	b.size = max(b.size, n)
+}
+
+func (b *ShuffleBag) Remap(order []int) {
+	newIdx := make(map[int]int, len(order))
+	for i, old := range order {
+		newIdx[old] = i
+	}
+
+	pending := b.pending[:0]
+	for _, old := range b.pending {
+		if i, ok := newIdx[old]; ok {
+			pending = append(pending, i)
+		}
+	}
+	b.pending = pending
+
+	history := make([]int, 0, len(b.history))
+	cursor := -1
+	for pos, old := range b.history {
+		if i, ok := newIdx[old]; ok {
+			history = append(history, i)
+		}
+		if pos == b.cursor {
+			cursor = len(history) - 1
+		}
+	}
+	b.history = history
+	b.cursor = cursor
+	b.size = len(order)
+}
+
+func (b *ShuffleBag) Upcoming(k int) []int {
+	var next []int
+	if b.cursor+1 < len(b.history) {
+		next = append(next, b.history[b.cursor+1:]...)
+	}
+	next = append(next, b.pending...)
+	if len(next) > k {
+		next = next[:k]
+	}
+	return next
+}
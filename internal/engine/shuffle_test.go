@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func drain(t *testing.T, b *ShuffleBag, n int) []int {
+	t.Helper()
+	var got []int
+	for i := 0; i < n; i++ {
+		idx, ok := b.Next()
+		if !ok {
+			t.Fatalf("Next() returned false after %d tracks", i)
+		}
+		got = append(got, idx)
+	}
+	return got
+}
+
+func TestShuffleBagNoRepeatUntilExhausted(t *testing.T) {
+	b := NewShuffleBag()
+	b.Reset(10, 3)
+
+	got := drain(t, b, 9)
+	if slices.Contains(got, 3) {
+		t.Fatalf("current track replayed before the bag was exhausted: %v", got)
+	}
+	slices.Sort(got)
+	if want := []int{0, 1, 2, 4, 5, 6, 7, 8, 9}; !slices.Equal(got, want) {
+		t.Fatalf("first pass = %v, want every other track once", got)
+	}
+}
+
+func TestShuffleBagRefill(t *testing.T) {
+	b := NewShuffleBag()
+	b.Reset(5, -1)
+
+	first := drain(t, b, 5)
+	last := first[len(first)-1]
+	second := drain(t, b, 4)
+
+	if slices.Contains(second, last) {
+		t.Fatalf("refilled bag repeated the last track %d straight away: %v", last, second)
+	}
+	seen := map[int]bool{}
+	for _, idx := range second {
+		if seen[idx] {
+			t.Fatalf("refilled bag repeated %d: %v", idx, second)
+		}
+		seen[idx] = true
+	}
+}
+
+func TestShuffleBagSingleTrack(t *testing.T) {
+	b := NewShuffleBag()
+	b.Reset(1, 0)
+	for i := 0; i < 3; i++ {
+		if idx, ok := b.Next(); !ok || idx != 0 {
+			t.Fatalf("Next() = %d, %v; want 0, true", idx, ok)
+		}
+	}
+}
+
+func TestShuffleBagEmpty(t *testing.T) {
+	b := NewShuffleBag()
+	b.Reset(0, -1)
+	if _, ok := b.Next(); ok {
+		t.Fatal("Next() on an empty bag returned true")
+	}
+}
+
+func TestShuffleBagPreviousReplaysHistory(t *testing.T) {
+	b := NewShuffleBag()
+	b.Reset(6, 0)
+	played := append([]int{0}, drain(t, b, 3)...)
+
+	for i := len(played) - 2; i >= 0; i-- {
+		idx, ok := b.Previous()
+		if !ok || idx != played[i] {
+			t.Fatalf("Previous() = %d, %v; want %d", idx, ok, played[i])
+		}
+	}
+	if _, ok := b.Previous(); ok {
+		t.Fatal("Previous() past the start of history returned true")
+	}
+	for _, want := range played[1:] {
+		if idx, _ := b.Next(); idx != want {
+			t.Fatalf("Next() after Previous() = %d, want %d", idx, want)
+		}
+	}
+}
+
+func TestShuffleBagPlayedRemovesFromPending(t *testing.T) {
+	b := NewShuffleBag()
+	b.Reset(5, 0)
+	b.Played(2)
+
+	got := drain(t, b, 3)
+	if slices.Contains(got, 2) {
+		t.Fatalf("manually played track came up again: %v", got)
+	}
+}
+
+func TestShuffleBagGrow(t *testing.T) {
+	b := NewShuffleBag()
+	b.Reset(3, 0)
+	b.Grow(6)
+
+	got := drain(t, b, 5)
+	slices.Sort(got)
+	if want := []int{1, 2, 3, 4, 5}; !slices.Equal(got, want) {
+		t.Fatalf("after Grow(6) got %v, want %v", got, want)
+	}
+}
+
+func TestShuffleBagRemap(t *testing.T) {
+	b := NewShuffleBag()
+	b.Reset(5, 1)
+	b.Played(4)
+
+	b.Remap([]int{0, 1, 2, 4})
+
+	if b.size != 4 {
+		t.Fatalf("size = %d, want 4", b.size)
+	}
+	if cur := b.current(); cur != 3 {
+		t.Fatalf("current = %d, want 3 (old index 4)", cur)
+	}
+	if idx, ok := b.Previous(); !ok || idx != 1 {
+		t.Fatalf("Previous() = %d, %v; want 1, true", idx, ok)
+	}
+	b.Next()
+
+	got := drain(t, b, 2)
+	slices.Sort(got)
+	if want := []int{0, 2}; !slices.Equal(got, want) {
+		t.Fatalf("pending after Remap = %v, want %v", got, want)
+	}
+}
+
+func TestShuffleBagUpcoming(t *testing.T) {
+	b := NewShuffleBag()
+	b.Reset(8, 0)
+	want := b.Upcoming(3)
+	if len(want) != 3 {
+		t.Fatalf("Upcoming(3) returned %d items", len(want))
+	}
+	if got := drain(t, b, 3); !slices.Equal(got, want) {
+		t.Fatalf("Next() order %v differs from Upcoming %v", got, want)
+	}
+}
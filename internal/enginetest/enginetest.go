@@ -0,0 +1,91 @@
+// Package enginetest fakes the engine controller and waits on channels in tests
+package enginetest
+
+import (
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/engine"
+)
+
+const (
+	receiveTimeout = 2 * time.Second
+	quietPeriod    = 150 * time.Millisecond
+)
+
+type Controller struct {
+	engine.Controller
+	Initial engine.State
+	Events  chan engine.Event
+
+	once sync.Once
+}
+
+func NewController() *Controller {
+	return &Controller{Events: make(chan engine.Event, 16)}
+}
+
+func (c *Controller) State() (engine.State, error) {
+	return c.Initial, nil
+}
+
+func (c *Controller) Subscribe() (<-chan engine.Event, func(), error) {
+	return c.Events, c.Close, nil
+}
+
+func (c *Controller) Send(ev engine.Event) {
+	c.Events <- ev
+}
+
+func (c *Controller) Close() {
+	c.once.Do(func() { close(c.Events) })
+}
+
+func Track(title, author string) engine.Track {
+	return engine.Track{
+		Title:     title,
+		Author:    author,
+		URL:       "https://www.youtube.com/watch?v=" + url.QueryEscape(title),
+		Thumbnail: "https://i.ytimg.com/vi/" + url.PathEscape(title) + "/hqdefault.jpg",
+	}
+}
+
+func Playing(track engine.Track, paused bool) engine.Event {
+	return engine.Event{Type: engine.EventState, State: engine.State{
+		Playing:  true,
+		Paused:   paused,
+		Track:    track,
+		Position: 10,
+		Duration: 200,
+	}}
+}
+
+func Started(track engine.Track) engine.Event {
+	ev := Playing(track, false)
+	ev.Type = engine.EventStarted
+	ev.Track = &track
+	return ev
+}
+
+func Receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(receiveTimeout):
+		t.Fatalf("nothing received in %s", receiveTimeout)
+	}
+	var zero T
+	return zero
+}
+
+func Quiet[T any](t *testing.T, ch <-chan T) {
+	t.Helper()
+	select {
+	case v := <-ch:
+		t.Fatalf("unexpected %+v", v)
+	case <-time.After(quietPeriod):
+	}
+}
@@ -0,0 +1,189 @@
+// Package history
+package history
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketHistory = []byte("history")
+	bucketMeta    = []byte("meta")
+
+	keyMigrated = []byte("history_migrated")
+)
+
+type Entry struct {
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	URL       string    `json:"url"`
+	Thumbnail string    `json:"thumbnail,omitempty"`
+	Duration  string    `json:"duration,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	Listened  float64   `json:"listened"`
+	Completed bool      `json:"completed"`
+}
+
+type Store struct {
+	db *bolt.DB
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func Open(db *bolt.DB, legacyPath string) (*Store, error) {
+	s := &Store{db: db}
+	if db == nil {
+		return s, nil
+	}
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(bucketHistory)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(_, v []byte) error {
+			var e Entry
+			if json.Unmarshal(v, &e) == nil {
+				s.entries = append(s.entries, e)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return s, err
+	}
+
+	return s, s.migrate(legacyPath)
+}
+
+func (s *Store) migrate(path string) error {
+	if path == "" {
+		return nil
+	}
+	if s.migrated() {
+		if _, err := os.Stat(path); err == nil {
+			return os.Rename(path, path+".migrated")
+		}
+		return nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var legacy []Entry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var e Entry
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			legacy = append(legacy, e)
+		}
+	}
+	_ = f.Close()
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		if err := putEntries(tx.Bucket(bucketHistory), legacy); err != nil {
+			return err
+		}
+		meta, err := tx.CreateBucketIfNotExists(bucketMeta)
+		if err != nil {
+			return err
+		}
+		return meta.Put(keyMigrated, []byte(time.Now().Format(time.RFC3339)))
+	})
+	if err != nil {
+		return err
+	}
+	s.entries = append(legacy, s.entries...)
+	return os.Rename(path, path+".migrated")
+}
+
+func (s *Store) migrated() bool {
+	done := false
+	_ = s.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(bucketMeta); b != nil {
+			done = b.Get(keyMigrated) != nil
+		}
+		return nil
+	})
+	return done
+}
+
+func (s *Store) put(entries ...Entry) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putEntries(tx.Bucket(bucketHistory), entries)
+	})
+}
+
+func putEntries(b *bolt.Bucket, entries []Entry) error {
+	for _, e := range entries {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := b.Put(binary.BigEndian.AppendUint64(nil, seq), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) Append(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.db != nil {
+		if err := s.put(e); err != nil {
+			return err
+		}
+	}
+	s.entries = append(s.entries, e)
+	return nil
+}
+
+func (s *Store) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make([]Entry, len(s.entries))
+	copy(entries, s.entries)
+	return entries
+}
+
+func (s *Store) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.db != nil {
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			if err := tx.DeleteBucket(bucketHistory); err != nil {
+				return err
+			}
+			_, err := tx.CreateBucket(bucketHistory)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	s.entries = nil
+	return nil
+}
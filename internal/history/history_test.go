@@ -0,0 +1,86 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestMigrateImportsLegacyOnce(t *testing.T) {
+	dir := t.TempDir()
+	db, err := bolt.Open(filepath.Join(dir, "youtui.db"), 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	legacy := filepath.Join(dir, "history.jsonl")
+	lines := `{"title":"One","url":"https://youtu.be/1","started_at":"2025-01-01T10:00:00Z"}
+{"title":"Two","url":"https://youtu.be/2","started_at":"2025-01-01T11:00:00Z"}
+`
+	if err := os.WriteFile(legacy, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	blocker := legacy + ".migrated"
+	if err := os.MkdirAll(filepath.Join(blocker, "keep"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(db, legacy)
+	if err == nil {
+		t.Fatal("rename onto a directory succeeded")
+	}
+	if n := len(s.Entries()); n != 2 {
+		t.Fatalf("first open imported %d entries, want 2", n)
+	}
+
+	s, err = Open(db, legacy)
+	if err == nil {
+		t.Fatal("rename onto a directory succeeded")
+	}
+	if n := len(s.Entries()); n != 2 {
+		t.Fatalf("second open has %d entries, want 2", n)
+	}
+
+	if err := os.RemoveAll(blocker); err != nil {
+		t.Fatal(err)
+	}
+	s, err = Open(db, legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.Entries()); n != 2 {
+		t.Fatalf("third open has %d entries, want 2", n)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Fatalf("legacy file still present: %v", err)
+	}
+}
+
+func TestAppendAndClear(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "youtui.db"), 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s, err := Open(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Append(Entry{Title: "One", StartedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = Open(db, ""); err != nil || len(s.Entries()) != 1 {
+		t.Fatalf("reopened store has %d entries, err %v", len(s.Entries()), err)
+	}
+	if err := s.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = Open(db, ""); err != nil || len(s.Entries()) != 0 {
+		t.Fatalf("cleared store has %d entries, err %v", len(s.Entries()), err)
+	}
+}
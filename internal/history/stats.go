@@ -0,0 +1,197 @@
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type Period string
+
+const (
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+	PeriodYear  Period = "year"
+	PeriodAll   Period = "all"
+)
+
+func (p Period) Since(now time.Time) time.Time {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch p {
+	case PeriodWeek:
+		return day.AddDate(0, 0, -6)
+	case PeriodMonth:
+		return day.AddDate(0, -1, 1)
+	case PeriodYear:
+		return day.AddDate(-1, 0, 1)
+	}
+	return time.Time{}
+}
+
+type Count struct {
+	Label   string  `json:"label"`
+	Author  string  `json:"author,omitempty"`
+	URL     string  `json:"url,omitempty"`
+	Plays   int     `json:"plays"`
+	Seconds float64 `json:"seconds"`
+}
+
+type Stats struct {
+	Period        Period      `json:"period"`
+	From          time.Time   `json:"from,omitempty"`
+	To            time.Time   `json:"to"`
+	Plays         int         `json:"plays"`
+	Completed     int         `json:"completed"`
+	Seconds       float64     `json:"seconds"`
+	ActiveDays    int         `json:"active_days"`
+	CurrentStreak int         `json:"current_streak"`
+	LongestStreak int         `json:"longest_streak"`
+	TopTracks     []Count     `json:"top_tracks"`
+	TopChannels   []Count     `json:"top_channels"`
+	ByHour        [24]float64 `json:"by_hour"`
+	ByWeekday     [7]float64  `json:"by_weekday"`
+}
+
+func Compute(entries []Entry, period Period, now time.Time, top int) Stats {
+	st := Stats{Period: period, From: period.Since(now), To: now}
+
+	tracks := map[string]*Count{}
+	channels := map[string]*Count{}
+	days := map[time.Time]bool{}
+
+	for _, e := range entries {
+		started := e.StartedAt.In(now.Location())
+		if started.Before(st.From) || started.After(now) {
+			continue
+		}
+
+		st.Plays++
+		st.Seconds += e.Listened
+		if e.Completed {
+			st.Completed++
+		}
+		st.ByHour[started.Hour()] += e.Listened
+		st.ByWeekday[started.Weekday()] += e.Listened
+		days[time.Date(started.Year(), started.Month(), started.Day(), 0, 0, 0, 0, now.Location())] = true
+
+		key := e.URL
+		if key == "" {
+			key = e.Title
+		}
+		t := tracks[key]
+		if t == nil {
+			t = &Count{Label: e.Title, Author: e.Author, URL: e.URL}
+			tracks[key] = t
+		}
+		t.Plays++
+		t.Seconds += e.Listened
+
+		if e.Author != "" {
+			c := channels[e.Author]
+			if c == nil {
+				c = &Count{Label: e.Author}
+				channels[e.Author] = c
+			}
+			c.Plays++
+			c.Seconds += e.Listened
+		}
+	}
+
+	st.TopTracks = topCounts(tracks, top)
+	st.TopChannels = topCounts(channels, top)
+	st.ActiveDays = len(days)
+	st.CurrentStreak, st.LongestStreak = streaks(days, now)
+	return st
+}
+
+func topCounts(m map[string]*Count, n int) []Count {
+	list := make([]Count, 0, len(m))
+	for _, c := range m {
+		list = append(list, *c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Plays != list[j].Plays {
+			return list[i].Plays > list[j].Plays
+		}
+		if list[i].Seconds != list[j].Seconds {
+			return list[i].Seconds > list[j].Seconds
+		}
+		return list[i].Label < list[j].Label
+	})
+	if n > 0 && len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+func streaks(days map[time.Time]bool, now time.Time) (current, longest int) {
+	if len(days) == 0 {
+		return 0, 0
+	}
+
+	sorted := make([]time.Time, 0, len(days))
+	for d := range days {
+		sorted = append(sorted, d)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	run := 0
+	for i, d := range sorted {
+		if i > 0 && sorted[i-1].AddDate(0, 0, 1).Equal(d) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if !days[day] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[day] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+	return current, longest
+}
+
+func (s Stats) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+func (s Stats) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	num := func(f float64) string { return strconv.FormatFloat(f, 'f', 0, 64) }
+
+	rows := [][]string{
+		{"section", "label", "author", "plays", "seconds"},
+		{"total", string(s.Period), "", strconv.Itoa(s.Plays), num(s.Seconds)},
+		{"completed", "", "", strconv.Itoa(s.Completed), ""},
+		{"active_days", "", "", strconv.Itoa(s.ActiveDays), ""},
+		{"current_streak", "", "", strconv.Itoa(s.CurrentStreak), ""},
+		{"longest_streak", "", "", strconv.Itoa(s.LongestStreak), ""},
+	}
+	for _, c := range s.TopTracks {
+		rows = append(rows, []string{"track", c.Label, c.Author, strconv.Itoa(c.Plays), num(c.Seconds)})
+	}
+	for _, c := range s.TopChannels {
+		rows = append(rows, []string{"channel", c.Label, "", strconv.Itoa(c.Plays), num(c.Seconds)})
+	}
+	for h, secs := range s.ByHour {
+		rows = append(rows, []string{"hour", strconv.Itoa(h), "", "", num(secs)})
+	}
+	for d, secs := range s.ByWeekday {
+		rows = append(rows, []string{"weekday", time.Weekday(d).String(), "", "", num(secs)})
+	}
+
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
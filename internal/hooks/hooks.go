@@ -0,0 +1,223 @@
+// Package hooks runs user scripts when the player changes state
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/engine"
+)
+
+const (
+	Start       = "start"
+	End         = "end"
+	Pause       = "pause"
+	Resume      = "resume"
+	PlaylistEnd = "playlist_end"
+	Error       = "error"
+)
+
+const (
+	DefaultTimeout = 10 * time.Second
+	queueSize      = 64
+)
+
+var (
+	errQueueFull = errors.New("too many pending hooks, event dropped")
+	errTimeout   = errors.New("hook timed out")
+)
+
+type Options struct {
+	Commands map[string]string
+	Timeout  time.Duration
+	OnError  func(event string, err error)
+}
+
+type Payload struct {
+	Event    string       `json:"event"`
+	Track    engine.Track `json:"track"`
+	Index    int          `json:"index"`
+	Position float64      `json:"position"`
+	Duration float64      `json:"duration"`
+	Finished bool         `json:"finished,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
+
+type Runner struct {
+	opts   Options
+	cancel func()
+	ctx    context.Context
+	stop   context.CancelFunc
+	queue  chan Payload
+	done   chan struct{}
+}
+
+func Attach(ctrl engine.Controller, opts Options) (*Runner, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	events, cancel, err := ctrl.Subscribe()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	r := &Runner{
+		opts:   opts,
+		cancel: cancel,
+		ctx:    ctx,
+		stop:   stop,
+		queue:  make(chan Payload, queueSize),
+		done:   make(chan struct{}),
+	}
+	initial, _ := ctrl.State()
+	go r.watch(events, initial)
+	go r.work()
+	return r, nil
+}
+
+func (r *Runner) Close() {
+	r.stop()
+	r.cancel()
+	<-r.done
+}
+
+func (r *Runner) watch(events <-chan engine.Event, last engine.State) {
+	defer close(r.queue)
+	for ev := range events {
+		for _, p := range translate(ev, last) {
+			r.enqueue(p)
+		}
+		last = ev.State
+	}
+}
+
+func translate(ev engine.Event, last engine.State) []Payload {
+	payload := func(event string, track engine.Track) Payload {
+		return Payload{
+			Event:    event,
+			Track:    track,
+			Index:    ev.Index,
+			Position: ev.State.Position,
+			Duration: ev.State.Duration,
+		}
+	}
+
+	switch ev.Type {
+	case engine.EventStarted:
+		if ev.Track != nil {
+			return []Payload{payload(Start, *ev.Track)}
+		}
+	case engine.EventState:
+		if !ev.State.Playing || !last.Playing || last.Session != ev.State.Session || last.Paused == ev.State.Paused {
+			return nil
+		}
+		if ev.State.Paused {
+			return []Payload{payload(Pause, ev.State.Track)}
+		}
+		return []Payload{payload(Resume, ev.State.Track)}
+	case engine.EventEnded:
+		if ev.Track == nil {
+			return nil
+		}
+		end := payload(End, *ev.Track)
+		end.Finished = ev.Finished
+		if last.Session == ev.Session {
+			end.Position, end.Duration = last.Position, last.Duration
+		}
+		out := []Payload{end}
+		switch {
+		case ev.Error != "":
+			failed := payload(Error, *ev.Track)
+			failed.Error = ev.Error
+			if ev.Blocked {
+				failed.Error = "blocked: " + ev.Error
+			}
+			out = append(out, failed)
+		case ev.Finished && ev.Index >= 0 && !ev.State.Playing:
+			out = append(out, payload(PlaylistEnd, *ev.Track))
+		}
+		return out
+	}
+	return nil
+}
+
+func (r *Runner) enqueue(p Payload) {
+	if r.opts.Commands[p.Event] == "" {
+		return
+	}
+	select {
+	case r.queue <- p:
+	default:
+		r.report(p.Event, errQueueFull)
+	}
+}
+
+func (r *Runner) work() {
+	defer close(r.done)
+	for p := range r.queue {
+		if r.ctx.Err() != nil {
+			continue
+		}
+		if err := r.run(p); err != nil {
+			r.report(p.Event, err)
+		}
+	}
+}
+
+func (r *Runner) report(event string, err error) {
+	if r.opts.OnError != nil {
+		r.opts.OnError(event, err)
+	}
+}
+
+func (r *Runner) run(p Payload) error {
+	stdin, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, r.opts.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", r.opts.Commands[p.Event])
+	cmd.Stdin = bytes.NewReader(append(stdin, '\n'))
+	cmd.Env = append(os.Environ(), env(p)...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return errTimeout
+	}
+	return err
+}
+
+func env(p Payload) []string {
+	finished := "0"
+	if p.Finished {
+		finished = "1"
+	}
+	return []string{
+		"YOUTUI_EVENT=" + p.Event,
+		"YOUTUI_TITLE=" + p.Track.Title,
+		"YOUTUI_AUTHOR=" + p.Track.Author,
+		"YOUTUI_URL=" + p.Track.URL,
+		"YOUTUI_THUMBNAIL=" + p.Track.Thumbnail,
+		"YOUTUI_INDEX=" + strconv.Itoa(p.Index),
+		"YOUTUI_POSITION=" + strconv.Itoa(int(p.Position)),
+		"YOUTUI_DURATION=" + strconv.Itoa(int(p.Duration)),
+		"YOUTUI_FINISHED=" + finished,
+		"YOUTUI_ERROR=" + p.Error,
+	}
+}
@@ -0,0 +1,68 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type Index struct {
+	mu    sync.RWMutex
+	paths map[string]string
+}
+
+func NewIndex() *Index {
+	return &Index{paths: map[string]string{}}
+}
+
+func (x *Index) Add(id, path string) {
+	if id == "" || path == "" {
+		return
+	}
+	x.mu.Lock()
+	x.paths[id] = path
+	x.mu.Unlock()
+}
+
+func (x *Index) AddEntries(entries []Entry) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, e := range entries {
+		if id := VideoIDFromPath(e.Path); id != "" {
+			x.paths[id] = e.Path
+		}
+	}
+}
+
+func (x *Index) Has(id string) bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	_, ok := x.paths[id]
+	return ok
+}
+
+func (x *Index) Lookup(id string) (string, bool) {
+	x.mu.RLock()
+	path, ok := x.paths[id]
+	x.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+	if _, err := os.Stat(path); err != nil {
+		x.mu.Lock()
+		delete(x.paths, id)
+		x.mu.Unlock()
+		return "", false
+	}
+	return path, true
+}
+
+func VideoIDFromPath(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	m := idSuffix.FindString(name)
+	if m == "" {
+		return ""
+	}
+	return strings.Trim(strings.TrimSpace(m), "[]")
+}
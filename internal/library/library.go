@@ -0,0 +1,224 @@
+// Package library
+package library
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/IvelOt/youtui-player/internal/atomicfile"
+)
+
+var mediaExts = map[string]bool{
+	".mp3":  true,
+	".m4a":  true,
+	".aac":  true,
+	".opus": true,
+	".ogg":  true,
+	".oga":  true,
+	".flac": true,
+	".wav":  true,
+	".wma":  true,
+	".mka":  true,
+	".webm": true,
+	".mp4":  true,
+	".mkv":  true,
+}
+
+type Entry struct {
+	Path     string  `json:"path"`
+	Title    string  `json:"title"`
+	Artist   string  `json:"artist"`
+	Album    string  `json:"album"`
+	Duration float64 `json:"duration"`
+	Cover    bool    `json:"cover"`
+	ModTime  int64   `json:"mod_time"`
+	Size     int64   `json:"size"`
+}
+
+type Library struct {
+	dirs      []string
+	cachePath string
+
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+func New(dirs []string, cachePath string) *Library {
+	l := &Library{
+		dirs:      dirs,
+		cachePath: cachePath,
+		entries:   map[string]Entry{},
+	}
+	l.load()
+	return l
+}
+
+func (l *Library) Dirs() []string {
+	return l.dirs
+}
+
+func (l *Library) Scan() ([]Entry, error) {
+	l.mu.Lock()
+	cached := l.entries
+	l.mu.Unlock()
+
+	var files []string
+	stats := map[string]fs.FileInfo{}
+	for _, dir := range l.dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == dir {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				if strings.HasPrefix(d.Name(), ".") && path != dir {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !mediaExts[strings.ToLower(filepath.Ext(path))] {
+				return nil
+			}
+			if _, seen := stats[path]; seen {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			stats[path] = info
+			files = append(files, path)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make(map[string]Entry, len(files))
+	var pending []string
+	for _, path := range files {
+		info := stats[path]
+		if e, ok := cached[path]; ok && e.ModTime == info.ModTime().Unix() && e.Size == info.Size() {
+			entries[path] = e
+			continue
+		}
+		pending = append(pending, path)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 4)
+	for _, path := range pending {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(path string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			e := probeEntry(path)
+			e.ModTime = stats[path].ModTime().Unix()
+			e.Size = stats[path].Size()
+
+			mu.Lock()
+			entries[path] = e
+			mu.Unlock()
+		}(path)
+	}
+	wg.Wait()
+
+	l.mu.Lock()
+	l.entries = entries
+	l.save()
+	l.mu.Unlock()
+
+	return l.Entries(), nil
+}
+
+func (l *Library) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	list := make([]Entry, 0, len(l.entries))
+	for _, e := range l.entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if !strings.EqualFold(a.Artist, b.Artist) {
+			return strings.ToLower(a.Artist) < strings.ToLower(b.Artist)
+		}
+		if !strings.EqualFold(a.Album, b.Album) {
+			return strings.ToLower(a.Album) < strings.ToLower(b.Album)
+		}
+		return a.Path < b.Path
+	})
+	return list
+}
+
+func (l *Library) Lookup(path string) (Entry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.entries[path]
+	return e, ok
+}
+
+func (l *Library) load() {
+	if l.cachePath == "" {
+		return
+	}
+	data, err := os.ReadFile(l.cachePath)
+	if err != nil {
+		return
+	}
+	var list []Entry
+	if err := json.Unmarshal(data, &list); err != nil {
+		return
+	}
+	for _, e := range list {
+		l.entries[e.Path] = e
+	}
+}
+
+func (l *Library) save() {
+	if l.cachePath == "" {
+		return
+	}
+	list := make([]Entry, 0, len(l.entries))
+	for _, e := range l.entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = atomicfile.WriteFile(l.cachePath, data, 0o644)
+}
+
+func FileURL(path string) string {
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+func PathFromURL(raw string) (string, bool) {
+	if !strings.HasPrefix(raw, "file://") {
+		return "", false
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Path == "" {
+		return "", false
+	}
+	return u.Path, true
+}
+
+func IsLocal(raw string) bool {
+	_, ok := PathFromURL(raw)
+	return ok
+}
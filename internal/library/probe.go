@@ -0,0 +1,109 @@
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var idSuffix = regexp.MustCompile(`\s*\[[A-Za-z0-9_-]{11}\]$`)
+
+type ffprobeOutput struct {
+	Format struct {
+		Duration string            `json:"duration"`
+		Tags     map[string]string `json:"tags"`
+	} `json:"format"`
+	Streams []struct {
+		CodecType   string            `json:"codec_type"`
+		Tags        map[string]string `json:"tags"`
+		Disposition struct {
+			AttachedPic int `json:"attached_pic"`
+		} `json:"disposition"`
+	} `json:"streams"`
+}
+
+func probeEntry(path string) Entry {
+	e := Entry{Path: path}
+
+	out, err := exec.Command("ffprobe",
+		"-v", "quiet",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		path,
+	).Output()
+
+	var probe ffprobeOutput
+	if err == nil && json.Unmarshal(out, &probe) == nil {
+		tags := map[string]string{}
+		for _, s := range probe.Streams {
+			for k, v := range s.Tags {
+				tags[strings.ToLower(k)] = v
+			}
+			if s.CodecType == "video" && s.Disposition.AttachedPic == 1 {
+				e.Cover = true
+			}
+		}
+		for k, v := range probe.Format.Tags {
+			tags[strings.ToLower(k)] = v
+		}
+
+		e.Title = strings.TrimSpace(tags["title"])
+		e.Artist = strings.TrimSpace(firstNonEmpty(tags["artist"], tags["album_artist"], tags["albumartist"]))
+		e.Album = strings.TrimSpace(tags["album"])
+		e.Duration, _ = strconv.ParseFloat(probe.Format.Duration, 64)
+	}
+
+	if e.Title == "" || e.Artist == "" {
+		artist, title := titleFromFilename(path)
+		if e.Title == "" {
+			e.Title = title
+		}
+		if e.Artist == "" {
+			e.Artist = artist
+		}
+	}
+	return e
+}
+
+func titleFromFilename(path string) (artist, title string) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	name = idSuffix.ReplaceAllString(name, "")
+	if a, t, ok := strings.Cut(name, " - "); ok {
+		return strings.TrimSpace(a), strings.TrimSpace(t)
+	}
+	return "", strings.TrimSpace(name)
+}
+
+func Cover(path string) ([]byte, error) {
+	out, err := exec.Command("ffmpeg",
+		"-v", "quiet",
+		"-i", path,
+		"-an",
+		"-map", "0:v:0",
+		"-frames:v", "1",
+		"-f", "image2pipe",
+		"-vcodec", "mjpeg",
+		"-",
+	).Output()
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no embedded cover")
+	}
+	return out, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+package library
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/atomicfile"
+	"github.com/IvelOt/youtui-player/internal/search"
+)
+
+const MaxStars = 5
+
+type Rating struct {
+	Favorite  bool      `json:"favorite,omitempty"`
+	Stars     int       `json:"stars,omitempty"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	URL       string    `json:"url"`
+	Thumbnail string    `json:"thumbnail,omitempty"`
+	Duration  string    `json:"duration,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Ratings struct {
+	path string
+
+	mu    sync.RWMutex
+	items map[string]Rating
+}
+
+func OpenRatings(path string) *Ratings {
+	r := &Ratings{path: path, items: map[string]Rating{}}
+	r.load()
+	return r
+}
+
+func RatingKey(rawURL string) string {
+	if path, ok := PathFromURL(rawURL); ok {
+		if id := VideoIDFromPath(path); id != "" {
+			return id
+		}
+		return path
+	}
+	if id := search.VideoID(rawURL); id != "" {
+		return id
+	}
+	return strings.TrimSpace(rawURL)
+}
+
+func (r *Ratings) Get(rawURL string) Rating {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.items[RatingKey(rawURL)]
+}
+
+func (r *Ratings) SetFavorite(meta Rating, favorite bool) {
+	r.update(meta, func(cur *Rating) { cur.Favorite = favorite })
+}
+
+func (r *Ratings) SetStars(meta Rating, stars int) {
+	stars = max(0, min(stars, MaxStars))
+	r.update(meta, func(cur *Rating) { cur.Stars = stars })
+}
+
+func (r *Ratings) update(meta Rating, change func(*Rating)) Rating {
+	key := RatingKey(meta.URL)
+	if key == "" {
+		return Rating{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cur := r.items[key]
+	cur.Title = meta.Title
+	cur.Author = meta.Author
+	cur.URL = meta.URL
+	cur.Thumbnail = meta.Thumbnail
+	cur.Duration = meta.Duration
+	cur.UpdatedAt = time.Now()
+	change(&cur)
+
+	if !cur.Favorite && cur.Stars == 0 {
+		delete(r.items, key)
+	} else {
+		r.items[key] = cur
+	}
+	r.save()
+	return cur
+}
+
+func (r *Ratings) All() []Rating {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]Rating, 0, len(r.items))
+	for _, item := range r.items {
+		list = append(list, item)
+	}
+	SortRatings(list)
+	return list
+}
+
+func (r *Ratings) Favorites() []Rating {
+	var favs []Rating
+	for _, item := range r.All() {
+		if item.Favorite {
+			favs = append(favs, item)
+		}
+	}
+	return favs
+}
+
+func SortRatings(list []Rating) {
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Stars != list[j].Stars {
+			return list[i].Stars > list[j].Stars
+		}
+		return list[i].UpdatedAt.After(list[j].UpdatedAt)
+	})
+}
+
+func (r *Ratings) load() {
+	if r.path == "" {
+		return
+	}
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return
+	}
+	_ = json.Unmarshal(data, &r.items)
+	if r.items == nil {
+		r.items = map[string]Rating{}
+	}
+}
+
+func (r *Ratings) save() {
+	if r.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(r.items, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = atomicfile.WriteFile(r.path, data, 0o644)
+}
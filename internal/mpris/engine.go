@@ -0,0 +1,99 @@
+package mpris
+
+import (
+	"math"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/library"
+	"github.com/godbus/dbus/v5"
+)
+
+type controller struct {
+	ctrl engine.Controller
+}
+
+func (c controller) PlayPause() {
+	st, err := c.ctrl.State()
+	if err == nil && !st.Playing {
+		c.Play()
+		return
+	}
+	_ = c.ctrl.TogglePause()
+}
+
+func (c controller) Play() { _ = engine.Resume(c.ctrl) }
+
+func (c controller) Pause() { _ = engine.Pause(c.ctrl) }
+
+func (c controller) Stop() { _ = c.ctrl.Stop() }
+
+func (c controller) Next() { _ = c.ctrl.Next() }
+
+func (c controller) Previous() { _ = c.ctrl.Previous() }
+
+func (c controller) Seek(offset time.Duration) { _ = c.ctrl.Seek(offset.Seconds(), false) }
+
+func (c controller) SetPosition(pos time.Duration) { _ = c.ctrl.Seek(pos.Seconds(), true) }
+
+func (c controller) SetVolume(volume float64) {
+	_ = c.ctrl.SetVolume(int(math.Round(volume * 100)))
+}
+
+func (c controller) OpenURI(uri string) {
+	_ = c.ctrl.PlayTrack(engine.Track{Title: uri, URL: uri})
+}
+
+type Bridge struct {
+	*Server
+	cancel func()
+}
+
+func Attach(conn *dbus.Conn, ctrl engine.Controller, artURL func(engine.Track) string) (*Bridge, error) {
+	srv, err := New(conn, controller{ctrl})
+	if err != nil {
+		return nil, err
+	}
+	events, cancel, err := ctrl.Subscribe()
+	if err != nil {
+		_ = srv.Close()
+		return nil, err
+	}
+
+	if st, err := ctrl.State(); err == nil {
+		srv.Update(statusFrom(st, artURL))
+	}
+	go func() {
+		for ev := range events {
+			srv.Update(statusFrom(ev.State, artURL))
+		}
+	}()
+	return &Bridge{Server: srv, cancel: cancel}, nil
+}
+
+func (b *Bridge) Close() error {
+	b.cancel()
+	return b.Server.Close()
+}
+
+func statusFrom(st engine.State, artURL func(engine.Track) string) Status {
+	status := Status{
+		Playing:     st.Playing,
+		Paused:      st.Paused,
+		Title:       st.Track.Title,
+		Artist:      st.Track.Author,
+		URL:         st.Track.URL,
+		Length:      time.Duration(st.Duration * float64(time.Second)),
+		Position:    time.Duration(st.Position * float64(time.Second)),
+		Volume:      float64(st.Volume) / 100,
+		CanNext:     st.PlaylistLen > 0,
+		CanPrevious: st.PlaylistLen > 0,
+	}
+	if path, ok := library.PathFromURL(status.URL); ok {
+		status.URL = "file://" + path
+	}
+	if st.Playing && artURL != nil {
+		status.ArtURL = artURL(st.Track)
+	}
+	return status
+}
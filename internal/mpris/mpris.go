@@ -0,0 +1,349 @@
+// Package mpris exposes the player on the session bus as an MPRIS2 media player
+package mpris
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+const (
+	BusName     = "org.mpris.MediaPlayer2.youtui_player"
+	ObjectPath  = dbus.ObjectPath("/org/mpris/MediaPlayer2")
+	RootIface   = "org.mpris.MediaPlayer2"
+	PlayerIface = "org.mpris.MediaPlayer2.Player"
+
+	noTrack = dbus.ObjectPath("/org/mpris/MediaPlayer2/TrackList/NoTrack")
+)
+
+type Player interface {
+	PlayPause()
+	Play()
+	Pause()
+	Stop()
+	Next()
+	Previous()
+	Seek(offset time.Duration)
+	SetPosition(pos time.Duration)
+	SetVolume(volume float64)
+	OpenURI(uri string)
+}
+
+type Status struct {
+	Playing     bool
+	Paused      bool
+	Title       string
+	Artist      string
+	URL         string
+	ArtURL      string
+	Length      time.Duration
+	Position    time.Duration
+	Volume      float64
+	CanNext     bool
+	CanPrevious bool
+}
+
+func (st Status) playbackStatus() string {
+	switch {
+	case !st.Playing:
+		return "Stopped"
+	case st.Paused:
+		return "Paused"
+	}
+	return "Playing"
+}
+
+func (st Status) trackID() dbus.ObjectPath {
+	if !st.Playing || st.URL == "" {
+		return noTrack
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(st.URL))
+	return dbus.ObjectPath(fmt.Sprintf("/org/youtui/track/t%x", h.Sum64()))
+}
+
+func (st Status) metadata() map[string]dbus.Variant {
+	m := map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(st.trackID()),
+	}
+	if !st.Playing {
+		return m
+	}
+	m["xesam:title"] = dbus.MakeVariant(st.Title)
+	if st.Artist != "" {
+		m["xesam:artist"] = dbus.MakeVariant([]string{st.Artist})
+	}
+	if st.URL != "" {
+		m["xesam:url"] = dbus.MakeVariant(st.URL)
+	}
+	if st.ArtURL != "" {
+		m["mpris:artUrl"] = dbus.MakeVariant(st.ArtURL)
+	}
+	if st.Length > 0 {
+		m["mpris:length"] = dbus.MakeVariant(st.Length.Microseconds())
+	}
+	return m
+}
+
+type Server struct {
+	conn    *dbus.Conn
+	player  Player
+	props   *prop.Properties
+	name    string
+	mu      sync.Mutex
+	last    Status
+	updated time.Time
+}
+
+func New(conn *dbus.Conn, player Player) (*Server, error) {
+	s := &Server{conn: conn, player: player, last: Status{Volume: 1}}
+
+	if err := conn.Export(root{}, ObjectPath, RootIface); err != nil {
+		return nil, err
+	}
+	if err := conn.ExportWithMap(methods{s}, methodNames, ObjectPath, PlayerIface); err != nil {
+		return nil, err
+	}
+
+	props, err := prop.Export(conn, ObjectPath, s.propMap())
+	if err != nil {
+		return nil, err
+	}
+	s.props = props
+
+	node := &introspect.Node{
+		Name: string(ObjectPath),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{
+				Name:       RootIface,
+				Methods:    introspect.Methods(root{}),
+				Properties: props.Introspection(RootIface),
+			},
+			{
+				Name:       PlayerIface,
+				Methods:    playerMethods(s),
+				Properties: props.Introspection(PlayerIface),
+				Signals: []introspect.Signal{{
+					Name: "Seeked",
+					Args: []introspect.Arg{{Name: "Position", Type: "x"}},
+				}},
+			},
+		},
+	}
+	if err := conn.Export(introspect.NewIntrospectable(node), ObjectPath, "org.freedesktop.DBus.Introspectable"); err != nil {
+		return nil, err
+	}
+
+	if err := s.requestName(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Server) requestName() error {
+	for _, name := range []string{BusName, fmt.Sprintf("%s.instance%d", BusName, os.Getpid())} {
+		reply, err := s.conn.RequestName(name, dbus.NameFlagDoNotQueue)
+		if err != nil {
+			return err
+		}
+		if reply == dbus.RequestNameReplyPrimaryOwner {
+			s.name = name
+			return nil
+		}
+	}
+	return fmt.Errorf("bus name %s is already taken", BusName)
+}
+
+func (s *Server) Name() string {
+	return s.name
+}
+
+func (s *Server) propMap() prop.Map {
+	constant := func(v any) *prop.Prop {
+		return &prop.Prop{Value: v, Emit: prop.EmitConst}
+	}
+	changing := func(v any) *prop.Prop {
+		return &prop.Prop{Value: v, Emit: prop.EmitTrue}
+	}
+
+	return prop.Map{
+		RootIface: {
+			"CanQuit":             constant(false),
+			"CanRaise":            constant(false),
+			"HasTrackList":        constant(false),
+			"Identity":            constant("YouTui Player"),
+			"DesktopEntry":        constant("youtui-player"),
+			"SupportedUriSchemes": constant([]string{"https", "http", "file"}),
+			"SupportedMimeTypes":  constant([]string{}),
+		},
+		PlayerIface: {
+			"PlaybackStatus": changing(s.last.playbackStatus()),
+			"Rate":           constant(1.0),
+			"MinimumRate":    constant(1.0),
+			"MaximumRate":    constant(1.0),
+			"Metadata":       changing(s.last.metadata()),
+			"Volume": {
+				Value:    s.last.Volume,
+				Writable: true,
+				Emit:     prop.EmitTrue,
+				Callback: func(c *prop.Change) *dbus.Error {
+					v, ok := c.Value.(float64)
+					if !ok {
+						return prop.ErrInvalidArg
+					}
+					go s.player.SetVolume(math.Max(v, 0))
+					return nil
+				},
+			},
+			"Position":      {Value: int64(0), Emit: prop.EmitFalse},
+			"CanGoNext":     changing(false),
+			"CanGoPrevious": changing(false),
+			"CanPlay":       changing(false),
+			"CanPause":      changing(false),
+			"CanSeek":       changing(false),
+			"CanControl":    constant(true),
+		},
+	}
+}
+
+func (s *Server) Update(st Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	prev := s.last
+	expected := prev.Position
+	if prev.Playing && !prev.Paused && !s.updated.IsZero() {
+		expected += now.Sub(s.updated)
+	}
+	s.last = st
+	s.updated = now
+
+	s.props.SetMust(PlayerIface, "Position", st.Position.Microseconds())
+
+	if st.playbackStatus() != prev.playbackStatus() {
+		s.props.SetMust(PlayerIface, "PlaybackStatus", st.playbackStatus())
+	}
+	if st.Title != prev.Title || st.Artist != prev.Artist || st.URL != prev.URL ||
+		st.ArtURL != prev.ArtURL || st.Length != prev.Length || st.Playing != prev.Playing {
+		s.props.SetMust(PlayerIface, "Metadata", st.metadata())
+	}
+	if st.Volume != prev.Volume {
+		s.props.SetMust(PlayerIface, "Volume", st.Volume)
+	}
+	if st.CanNext != prev.CanNext {
+		s.props.SetMust(PlayerIface, "CanGoNext", st.CanNext)
+	}
+	if st.CanPrevious != prev.CanPrevious {
+		s.props.SetMust(PlayerIface, "CanGoPrevious", st.CanPrevious)
+	}
+	if st.Playing != prev.Playing {
+		s.props.SetMust(PlayerIface, "CanPause", st.Playing)
+		s.props.SetMust(PlayerIface, "CanSeek", st.Playing)
+		s.props.SetMust(PlayerIface, "CanPlay", st.Playing || st.CanNext)
+	}
+
+	if st.Playing && prev.Playing && st.URL == prev.URL {
+		if d := st.Position - expected; d > 2*time.Second || d < -2*time.Second {
+			_ = s.conn.Emit(ObjectPath, PlayerIface+".Seeked", st.Position.Microseconds())
+		}
+	}
+}
+
+func (s *Server) Close() error {
+	if s.name != "" {
+		if _, err := s.conn.ReleaseName(s.name); err != nil {
+			return err
+		}
+	}
+	for _, iface := range []string{RootIface, PlayerIface, "org.freedesktop.DBus.Properties", "org.freedesktop.DBus.Introspectable"} {
+		_ = s.conn.Export(nil, ObjectPath, iface)
+	}
+	return nil
+}
+
+type root struct{}
+
+func (root) Raise() *dbus.Error { return nil }
+
+func (root) Quit() *dbus.Error { return nil }
+
+var methodNames = map[string]string{"SeekBy": "Seek"}
+
+func playerMethods(s *Server) []introspect.Method {
+	list := introspect.Methods(methods{s})
+	for i, m := range list {
+		if name, ok := methodNames[m.Name]; ok {
+			list[i].Name = name
+		}
+	}
+	return list
+}
+
+type methods struct {
+	s *Server
+}
+
+func (m methods) current() Status {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+	return m.s.last
+}
+
+func (m methods) PlayPause() *dbus.Error {
+	go m.s.player.PlayPause()
+	return nil
+}
+
+func (m methods) Play() *dbus.Error {
+	go m.s.player.Play()
+	return nil
+}
+
+func (m methods) Pause() *dbus.Error {
+	go m.s.player.Pause()
+	return nil
+}
+
+func (m methods) Stop() *dbus.Error {
+	go m.s.player.Stop()
+	return nil
+}
+
+func (m methods) Next() *dbus.Error {
+	go m.s.player.Next()
+	return nil
+}
+
+func (m methods) Previous() *dbus.Error {
+	go m.s.player.Previous()
+	return nil
+}
+
+func (m methods) SeekBy(offset int64) *dbus.Error {
+	go m.s.player.Seek(time.Duration(offset) * time.Microsecond)
+	return nil
+}
+
+func (m methods) SetPosition(trackID dbus.ObjectPath, position int64) *dbus.Error {
+	st := m.current()
+	if trackID != st.trackID() || position < 0 || (st.Length > 0 && time.Duration(position)*time.Microsecond > st.Length) {
+		return nil
+	}
+	go m.s.player.SetPosition(time.Duration(position) * time.Microsecond)
+	return nil
+}
+
+func (m methods) OpenUri(uri string) *dbus.Error {
+	go m.s.player.OpenURI(uri)
+	return nil
+}
@@ -0,0 +1,256 @@
+package mpris
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/dbustest"
+	"github.com/IvelOt/youtui-player/internal/enginetest"
+	"github.com/godbus/dbus/v5"
+)
+
+type fakePlayer struct {
+	calls chan string
+}
+
+func (p *fakePlayer) record(format string, args ...any) {
+	p.calls <- fmt.Sprintf(format, args...)
+}
+
+func (p *fakePlayer) PlayPause()                    { p.record("PlayPause") }
+func (p *fakePlayer) Play()                         { p.record("Play") }
+func (p *fakePlayer) Pause()                        { p.record("Pause") }
+func (p *fakePlayer) Stop()                         { p.record("Stop") }
+func (p *fakePlayer) Next()                         { p.record("Next") }
+func (p *fakePlayer) Previous()                     { p.record("Previous") }
+func (p *fakePlayer) Seek(offset time.Duration)     { p.record("Seek %s", offset) }
+func (p *fakePlayer) SetPosition(pos time.Duration) { p.record("SetPosition %s", pos) }
+func (p *fakePlayer) SetVolume(volume float64)      { p.record("SetVolume %.2f", volume) }
+func (p *fakePlayer) OpenURI(uri string)            { p.record("OpenURI %s", uri) }
+
+func (p *fakePlayer) expect(t *testing.T, want string) {
+	t.Helper()
+	if got := enginetest.Receive(t, p.calls); got != want {
+		t.Fatalf("player got %q, want %q", got, want)
+	}
+}
+
+func (p *fakePlayer) expectNone(t *testing.T) {
+	t.Helper()
+	enginetest.Quiet(t, p.calls)
+}
+
+var playing = Status{
+	Playing:     true,
+	Title:       "Never Gonna Give You Up",
+	Artist:      "Rick Astley",
+	URL:         "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+	ArtURL:      "https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg",
+	Length:      213 * time.Second,
+	Position:    10 * time.Second,
+	Volume:      0.8,
+	CanNext:     true,
+	CanPrevious: true,
+}
+
+func setup(t *testing.T) (*Server, *fakePlayer, dbus.BusObject, *dbus.Conn) {
+	t.Helper()
+	addr := dbustest.Start(t)
+
+	player := &fakePlayer{calls: make(chan string, 16)}
+	srv, err := New(dbustest.Connect(t, addr), player)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = srv.Close()
+	})
+	if srv.Name() != BusName {
+		t.Fatalf("Name() = %q, want %q", srv.Name(), BusName)
+	}
+
+	client := dbustest.Connect(t, addr)
+	return srv, player, client.Object(BusName, ObjectPath), client
+}
+
+func TestMethods(t *testing.T) {
+	srv, player, obj, _ := setup(t)
+	srv.Update(playing)
+
+	call := func(method string, args ...any) {
+		t.Helper()
+		if err := obj.Call(PlayerIface+"."+method, 0, args...).Err; err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+	}
+
+	call("PlayPause")
+	player.expect(t, "PlayPause")
+	call("Next")
+	player.expect(t, "Next")
+	call("Previous")
+	player.expect(t, "Previous")
+	call("Seek", int64(-5_000_000))
+	player.expect(t, "Seek -5s")
+
+	call("SetPosition", playing.trackID(), int64(30_000_000))
+	player.expect(t, "SetPosition 30s")
+	call("SetPosition", dbus.ObjectPath("/org/youtui/track/other"), int64(30_000_000))
+	player.expectNone(t)
+	call("SetPosition", playing.trackID(), int64(300_000_000))
+	player.expectNone(t)
+
+	call("OpenUri", "https://youtu.be/dQw4w9WgXcQ")
+	player.expect(t, "OpenURI https://youtu.be/dQw4w9WgXcQ")
+
+	if err := obj.SetProperty(PlayerIface+".Volume", dbus.MakeVariant(0.5)); err != nil {
+		t.Fatal(err)
+	}
+	player.expect(t, "SetVolume 0.50")
+}
+
+func TestMetadata(t *testing.T) {
+	srv, _, obj, _ := setup(t)
+
+	v, err := obj.GetProperty(PlayerIface + ".PlaybackStatus")
+	if err != nil || v.Value() != "Stopped" {
+		t.Fatalf("PlaybackStatus = %v, %v; want Stopped", v, err)
+	}
+
+	srv.Update(playing)
+
+	v, err = obj.GetProperty(PlayerIface + ".Metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta, ok := v.Value().(map[string]dbus.Variant)
+	if !ok {
+		t.Fatalf("Metadata has type %T", v.Value())
+	}
+	want := map[string]any{
+		"mpris:trackid": playing.trackID(),
+		"xesam:title":   playing.Title,
+		"xesam:url":     playing.URL,
+		"mpris:artUrl":  playing.ArtURL,
+		"mpris:length":  int64(213_000_000),
+	}
+	for key, value := range want {
+		if got := meta[key].Value(); got != value {
+			t.Errorf("%s = %v, want %v", key, got, value)
+		}
+	}
+	if artists, _ := meta["xesam:artist"].Value().([]string); len(artists) != 1 || artists[0] != "Rick Astley" {
+		t.Errorf("xesam:artist = %v", meta["xesam:artist"].Value())
+	}
+
+	for prop, value := range map[string]any{
+		"PlaybackStatus": "Playing",
+		"Position":       int64(10_000_000),
+		"Volume":         0.8,
+		"CanGoNext":      true,
+		"CanSeek":        true,
+	} {
+		v, err := obj.GetProperty(PlayerIface + "." + prop)
+		if err != nil || v.Value() != value {
+			t.Errorf("%s = %v, %v; want %v", prop, v, err, value)
+		}
+	}
+}
+
+func TestPropertiesChanged(t *testing.T) {
+	srv, _, _, client := setup(t)
+
+	if err := client.AddMatchSignal(
+		dbus.WithMatchObjectPath(ObjectPath),
+		dbus.WithMatchInterface("org.freedesktop.DBus.Properties"),
+		dbus.WithMatchMember("PropertiesChanged"),
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AddMatchSignal(
+		dbus.WithMatchObjectPath(ObjectPath),
+		dbus.WithMatchInterface(PlayerIface),
+		dbus.WithMatchMember("Seeked"),
+	); err != nil {
+		t.Fatal(err)
+	}
+	signals := make(chan *dbus.Signal, 32)
+	client.Signal(signals)
+
+	changed := func(want string, match func(dbus.Variant) bool) {
+		t.Helper()
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case sig := <-signals:
+				if sig.Name != "org.freedesktop.DBus.Properties.PropertiesChanged" || sig.Body[0] != PlayerIface {
+					continue
+				}
+				props := sig.Body[1].(map[string]dbus.Variant)
+				if v, ok := props[want]; ok && match(v) {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("no matching PropertiesChanged for %s", want)
+			}
+		}
+	}
+
+	status := func(want string) func(dbus.Variant) bool {
+		return func(v dbus.Variant) bool { return v.Value() == want }
+	}
+
+	srv.Update(playing)
+	changed("PlaybackStatus", status("Playing"))
+
+	next := playing
+	next.Title = "Together Forever"
+	next.URL = "https://www.youtube.com/watch?v=yPYZpwSpKmA"
+	next.Position = 0
+	srv.Update(next)
+	changed("Metadata", func(v dbus.Variant) bool {
+		meta := v.Value().(map[string]dbus.Variant)
+		return meta["xesam:title"].Value() == "Together Forever" && meta["mpris:trackid"].Value() == next.trackID()
+	})
+
+	paused := next
+	paused.Paused = true
+	srv.Update(paused)
+	changed("PlaybackStatus", status("Paused"))
+
+	jumped := paused
+	jumped.Position = 90 * time.Second
+	srv.Update(jumped)
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case sig := <-signals:
+			if sig.Name == PlayerIface+".Seeked" {
+				if pos := sig.Body[0].(int64); pos != 90_000_000 {
+					t.Fatalf("Seeked to %d", pos)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("no Seeked signal after a jump")
+		}
+	}
+}
+
+func TestSecondInstanceGetsUniqueName(t *testing.T) {
+	addr := dbustest.Start(t)
+	first, err := New(dbustest.Connect(t, addr), &fakePlayer{calls: make(chan string, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	second, err := New(dbustest.Connect(t, addr), &fakePlayer{calls: make(chan string, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+	if second.Name() == BusName {
+		t.Fatal("second instance took the primary name")
+	}
+}
@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"github.com/IvelOt/youtui-player/internal/engine"
+)
+
+type Options struct {
+	TrackStart bool
+	Errors     bool
+	Icon       func(engine.Track) string
+	ErrorText  func(ev engine.Event) string
+}
+
+type Watcher struct {
+	cancel func()
+	done   chan struct{}
+}
+
+func Watch(n *Notifier, ctrl engine.Controller, opts Options) (*Watcher, error) {
+	events, cancel, err := ctrl.Subscribe()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(w.done)
+		for ev := range events {
+			if msg, ok := notificationFor(ev, opts); ok {
+				_ = n.Send(msg)
+			}
+		}
+	}()
+	return w, nil
+}
+
+func (w *Watcher) Close() {
+	w.cancel()
+	<-w.done
+}
+
+func notificationFor(ev engine.Event, opts Options) (Notification, bool) {
+	if ev.Track == nil {
+		return Notification{}, false
+	}
+	track := *ev.Track
+
+	switch {
+	case ev.Type == engine.EventStarted && opts.TrackStart:
+		msg := Notification{Summary: track.Title, Body: track.Author, Urgency: Low}
+		if opts.Icon != nil {
+			msg.Icon = opts.Icon(track)
+		}
+		return msg, true
+	case ev.Type == engine.EventEnded && opts.Errors && (ev.Blocked || ev.Error != ""):
+		summary := ev.Error
+		if opts.ErrorText != nil {
+			summary = opts.ErrorText(ev)
+		}
+		return Notification{Summary: summary, Body: track.Title, Urgency: Critical}, true
+	}
+	return Notification{}, false
+}
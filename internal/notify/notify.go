@@ -0,0 +1,64 @@
+// Package notify shows desktop notifications through org.freedesktop.Notifications
+package notify
+
+import (
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	BusName    = "org.freedesktop.Notifications"
+	ObjectPath = "/org/freedesktop/Notifications"
+	Interface  = "org.freedesktop.Notifications"
+)
+
+type Urgency byte
+
+const (
+	Low Urgency = iota
+	Normal
+	Critical
+)
+
+type Notification struct {
+	Summary string
+	Body    string
+	Icon    string
+	Urgency Urgency
+}
+
+type Notifier struct {
+	obj     dbus.BusObject
+	appName string
+
+	mu sync.Mutex
+	id uint32
+}
+
+func New(conn *dbus.Conn, appName string) *Notifier {
+	return &Notifier{obj: conn.Object(BusName, ObjectPath), appName: appName}
+}
+
+func (n *Notifier) Send(msg Notification) error {
+	hints := map[string]dbus.Variant{
+		"urgency": dbus.MakeVariant(byte(msg.Urgency)),
+	}
+	if msg.Icon != "" {
+		hints["image-path"] = dbus.MakeVariant("file://" + msg.Icon)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var id uint32
+	err := n.obj.Call(Interface+".Notify", 0,
+		n.appName, n.id, msg.Icon, msg.Summary, msg.Body,
+		[]string{}, hints, int32(-1),
+	).Store(&id)
+	if err != nil {
+		return err
+	}
+	n.id = id
+	return nil
+}
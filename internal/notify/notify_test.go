@@ -0,0 +1,167 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/IvelOt/youtui-player/internal/dbustest"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/enginetest"
+	"github.com/godbus/dbus/v5"
+)
+
+type notifyCall struct {
+	appName   string
+	replaces  uint32
+	icon      string
+	summary   string
+	body      string
+	urgency   byte
+	imagePath string
+}
+
+type fakeServer struct {
+	calls  chan notifyCall
+	nextID uint32
+}
+
+func (s *fakeServer) Notify(appName string, replaces uint32, icon, summary, body string,
+	actions []string, hints map[string]dbus.Variant, timeout int32) (uint32, *dbus.Error) {
+	call := notifyCall{appName: appName, replaces: replaces, icon: icon, summary: summary, body: body}
+	if v, ok := hints["urgency"]; ok {
+		call.urgency, _ = v.Value().(byte)
+	}
+	if v, ok := hints["image-path"]; ok {
+		call.imagePath, _ = v.Value().(string)
+	}
+	s.calls <- call
+
+	if replaces != 0 {
+		return replaces, nil
+	}
+	s.nextID++
+	return s.nextID, nil
+}
+
+func startServer(t *testing.T) (*fakeServer, *dbus.Conn) {
+	t.Helper()
+	addr := dbustest.Start(t)
+
+	srv := &fakeServer{calls: make(chan notifyCall, 16), nextID: 41}
+	conn := dbustest.Connect(t, addr)
+	if err := conn.Export(srv, ObjectPath, Interface); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := conn.RequestName(BusName, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("RequestName: %v, %v", reply, err)
+	}
+	return srv, dbustest.Connect(t, addr)
+}
+
+func TestSendReusesID(t *testing.T) {
+	srv, conn := startServer(t)
+	n := New(conn, "youtui-player")
+
+	if err := n.Send(Notification{Summary: "One", Body: "Band", Icon: "/tmp/one.jpg", Urgency: Low}); err != nil {
+		t.Fatal(err)
+	}
+	first := enginetest.Receive(t, srv.calls)
+	want := notifyCall{
+		appName:   "youtui-player",
+		icon:      "/tmp/one.jpg",
+		summary:   "One",
+		body:      "Band",
+		urgency:   byte(Low),
+		imagePath: "file:///tmp/one.jpg",
+	}
+	if first != want {
+		t.Fatalf("first notification = %+v, want %+v", first, want)
+	}
+
+	if err := n.Send(Notification{Summary: "Two", Urgency: Critical}); err != nil {
+		t.Fatal(err)
+	}
+	second := enginetest.Receive(t, srv.calls)
+	if second.replaces != 42 {
+		t.Fatalf("replaces_id = %d, want 42", second.replaces)
+	}
+	if second.summary != "Two" || second.urgency != byte(Critical) || second.imagePath != "" {
+		t.Fatalf("second notification = %+v", second)
+	}
+}
+
+func TestWatchTrackChanges(t *testing.T) {
+	srv, conn := startServer(t)
+	ctrl := enginetest.NewController()
+	w, err := Watch(New(conn, "youtui-player"), ctrl, Options{
+		TrackStart: true,
+		Errors:     true,
+		Icon:       func(track engine.Track) string { return "/cache/" + track.Title + ".jpg" },
+		ErrorText:  func(ev engine.Event) string { return "failed: " + ev.Error },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctrl.Send(enginetest.Started(enginetest.Track("Never Gonna Give You Up", "Rick Astley")))
+	first := enginetest.Receive(t, srv.calls)
+	if first.appName != "youtui-player" || first.summary != "Never Gonna Give You Up" ||
+		first.body != "Rick Astley" || first.icon != "/cache/Never Gonna Give You Up.jpg" || first.replaces != 0 {
+		t.Fatalf("first track = %+v", first)
+	}
+
+	ctrl.Send(engine.Event{Type: engine.EventState, Track: &engine.Track{Title: "ignored"}})
+	ctrl.Send(enginetest.Started(enginetest.Track("Together Forever", "Rick Astley")))
+	second := enginetest.Receive(t, srv.calls)
+	if second.summary != "Together Forever" || second.replaces != 42 {
+		t.Fatalf("second track = %+v, want it to replace 42", second)
+	}
+
+	track := engine.Track{Title: "Together Forever"}
+	ctrl.Send(engine.Event{Type: engine.EventEnded, Track: &track, Finished: true})
+	ctrl.Send(engine.Event{Type: engine.EventEnded, Track: &track, Error: "exit 2"})
+	failed := enginetest.Receive(t, srv.calls)
+	if failed.summary != "failed: exit 2" || failed.body != "Together Forever" || failed.urgency != byte(Critical) {
+		t.Fatalf("error notification = %+v", failed)
+	}
+
+	ctrl.Close()
+	w.Close()
+	enginetest.Quiet(t, srv.calls)
+}
+
+func TestWatchHonoursEventFlags(t *testing.T) {
+	track := engine.Track{Title: "Song"}
+	blocked := engine.Event{Type: engine.EventEnded, Track: &track, Blocked: true}
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"track start only", Options{TrackStart: true}, []string{"Song"}},
+		{"errors only", Options{Errors: true}, []string{""}},
+		{"nothing", Options{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, conn := startServer(t)
+			ctrl := enginetest.NewController()
+			w, err := Watch(New(conn, "youtui-player"), ctrl, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctrl.Send(enginetest.Started(enginetest.Track("Song", "Band")))
+			ctrl.Send(blocked)
+			ctrl.Close()
+			w.Close()
+
+			for _, summary := range tt.want {
+				if got := enginetest.Receive(t, srv.calls); got.summary != summary {
+					t.Fatalf("summary = %q, want %q", got.summary, summary)
+				}
+			}
+			enginetest.Quiet(t, srv.calls)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package nowplaying renders the playing track through user templates
+package nowplaying
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/library"
+)
+
+const DefaultTemplate = "{icon} {track} {position}/{duration}"
+
+type Fields map[string]string
+
+var placeholder = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+func FieldsFrom(st engine.State) Fields {
+	f := Fields{
+		"state":    State(st),
+		"icon":     "",
+		"title":    "",
+		"author":   "",
+		"track":    "",
+		"url":      "",
+		"position": "",
+		"duration": "",
+		"percent":  "0",
+		"volume":   strconv.Itoa(st.Volume),
+		"index":    "",
+		"count":    strconv.Itoa(st.PlaylistLen),
+	}
+	if !st.Playing {
+		return f
+	}
+
+	f["icon"] = "▶"
+	if st.Paused {
+		f["icon"] = "⏸"
+	}
+	f["title"] = st.Track.Title
+	f["author"] = st.Track.Author
+	f["track"] = st.Track.Title
+	if st.Track.Author != "" {
+		f["track"] = st.Track.Title + " - " + st.Track.Author
+	}
+	f["url"] = st.Track.URL
+	if path, ok := library.PathFromURL(st.Track.URL); ok {
+		f["url"] = path
+	}
+	f["position"] = Clock(st.Position)
+	f["duration"] = Clock(st.Duration)
+	if st.Duration <= 0 && st.Track.Duration != "" {
+		f["duration"] = st.Track.Duration
+	}
+	f["percent"] = strconv.Itoa(Percent(st))
+	if st.Index >= 0 {
+		f["index"] = strconv.Itoa(st.Index + 1)
+	}
+	return f
+}
+
+func State(st engine.State) string {
+	switch {
+	case !st.Playing:
+		return "stopped"
+	case st.Paused:
+		return "paused"
+	}
+	return "playing"
+}
+
+func Percent(st engine.State) int {
+	if !st.Playing || st.Duration <= 0 {
+		return 0
+	}
+	return int(min(max(st.Position/st.Duration, 0), 1) * 100)
+}
+
+func Clock(seconds float64) string {
+	s := max(int(seconds), 0)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s%3600/60, s%60)
+	}
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
+}
+
+func Render(tmpl string, fields Fields, escape func(string) string) string {
+	return placeholder.ReplaceAllStringFunc(tmpl, func(m string) string {
+		v, ok := fields[m[1:len(m)-1]]
+		if !ok {
+			return m
+		}
+		if escape != nil {
+			return escape(v)
+		}
+		return v
+	})
+}
@@ -0,0 +1,200 @@
+package nowplaying
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/atomicfile"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/library"
+)
+
+const DefaultFile = "nowplaying.txt"
+
+type Options struct {
+	Dir         string
+	Files       map[string]string
+	Thumbnail   string
+	EverySecond bool
+}
+
+type Overlay struct {
+	opts   Options
+	cancel func()
+	done   chan struct{}
+
+	mu      sync.Mutex
+	written map[string]string
+	thumb   string
+	fetch   context.CancelFunc
+}
+
+func Attach(ctrl engine.Controller, opts Options) (*Overlay, error) {
+	if opts.Dir == "" {
+		return nil, errors.New("no output directory")
+	}
+	if len(opts.Files) == 0 {
+		opts.Files = map[string]string{DefaultFile: "{track}"}
+	}
+	for name := range opts.Files {
+		if !validName(name) {
+			return nil, fmt.Errorf("invalid now-playing file name %q", name)
+		}
+	}
+	if opts.Thumbnail != "" && !validName(opts.Thumbnail) {
+		return nil, fmt.Errorf("invalid thumbnail file name %q", opts.Thumbnail)
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	if opts.Thumbnail != "" {
+		_ = os.Remove(filepath.Join(opts.Dir, opts.Thumbnail))
+	}
+
+	events, cancel, err := ctrl.Subscribe()
+	if err != nil {
+		return nil, err
+	}
+	o := &Overlay{opts: opts, cancel: cancel, done: make(chan struct{}), written: map[string]string{}}
+
+	st, err := ctrl.State()
+	if err != nil {
+		st = engine.State{}
+	}
+	o.update(st, true)
+
+	go func() {
+		defer close(o.done)
+		last := st
+		for ev := range events {
+			changed := ev.Type != engine.EventState ||
+				ev.State.Playing != last.Playing ||
+				ev.State.Paused != last.Paused ||
+				ev.State.Track.URL != last.Track.URL
+			if changed || o.opts.EverySecond {
+				o.update(ev.State, changed)
+			}
+			last = ev.State
+		}
+		o.update(engine.State{}, true)
+	}()
+	return o, nil
+}
+
+func (o *Overlay) Close() {
+	o.cancel()
+	<-o.done
+
+	o.mu.Lock()
+	if o.fetch != nil {
+		o.fetch()
+	}
+	o.mu.Unlock()
+}
+
+func validName(name string) bool {
+	return name != "" && name != "." && name != ".." && filepath.Base(name) == name
+}
+
+func (o *Overlay) update(st engine.State, trackChanged bool) {
+	fields := FieldsFrom(st)
+	for name, tmpl := range o.opts.Files {
+		text := ""
+		if st.Playing {
+			text = Render(tmpl, fields, nil)
+		}
+
+		o.mu.Lock()
+		prev, ok := o.written[name]
+		o.mu.Unlock()
+		if ok && prev == text {
+			continue
+		}
+		if err := atomicfile.WriteFile(filepath.Join(o.opts.Dir, name), []byte(text), 0o644); err != nil {
+			continue
+		}
+		o.mu.Lock()
+		o.written[name] = text
+		o.mu.Unlock()
+	}
+
+	if trackChanged && o.opts.Thumbnail != "" {
+		o.updateThumbnail(st)
+	}
+}
+
+func (o *Overlay) updateThumbnail(st engine.State) {
+	source := ""
+	if st.Playing {
+		source = st.Track.Thumbnail
+	}
+
+	o.mu.Lock()
+	if source == o.thumb {
+		o.mu.Unlock()
+		return
+	}
+	o.thumb = source
+	if o.fetch != nil {
+		o.fetch()
+		o.fetch = nil
+	}
+	path := filepath.Join(o.opts.Dir, o.opts.Thumbnail)
+	if source == "" {
+		_ = os.Remove(path)
+		o.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	o.fetch = cancel
+	o.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		data, err := fetchImage(ctx, source)
+
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		if ctx.Err() != nil || o.thumb != source {
+			return
+		}
+		if err != nil || len(data) == 0 {
+			_ = os.Remove(path)
+			return
+		}
+		_ = atomicfile.WriteFile(path, data, 0o644)
+	}()
+}
+
+func fetchImage(ctx context.Context, source string) ([]byte, error) {
+	if path, ok := library.PathFromURL(source); ok {
+		return library.Cover(path)
+	}
+	if !strings.HasPrefix(source, "http") {
+		return nil, errors.New("unsupported thumbnail source")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http status: %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+}
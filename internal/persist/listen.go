@@ -0,0 +1,124 @@
+package persist
+
+import (
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/history"
+)
+
+type listen struct {
+	track     engine.Track
+	session   uint64
+	started   time.Time
+	lastPos   float64
+	duration  float64
+	listened  float64
+	incognito bool
+}
+
+func (o *Owner) run(events <-chan engine.Event) {
+	defer close(o.done)
+	var last engine.State
+	for ev := range events {
+		switch ev.Type {
+		case engine.EventStarted:
+			if ev.Track != nil {
+				o.begin(*ev.Track, ev.Session)
+			}
+			o.report(o.savePlayback())
+		case engine.EventState:
+			if ev.State.Playing {
+				o.track(ev.State)
+			}
+			if ev.State.Mode != last.Mode || ev.State.PlayMode != last.PlayMode {
+				o.report(o.savePlayback())
+			}
+		case engine.EventEnded:
+			o.end(ev.Session, ev.Finished)
+		case engine.EventPlaylist:
+			o.report(o.savePlayback())
+		}
+		if ev.Type != engine.EventHistory {
+			last = ev.State
+		}
+	}
+	o.end(0, false)
+	o.report(o.savePlayback())
+}
+
+func (o *Owner) begin(track engine.Track, session uint64) {
+	o.mu.Lock()
+	prev := o.listening
+	o.listening = &listen{
+		track:     track,
+		session:   session,
+		started:   time.Now(),
+		incognito: o.incognito,
+	}
+	o.mu.Unlock()
+
+	o.record(prev, false)
+}
+
+func (o *Owner) track(st engine.State) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	l := o.listening
+	if l == nil || l.session != st.Session {
+		return
+	}
+	if delta := st.Position - l.lastPos; delta > 0 && delta <= 3 && !st.Paused {
+		l.listened += delta
+	}
+	l.lastPos = st.Position
+	if st.Duration > 0 {
+		l.duration = st.Duration
+	}
+}
+
+func (o *Owner) end(session uint64, finished bool) {
+	o.mu.Lock()
+	l := o.listening
+	if l == nil || (session != 0 && l.session != session) {
+		o.mu.Unlock()
+		return
+	}
+	o.listening = nil
+	o.mu.Unlock()
+
+	o.record(l, finished)
+}
+
+func (o *Owner) record(l *listen, finished bool) {
+	if l == nil || l.incognito {
+		return
+	}
+
+	completed := l.duration > 0 && l.listened >= l.duration*0.9
+	if finished && (l.duration == 0 || l.lastPos >= l.duration-10) {
+		completed = true
+	}
+	if l.listened < 1 && !completed {
+		return
+	}
+
+	o.mu.Lock()
+	err := o.history.Append(history.Entry{
+		Title:     l.track.Title,
+		Author:    l.track.Author,
+		URL:       l.track.URL,
+		Thumbnail: l.track.Thumbnail,
+		Duration:  l.track.Duration,
+		StartedAt: l.started,
+		Listened:  l.listened,
+		Completed: completed,
+	})
+	o.mu.Unlock()
+	if err != nil {
+		o.report(err)
+		return
+	}
+	o.changed()
+}
@@ -0,0 +1,326 @@
+// Package persist keeps saved state and listening history in the process that runs the player
+package persist
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/history"
+	"github.com/IvelOt/youtui-player/internal/library"
+	"github.com/IvelOt/youtui-player/internal/store"
+)
+
+type Data interface {
+	History() ([]history.Entry, error)
+	ClearHistory() error
+	Incognito() (bool, error)
+	SetIncognito(on bool) error
+	LoadSession() (*config.PlayerState, error)
+	SaveSession(state *config.PlayerState) error
+	Offline() (map[string]string, error)
+	AddOffline(id, path string) error
+}
+
+type Owner struct {
+	mu        sync.Mutex
+	store     *store.Store
+	history   *history.Store
+	incognito bool
+	listening *listen
+	offline   *library.Index
+
+	eng     *engine.Engine
+	onError func(error)
+	cancel  func()
+	done    chan struct{}
+}
+
+func Open() (*Owner, error) {
+	o := &Owner{}
+	err := o.open()
+	return o, err
+}
+
+func (o *Owner) open() error {
+	st, err := store.Open(config.GetStorePath())
+	if err != nil {
+		o.history, _ = history.Open(nil, "")
+		return err
+	}
+
+	legacyHistory := config.GetHistoryPath()
+	_, migrateErr := st.MigrateJSON()
+	if migrateErr != nil {
+		legacyHistory = ""
+	}
+	hist, err := history.Open(st.DB(), legacyHistory)
+	o.store, o.history = st, hist
+	if migrateErr != nil {
+		return migrateErr
+	}
+	return err
+}
+
+func (o *Owner) Watch(eng *engine.Engine, onError func(error)) error {
+	o.restorePlayback(eng)
+
+	events, cancel, err := eng.Subscribe()
+	if err != nil {
+		return err
+	}
+	o.mu.Lock()
+	o.eng, o.onError, o.cancel = eng, onError, cancel
+	o.done = make(chan struct{})
+	o.mu.Unlock()
+
+	go o.run(events)
+	return nil
+}
+
+func (o *Owner) Close() {
+	o.mu.Lock()
+	cancel, done := o.cancel, o.done
+	o.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.store != nil {
+		_ = o.store.Close()
+		o.store = nil
+	}
+}
+
+func (o *Owner) Recover(backup string) (string, error) {
+	o.mu.Lock()
+	if o.store != nil {
+		_ = o.store.Close()
+		o.store = nil
+	}
+	aside, err := store.Recover(config.GetStorePath(), backup)
+	if err == nil {
+		err = o.open()
+	}
+	eng := o.eng
+	o.mu.Unlock()
+
+	if err == nil && backup != "" && eng != nil {
+		o.restorePlayback(eng)
+	}
+	if err == nil {
+		o.loadOffline()
+	}
+	o.changed()
+	return aside, err
+}
+
+func (o *Owner) restorePlayback(eng *engine.Engine) {
+	state, err := o.LoadSession()
+	if err != nil || len(state.Playlist) == 0 {
+		return
+	}
+	_ = eng.SetPlaylist(Tracks(state.Playlist), state.CurrentTrackIdx)
+	_ = eng.SetPlaylistMode(engine.PlaylistMode(state.PlaylistMode))
+	_ = eng.SetPlayMode(engine.PlayMode(state.PlayMode))
+}
+
+func (o *Owner) History() ([]history.Entry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.history.Entries(), nil
+}
+
+func (o *Owner) ClearHistory() error {
+	o.mu.Lock()
+	err := o.history.Clear()
+	o.mu.Unlock()
+	if err == nil {
+		o.changed()
+	}
+	return err
+}
+
+func (o *Owner) Incognito() (bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.incognito, nil
+}
+
+func (o *Owner) SetIncognito(on bool) error {
+	o.mu.Lock()
+	o.incognito = on
+	if o.listening != nil {
+		o.listening.incognito = o.listening.incognito || on
+	}
+	o.mu.Unlock()
+	o.changed()
+	return nil
+}
+
+func (o *Owner) LoadSession() (*config.PlayerState, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.store == nil {
+		return &config.PlayerState{CurrentTrackIdx: -1}, nil
+	}
+	return o.store.LoadState()
+}
+
+func (o *Owner) SaveSession(state *config.PlayerState) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.store == nil {
+		return nil
+	}
+	saved := *state
+	o.fillPlaybackLocked(&saved)
+	return o.store.SaveState(&saved)
+}
+
+func (o *Owner) IndexOffline(index *library.Index) {
+	o.mu.Lock()
+	o.offline = index
+	o.mu.Unlock()
+	o.loadOffline()
+}
+
+func (o *Owner) loadOffline() {
+	paths, err := o.Offline()
+	o.mu.Lock()
+	index := o.offline
+	o.mu.Unlock()
+	if err != nil || index == nil {
+		return
+	}
+	for id, path := range paths {
+		index.Add(id, path)
+	}
+}
+
+func (o *Owner) Offline() (map[string]string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.store == nil {
+		return map[string]string{}, nil
+	}
+	paths, err := o.store.Offline()
+	if err != nil {
+		return nil, err
+	}
+	for id, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			delete(paths, id)
+			_ = o.store.RemoveOffline(id)
+		}
+	}
+	return paths, nil
+}
+
+func (o *Owner) AddOffline(id, path string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.offline != nil {
+		o.offline.Add(id, path)
+	}
+	if o.store == nil {
+		return nil
+	}
+	return o.store.AddOffline(id, path)
+}
+
+func (o *Owner) savePlayback() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.store == nil {
+		return nil
+	}
+	state, err := o.store.LoadState()
+	if err != nil {
+		return err
+	}
+	o.fillPlaybackLocked(state)
+	return o.store.SaveState(state)
+}
+
+func (o *Owner) fillPlaybackLocked(state *config.PlayerState) {
+	if o.eng == nil {
+		return
+	}
+	st, err := o.eng.State()
+	if err != nil {
+		return
+	}
+	tracks, err := o.eng.Playlist()
+	if err != nil {
+		return
+	}
+	state.Playlist = ConfigTracks(tracks)
+	state.CurrentTrackIdx = st.Index
+	state.PlaylistMode = int(st.Mode)
+	state.PlayMode = int(st.PlayMode)
+}
+
+func (o *Owner) changed() {
+	o.mu.Lock()
+	eng := o.eng
+	o.mu.Unlock()
+	if eng != nil {
+		eng.Notify(engine.EventHistory)
+	}
+}
+
+func (o *Owner) report(err error) {
+	if err == nil {
+		return
+	}
+	o.mu.Lock()
+	onError := o.onError
+	o.mu.Unlock()
+	if onError != nil {
+		onError(err)
+	}
+}
+
+func ConfigTracks(tracks []engine.Track) []config.Track {
+	result := make([]config.Track, len(tracks))
+	for i, t := range tracks {
+		result[i] = config.Track{
+			Title:       t.Title,
+			Author:      t.Author,
+			URL:         t.URL,
+			Thumbnail:   t.Thumbnail,
+			Duration:    t.Duration,
+			PublishedAt: t.PublishedAt,
+			Description: t.Description,
+		}
+		if !t.AddedAt.IsZero() {
+			result[i].AddedAt = t.AddedAt.Unix()
+		}
+	}
+	return result
+}
+
+func Tracks(tracks []config.Track) []engine.Track {
+	result := make([]engine.Track, len(tracks))
+	for i, t := range tracks {
+		result[i] = engine.Track{
+			Title:       t.Title,
+			Author:      t.Author,
+			URL:         t.URL,
+			Thumbnail:   t.Thumbnail,
+			Duration:    t.Duration,
+			PublishedAt: t.PublishedAt,
+			Description: t.Description,
+		}
+		if t.AddedAt > 0 {
+			result[i].AddedAt = time.Unix(t.AddedAt, 0)
+		}
+	}
+	return result
+}
@@ -0,0 +1,106 @@
+package scrobble
+
+import (
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/engine"
+)
+
+type play struct {
+	session  uint64
+	listen   Listen
+	skip     bool
+	lastPos  float64
+	listened float64
+	duration float64
+}
+
+type Watcher struct {
+	s      *Scrobbler
+	skip   func() bool
+	cancel func()
+	done   chan struct{}
+}
+
+func Watch(s *Scrobbler, ctrl engine.Controller, skip func() bool) (*Watcher, error) {
+	events, cancel, err := ctrl.Subscribe()
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{s: s, skip: skip, cancel: cancel, done: make(chan struct{})}
+	go w.run(events)
+	return w, nil
+}
+
+func (w *Watcher) Close() {
+	w.cancel()
+	<-w.done
+}
+
+func (w *Watcher) run(events <-chan engine.Event) {
+	defer close(w.done)
+	var cur *play
+	for ev := range events {
+		switch ev.Type {
+		case engine.EventStarted:
+			w.finish(cur)
+			cur = nil
+			if ev.Track != nil {
+				cur = w.begin(*ev.Track, ev.Session)
+			}
+		case engine.EventState:
+			if cur != nil && ev.State.Session == cur.session {
+				cur.track(ev.State)
+			}
+		case engine.EventEnded:
+			if cur != nil && ev.Session == cur.session {
+				w.finish(cur)
+				cur = nil
+			}
+		}
+	}
+	w.finish(cur)
+}
+
+func (w *Watcher) begin(track engine.Track, session uint64) *play {
+	artist, title := Parse(track.Title, track.Author)
+	p := &play{
+		session: session,
+		listen: Listen{
+			Artist:     artist,
+			Track:      title,
+			ListenedAt: time.Now(),
+			URL:        track.URL,
+		},
+		skip: artist == "" || title == "" || (w.skip != nil && w.skip()),
+	}
+	if !p.skip {
+		w.s.NowPlaying(p.listen)
+	}
+	return p
+}
+
+func (p *play) track(st engine.State) {
+	if delta := st.Position - p.lastPos; delta > 0 && delta <= 3 && st.Playing && !st.Paused {
+		p.listened += delta
+	}
+	p.lastPos = st.Position
+	if st.Duration > 0 {
+		p.duration = st.Duration
+		p.listen.Duration = int(st.Duration)
+	}
+}
+
+func (w *Watcher) finish(p *play) {
+	if p == nil || p.skip || (w.skip != nil && w.skip()) {
+		return
+	}
+	if !Eligible(seconds(p.listened), seconds(p.duration)) {
+		return
+	}
+	w.s.Add(p.listen)
+}
+
+func seconds(v float64) time.Duration {
+	return time.Duration(v * float64(time.Second))
+}
@@ -0,0 +1,201 @@
+package scrobble
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const DefaultLastFMURL = "https://ws.audioscrobbler.com/2.0/"
+
+type LastFMOptions struct {
+	APIKey     string
+	APISecret  string
+	SessionKey string
+	Username   string
+	Password   string
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+type LastFM struct {
+	opts LastFMOptions
+	http *http.Client
+
+	mu         sync.Mutex
+	sessionKey string
+}
+
+func NewLastFM(opts LastFMOptions) (*LastFM, error) {
+	if opts.APIKey == "" || opts.APISecret == "" {
+		return nil, errors.New("last.fm: api_key and api_secret are required")
+	}
+	if opts.SessionKey == "" && (opts.Username == "" || opts.Password == "") {
+		return nil, errors.New("last.fm: session_key or username and password are required")
+	}
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultLastFMURL
+	}
+	return &LastFM{opts: opts, http: newHTTPClient(opts.HTTPClient), sessionKey: opts.SessionKey}, nil
+}
+
+func (c *LastFM) Name() string { return "lastfm" }
+
+func (c *LastFM) BatchSize() int { return 50 }
+
+func (c *LastFM) NowPlaying(ctx context.Context, l Listen) error {
+	params := url.Values{"artist": {l.Artist}, "track": {l.Track}}
+	if l.Duration > 0 {
+		params.Set("duration", strconv.Itoa(l.Duration))
+	}
+	return c.authed(ctx, "track.updateNowPlaying", params)
+}
+
+func (c *LastFM) Submit(ctx context.Context, listens []Listen) error {
+	params := url.Values{}
+	for i, l := range listens {
+		suffix := "[" + strconv.Itoa(i) + "]"
+		params.Set("artist"+suffix, l.Artist)
+		params.Set("track"+suffix, l.Track)
+		params.Set("timestamp"+suffix, strconv.FormatInt(l.ListenedAt.Unix(), 10))
+		if l.Duration > 0 {
+			params.Set("duration"+suffix, strconv.Itoa(l.Duration))
+		}
+	}
+	return c.authed(ctx, "track.scrobble", params)
+}
+
+func (c *LastFM) authed(ctx context.Context, method string, params url.Values) error {
+	sk, err := c.session(ctx)
+	if err != nil {
+		return err
+	}
+	params.Set("sk", sk)
+	err = c.call(ctx, method, params, nil)
+
+	var svc *ServiceError
+	if errors.As(err, &svc) && svc.Code == 9 && c.opts.Password != "" {
+		c.mu.Lock()
+		c.sessionKey = ""
+		c.mu.Unlock()
+	}
+	return err
+}
+
+func (c *LastFM) session(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sessionKey != "" {
+		return c.sessionKey, nil
+	}
+
+	var out struct {
+		Session struct {
+			Key string `json:"key"`
+		} `json:"session"`
+	}
+	params := url.Values{"username": {c.opts.Username}, "password": {c.opts.Password}}
+	if err := c.call(ctx, "auth.getMobileSession", params, &out); err != nil {
+		return "", err
+	}
+	if out.Session.Key == "" {
+		return "", &ServiceError{Service: c.Name(), Status: http.StatusOK, Message: "no session key in response", Retry: true}
+	}
+	c.sessionKey = out.Session.Key
+	return c.sessionKey, nil
+}
+
+func (c *LastFM) call(ctx context.Context, method string, params url.Values, out any) error {
+	params.Set("method", method)
+	params.Set("api_key", c.opts.APIKey)
+	params.Set("api_sig", c.sign(params))
+	params.Set("format", "json")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.opts.BaseURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	var failure struct {
+		Error   int    `json:"error"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(body, &failure)
+	if failure.Error != 0 {
+		auth := lastFMAuthError(failure.Error) && !(failure.Error == 9 && c.opts.Password != "")
+		return &ServiceError{
+			Service: c.Name(),
+			Status:  resp.StatusCode,
+			Code:    failure.Error,
+			Message: failure.Message,
+			Retry:   !auth && (lastFMRetryable(failure.Error) || resp.StatusCode >= 500),
+			Auth:    auth,
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &ServiceError{Service: c.Name(), Status: resp.StatusCode, Retry: resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests}
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return &ServiceError{Service: c.Name(), Status: resp.StatusCode, Message: err.Error(), Retry: true}
+		}
+	}
+	return nil
+}
+
+func lastFMRetryable(code int) bool {
+	switch code {
+	case 9, 11, 16, 29:
+		return true
+	}
+	return false
+}
+
+func lastFMAuthError(code int) bool {
+	switch code {
+	case 4, 9, 10, 14, 26:
+		return true
+	}
+	return false
+}
+
+func (c *LastFM) sign(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if k != "format" && k != "callback" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteString(params.Get(k))
+	}
+	b.WriteString(c.opts.APISecret)
+	sum := md5.Sum([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
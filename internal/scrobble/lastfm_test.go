@@ -0,0 +1,207 @@
+package scrobble
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+type lastFMServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []url.Values
+	reply    func(form url.Values) (int, string)
+}
+
+func newLastFMServer(t *testing.T, reply func(form url.Values) (int, string)) *lastFMServer {
+	t.Helper()
+	s := &lastFMServer{reply: reply}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+			t.Errorf("%s with content type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, r.PostForm)
+		s.mu.Unlock()
+
+		status, body := s.reply(r.PostForm)
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *lastFMServer) last() url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[len(s.requests)-1]
+}
+
+func md5hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLastFMSignsNowPlaying(t *testing.T) {
+	srv := newLastFMServer(t, func(url.Values) (int, string) { return 200, `{"nowplaying":{}}` })
+	c, err := NewLastFM(LastFMOptions{APIKey: "key", APISecret: "secret", SessionKey: "SK", BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.NowPlaying(context.Background(), Listen{Artist: "A", Track: "T"}); err != nil {
+		t.Fatal(err)
+	}
+	form := srv.last()
+	if form.Get("method") != "track.updateNowPlaying" || form.Get("format") != "json" || form.Get("sk") != "SK" {
+		t.Fatalf("form = %v", form)
+	}
+	want := md5hex("api_keykeyartistAmethodtrack.updateNowPlayingskSKtrackTsecret")
+	if got := form.Get("api_sig"); got != want {
+		t.Fatalf("api_sig = %s, want %s", got, want)
+	}
+}
+
+func TestLastFMSubmitBatch(t *testing.T) {
+	srv := newLastFMServer(t, func(url.Values) (int, string) { return 200, `{"scrobbles":{}}` })
+	c, err := NewLastFM(LastFMOptions{APIKey: "key", APISecret: "secret", SessionKey: "SK", BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Unix(1700000000, 0)
+	err = c.Submit(context.Background(), []Listen{
+		{Artist: "A", Track: "One", Duration: 200, ListenedAt: at},
+		{Artist: "B", Track: "Two", ListenedAt: at.Add(time.Minute)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := srv.last()
+	for key, want := range map[string]string{
+		"method":       "track.scrobble",
+		"artist[0]":    "A",
+		"track[0]":     "One",
+		"timestamp[0]": "1700000000",
+		"duration[0]":  "200",
+		"artist[1]":    "B",
+		"track[1]":     "Two",
+		"timestamp[1]": "1700000060",
+	} {
+		if got := form.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	if form.Has("duration[1]") {
+		t.Error("duration sent for a listen without one")
+	}
+
+	sig := form.Get("api_sig")
+	form.Del("api_sig")
+	if want := c.sign(form); sig != want {
+		t.Fatalf("api_sig = %s, want %s", sig, want)
+	}
+}
+
+func TestLastFMMobileSession(t *testing.T) {
+	sessions := 0
+	srv := newLastFMServer(t, func(form url.Values) (int, string) {
+		switch form.Get("method") {
+		case "auth.getMobileSession":
+			sessions++
+			if form.Get("username") != "user" || form.Get("password") != "pass" {
+				return 403, `{"error":4,"message":"Authentication Failed"}`
+			}
+			return 200, fmt.Sprintf(`{"session":{"name":"user","key":"SK%d"}}`, sessions)
+		default:
+			if form.Get("sk") == "SK1" {
+				return 403, `{"error":9,"message":"Invalid session key"}`
+			}
+			return 200, `{}`
+		}
+	})
+	c, err := NewLastFM(LastFMOptions{APIKey: "key", APISecret: "secret", Username: "user", Password: "pass", BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.NowPlaying(context.Background(), Listen{Artist: "A", Track: "T"})
+	if !Retryable(err) || AuthFailed(err) {
+		t.Fatalf("expired session error = %v, want retryable", err)
+	}
+	if err := c.NowPlaying(context.Background(), Listen{Artist: "A", Track: "T"}); err != nil {
+		t.Fatalf("after a new session: %v", err)
+	}
+	if sessions != 2 || srv.last().Get("sk") != "SK2" {
+		t.Fatalf("sessions = %d, sk = %s", sessions, srv.last().Get("sk"))
+	}
+}
+
+func TestLastFMErrorClassification(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		password    bool
+		retry, auth bool
+	}{
+		{"authentication failed", 403, `{"error":4,"message":"Authentication Failed"}`, false, false, true},
+		{"invalid api key", 403, `{"error":10,"message":"Invalid API key"}`, false, false, true},
+		{"suspended api key", 403, `{"error":26,"message":"Suspended API key"}`, false, false, true},
+		{"invalid session key", 403, `{"error":9,"message":"Invalid session key"}`, false, false, true},
+		{"invalid session key with password", 403, `{"error":9,"message":"Invalid session key"}`, true, true, false},
+		{"service offline", 503, `{"error":11,"message":"Service Offline"}`, false, true, false},
+		{"temporary error", 500, `{"error":16,"message":"Temporary error"}`, false, true, false},
+		{"rate limit", 429, `{"error":29,"message":"Rate limit exceeded"}`, false, true, false},
+		{"invalid parameters", 400, `{"error":6,"message":"Invalid parameters"}`, false, false, false},
+		{"bad gateway", 502, `<html>bad gateway</html>`, false, true, false},
+		{"not found", 404, ``, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newLastFMServer(t, func(url.Values) (int, string) { return tt.status, tt.body })
+			opts := LastFMOptions{APIKey: "key", APISecret: "secret", SessionKey: "SK", BaseURL: srv.URL}
+			if tt.password {
+				opts.Username, opts.Password = "user", "pass"
+			}
+			c, err := NewLastFM(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = c.Submit(context.Background(), []Listen{{Artist: "A", Track: "T", ListenedAt: time.Now()}})
+			if err == nil {
+				t.Fatal("Submit succeeded")
+			}
+			if Retryable(err) != tt.retry || AuthFailed(err) != tt.auth {
+				t.Fatalf("%v: retry=%v auth=%v, want retry=%v auth=%v", err, Retryable(err), AuthFailed(err), tt.retry, tt.auth)
+			}
+		})
+	}
+}
+
+func TestNewLastFMRequiresCredentials(t *testing.T) {
+	for _, opts := range []LastFMOptions{
+		{APISecret: "s", SessionKey: "k"},
+		{APIKey: "k", SessionKey: "k"},
+		{APIKey: "k", APISecret: "s"},
+		{APIKey: "k", APISecret: "s", Username: "u"},
+	} {
+		if _, err := NewLastFM(opts); err == nil {
+			t.Errorf("NewLastFM(%+v) accepted incomplete credentials", opts)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package scrobble
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const DefaultListenBrainzURL = "https://api.listenbrainz.org"
+
+type ListenBrainzOptions struct {
+	Token      string
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+type ListenBrainz struct {
+	opts ListenBrainzOptions
+	http *http.Client
+}
+
+type lbPayload struct {
+	ListenedAt    int64      `json:"listened_at,omitempty"`
+	TrackMetadata lbMetadata `json:"track_metadata"`
+}
+
+type lbMetadata struct {
+	ArtistName     string         `json:"artist_name"`
+	TrackName      string         `json:"track_name"`
+	AdditionalInfo map[string]any `json:"additional_info,omitempty"`
+}
+
+func NewListenBrainz(opts ListenBrainzOptions) (*ListenBrainz, error) {
+	if opts.Token == "" {
+		return nil, errors.New("listenbrainz: token is required")
+	}
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultListenBrainzURL
+	}
+	opts.BaseURL = strings.TrimRight(opts.BaseURL, "/")
+	return &ListenBrainz{opts: opts, http: newHTTPClient(opts.HTTPClient)}, nil
+}
+
+func (c *ListenBrainz) Name() string { return "listenbrainz" }
+
+func (c *ListenBrainz) BatchSize() int { return 100 }
+
+func (c *ListenBrainz) NowPlaying(ctx context.Context, l Listen) error {
+	p := lbListen(l)
+	p.ListenedAt = 0
+	return c.submit(ctx, "playing_now", []lbPayload{p})
+}
+
+func (c *ListenBrainz) Submit(ctx context.Context, listens []Listen) error {
+	payload := make([]lbPayload, len(listens))
+	for i, l := range listens {
+		payload[i] = lbListen(l)
+	}
+	kind := "import"
+	if len(payload) == 1 {
+		kind = "single"
+	}
+	return c.submit(ctx, kind, payload)
+}
+
+func lbListen(l Listen) lbPayload {
+	info := map[string]any{"media_player": "youtui-player", "submission_client": "youtui-player"}
+	if l.Duration > 0 {
+		info["duration"] = l.Duration
+	}
+	if l.URL != "" {
+		info["origin_url"] = l.URL
+	}
+	return lbPayload{
+		ListenedAt: l.ListenedAt.Unix(),
+		TrackMetadata: lbMetadata{
+			ArtistName:     l.Artist,
+			TrackName:      l.Track,
+			AdditionalInfo: info,
+		},
+	}
+}
+
+func (c *ListenBrainz) submit(ctx context.Context, kind string, payload []lbPayload) error {
+	body, err := json.Marshal(struct {
+		ListenType string      `json:"listen_type"`
+		Payload    []lbPayload `json:"payload"`
+	}{kind, payload})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.opts.BaseURL+"/1/submit-listens", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Token "+c.opts.Token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode == http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+		return nil
+	}
+
+	var failure struct {
+		Error string `json:"error"`
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	_ = json.Unmarshal(raw, &failure)
+	return &ServiceError{
+		Service: c.Name(),
+		Status:  resp.StatusCode,
+		Message: failure.Error,
+		Retry:   resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests,
+		Auth:    resp.StatusCode == http.StatusUnauthorized,
+	}
+}
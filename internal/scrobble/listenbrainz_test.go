@@ -0,0 +1,120 @@
+package scrobble
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type lbRequest struct {
+	ListenType string `json:"listen_type"`
+	Payload    []struct {
+		ListenedAt    *int64 `json:"listened_at"`
+		TrackMetadata struct {
+			ArtistName     string         `json:"artist_name"`
+			TrackName      string         `json:"track_name"`
+			AdditionalInfo map[string]any `json:"additional_info"`
+		} `json:"track_metadata"`
+	} `json:"payload"`
+}
+
+func newListenBrainzServer(t *testing.T, status int, body string) (*ListenBrainz, chan lbRequest) {
+	t.Helper()
+	requests := make(chan lbRequest, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1/submit-listens" || r.Method != http.MethodPost {
+			t.Errorf("%s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Token tok" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req lbRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		requests <- req
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewListenBrainz(ListenBrainzOptions{Token: "tok", BaseURL: srv.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, requests
+}
+
+func TestListenBrainzSubmit(t *testing.T) {
+	c, requests := newListenBrainzServer(t, 200, `{"status":"ok"}`)
+	at := time.Unix(1700000000, 0)
+
+	err := c.Submit(context.Background(), []Listen{{
+		Artist: "Rick Astley", Track: "Never Gonna Give You Up", Duration: 213,
+		ListenedAt: at, URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := <-requests
+	if req.ListenType != "single" || len(req.Payload) != 1 {
+		t.Fatalf("request = %+v", req)
+	}
+	p := req.Payload[0]
+	if p.ListenedAt == nil || *p.ListenedAt != 1700000000 {
+		t.Fatalf("listened_at = %v", p.ListenedAt)
+	}
+	if p.TrackMetadata.ArtistName != "Rick Astley" || p.TrackMetadata.TrackName != "Never Gonna Give You Up" {
+		t.Fatalf("track_metadata = %+v", p.TrackMetadata)
+	}
+	info := p.TrackMetadata.AdditionalInfo
+	if info["duration"] != float64(213) || info["origin_url"] != "https://www.youtube.com/watch?v=dQw4w9WgXcQ" || info["media_player"] != "youtui-player" {
+		t.Fatalf("additional_info = %v", info)
+	}
+
+	if err := c.Submit(context.Background(), []Listen{{Artist: "A", Track: "1", ListenedAt: at}, {Artist: "B", Track: "2", ListenedAt: at}}); err != nil {
+		t.Fatal(err)
+	}
+	if req := <-requests; req.ListenType != "import" || len(req.Payload) != 2 {
+		t.Fatalf("batch request = %+v", req)
+	}
+}
+
+func TestListenBrainzNowPlaying(t *testing.T) {
+	c, requests := newListenBrainzServer(t, 200, `{"status":"ok"}`)
+	if err := c.NowPlaying(context.Background(), Listen{Artist: "A", Track: "T", ListenedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	req := <-requests
+	if req.ListenType != "playing_now" || req.Payload[0].ListenedAt != nil {
+		t.Fatalf("playing_now request = %+v", req)
+	}
+}
+
+func TestListenBrainzErrorClassification(t *testing.T) {
+	tests := []struct {
+		status      int
+		body        string
+		retry, auth bool
+	}{
+		{401, `{"code":401,"error":"You need to provide an Authorization header."}`, false, true},
+		{429, ``, true, false},
+		{500, `{"error":"internal"}`, true, false},
+		{503, ``, true, false},
+		{400, `{"code":400,"error":"JSON document may only contain 1 listen"}`, false, false},
+	}
+	for _, tt := range tests {
+		c, _ := newListenBrainzServer(t, tt.status, tt.body)
+		err := c.Submit(context.Background(), []Listen{{Artist: "A", Track: "T", ListenedAt: time.Now()}})
+		if err == nil {
+			t.Fatalf("status %d: Submit succeeded", tt.status)
+		}
+		if Retryable(err) != tt.retry || AuthFailed(err) != tt.auth {
+			t.Errorf("status %d: %v: retry=%v auth=%v, want retry=%v auth=%v",
+				tt.status, err, Retryable(err), AuthFailed(err), tt.retry, tt.auth)
+		}
+	}
+}
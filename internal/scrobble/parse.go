@@ -0,0 +1,48 @@
+package scrobble
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	titleNoise = regexp.MustCompile(`(?i)\s*[(\[][^)\]]*\b(official|lyrics?|audio|video|visuali[sz]er|hd|hq|4k|mv|m/v|oficial|clipe|letra)\b[^)\]]*[)\]]`)
+	titleSplit = regexp.MustCompile(`\s+[-–—~]\s+`)
+	authorTail = regexp.MustCompile(`(?i)(\s+-\s+topic|vevo|\s+official)$`)
+)
+
+func Parse(title, author string) (artist, track string) {
+	clean := titleNoise.ReplaceAllString(title, "")
+	if before, _, ok := strings.Cut(clean, " | "); ok {
+		clean = before
+	}
+	clean = strings.TrimSpace(clean)
+
+	if parts := titleSplit.Split(clean, 2); len(parts) == 2 {
+		artist, track = strings.TrimSpace(parts[0]), unquote(parts[1])
+		if artist != "" && track != "" {
+			return artist, track
+		}
+	}
+
+	artist = strings.TrimSpace(authorTail.ReplaceAllString(strings.TrimSpace(author), ""))
+	track = unquote(clean)
+	if track == "" {
+		track = strings.TrimSpace(title)
+	}
+	return artist, track
+}
+
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	for _, q := range []string{`"`, `'`, "“”", "‘’"} {
+		open, close := q, q
+		if r := []rune(q); len(r) == 2 {
+			open, close = string(r[0]), string(r[1])
+		}
+		if strings.HasPrefix(s, open) && strings.HasSuffix(s, close) && len(s) > len(open)+len(close) {
+			return strings.TrimSpace(s[len(open) : len(s)-len(close)])
+		}
+	}
+	return s
+}
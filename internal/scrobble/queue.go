@@ -0,0 +1,261 @@
+package scrobble
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/atomicfile"
+)
+
+const (
+	maxQueued  = 1000
+	minBackoff = 30 * time.Second
+	maxBackoff = 30 * time.Minute
+)
+
+type Options struct {
+	QueuePath string
+	OnError   func(err error)
+}
+
+type Scrobbler struct {
+	clients []Client
+	opts    Options
+	wake    chan struct{}
+	ctx     context.Context
+	stop    context.CancelFunc
+	done    chan struct{}
+
+	mu      sync.Mutex
+	pending map[string][]Listen
+	parked  map[string]error
+}
+
+func New(clients []Client, opts Options) (*Scrobbler, error) {
+	if len(clients) == 0 {
+		return nil, errors.New("no scrobbling service configured")
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	s := &Scrobbler{
+		clients: clients,
+		opts:    opts,
+		wake:    make(chan struct{}, 1),
+		ctx:     ctx,
+		stop:    stop,
+		done:    make(chan struct{}),
+		pending: map[string][]Listen{},
+		parked:  map[string]error{},
+	}
+	if err := s.load(); err != nil {
+		stop()
+		return nil, err
+	}
+	go s.loop()
+	s.signal()
+	return s, nil
+}
+
+func (s *Scrobbler) Close() {
+	s.stop()
+	<-s.done
+}
+
+func (s *Scrobbler) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, c := range s.clients {
+		n += len(s.pending[c.Name()])
+	}
+	return n
+}
+
+func (s *Scrobbler) NowPlaying(l Listen) {
+	for _, c := range s.clients {
+		if s.isParked(c) {
+			continue
+		}
+		go func(c Client) {
+			ctx, cancel := context.WithTimeout(s.ctx, httpTimeout)
+			defer cancel()
+			err := c.NowPlaying(ctx, l)
+			switch {
+			case AuthFailed(err):
+				s.park(c, err)
+			case err != nil && !Retryable(err):
+				s.report(err)
+			}
+		}(c)
+	}
+}
+
+func (s *Scrobbler) isParked(c Client) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.parked[c.Name()] != nil
+}
+
+func (s *Scrobbler) park(c Client, err error) {
+	s.mu.Lock()
+	first := s.parked[c.Name()] == nil
+	s.parked[c.Name()] = err
+	s.mu.Unlock()
+	if first {
+		s.report(err)
+	}
+}
+
+func (s *Scrobbler) Add(l Listen) {
+	s.mu.Lock()
+	for _, c := range s.clients {
+		queue := append(s.pending[c.Name()], l)
+		if len(queue) > maxQueued {
+			queue = queue[len(queue)-maxQueued:]
+		}
+		s.pending[c.Name()] = queue
+	}
+	err := s.saveLocked()
+	s.mu.Unlock()
+	if err != nil {
+		s.report(err)
+	}
+	s.signal()
+}
+
+func (s *Scrobbler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scrobbler) report(err error) {
+	if s.opts.OnError != nil && s.ctx.Err() == nil {
+		s.opts.OnError(err)
+	}
+}
+
+func (s *Scrobbler) loop() {
+	defer close(s.done)
+	backoff := minBackoff
+	retry := time.NewTimer(time.Hour)
+	retry.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			retry.Stop()
+			return
+		case <-s.wake:
+		case <-retry.C:
+		}
+
+		if s.flush() {
+			backoff = minBackoff
+			retry.Stop()
+			continue
+		}
+		retry.Reset(backoff)
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+func (s *Scrobbler) flush() bool {
+	ok := true
+	for _, c := range s.clients {
+		if !s.flushClient(c) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+func (s *Scrobbler) flushClient(c Client) bool {
+	for s.ctx.Err() == nil {
+		s.mu.Lock()
+		if s.parked[c.Name()] != nil {
+			s.mu.Unlock()
+			return true
+		}
+		batch := s.pending[c.Name()]
+		if len(batch) > c.BatchSize() {
+			batch = batch[:c.BatchSize()]
+		}
+		batch = append([]Listen(nil), batch...)
+		s.mu.Unlock()
+		if len(batch) == 0 {
+			return true
+		}
+
+		ctx, cancel := context.WithTimeout(s.ctx, httpTimeout)
+		err := c.Submit(ctx, batch)
+		cancel()
+		if AuthFailed(err) {
+			s.park(c, err)
+			return true
+		}
+		if err != nil && Retryable(err) {
+			return false
+		}
+		if err != nil {
+			s.report(err)
+		}
+
+		s.mu.Lock()
+		queue := s.pending[c.Name()]
+		s.pending[c.Name()] = queue[min(len(batch), len(queue)):]
+		saveErr := s.saveLocked()
+		s.mu.Unlock()
+		if saveErr != nil {
+			s.report(saveErr)
+		}
+	}
+	return false
+}
+
+func (s *Scrobbler) load() error {
+	if s.opts.QueuePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.opts.QueuePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, &s.pending)
+}
+
+func (s *Scrobbler) saveLocked() error {
+	if s.opts.QueuePath == "" {
+		return nil
+	}
+	for name, queue := range s.pending {
+		if len(queue) == 0 {
+			delete(s.pending, name)
+		}
+	}
+	if len(s.pending) == 0 {
+		err := os.Remove(s.opts.QueuePath)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	data, err := json.MarshalIndent(s.pending, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.opts.QueuePath), 0o755); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.opts.QueuePath, data, 0o600)
+}
@@ -0,0 +1,190 @@
+package scrobble
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeClient struct {
+	name  string
+	batch int
+
+	mu      sync.Mutex
+	fail    error
+	batches [][]Listen
+	playing []Listen
+}
+
+func (c *fakeClient) Name() string   { return c.name }
+func (c *fakeClient) BatchSize() int { return c.batch }
+
+func (c *fakeClient) NowPlaying(_ context.Context, l Listen) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fail != nil {
+		return c.fail
+	}
+	c.playing = append(c.playing, l)
+	return nil
+}
+
+func (c *fakeClient) Submit(_ context.Context, listens []Listen) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batches = append(c.batches, listens)
+	return c.fail
+}
+
+func (c *fakeClient) submitted() [][]Listen {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]Listen(nil), c.batches...)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func listen(track string) Listen {
+	return Listen{Artist: "Band", Track: track, ListenedAt: time.Unix(1700000000, 0)}
+}
+
+func TestScrobblerReplaysOfflineQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scrobble_queue.json")
+
+	offline := &fakeClient{name: "lastfm", batch: 50, fail: errors.New("dial tcp: network is unreachable")}
+	s, err := New([]Client{offline}, Options{QueuePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Add(listen("One"))
+	s.Add(listen("Two"))
+	waitFor(t, "a failed attempt", func() bool { return len(offline.submitted()) > 0 })
+	s.Close()
+
+	if s.Pending() != 2 {
+		t.Fatalf("Pending() = %d, want 2", s.Pending())
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("queue not saved: %v", err)
+	}
+
+	online := &fakeClient{name: "lastfm", batch: 50}
+	s, err = New([]Client{online}, Options{QueuePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	waitFor(t, "the queue to drain", func() bool { return s.Pending() == 0 })
+
+	batches := online.submitted()
+	if len(batches) != 1 || len(batches[0]) != 2 || batches[0][0].Track != "One" || batches[0][1].Track != "Two" {
+		t.Fatalf("replayed batches = %+v", batches)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("empty queue file left behind: %v", err)
+	}
+}
+
+func TestScrobblerBatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scrobble_queue.json")
+	offline := &fakeClient{name: "listenbrainz", batch: 2, fail: errors.New("offline")}
+	s, err := New([]Client{offline}, Options{QueuePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, track := range []string{"1", "2", "3", "4", "5"} {
+		s.Add(listen(track))
+	}
+	s.Close()
+
+	online := &fakeClient{name: "listenbrainz", batch: 2}
+	s, err = New([]Client{online}, Options{QueuePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	waitFor(t, "the queue to drain", func() bool { return s.Pending() == 0 })
+
+	var sizes []int
+	for _, b := range online.submitted() {
+		sizes = append(sizes, len(b))
+	}
+	if len(sizes) != 3 || sizes[0] != 2 || sizes[1] != 2 || sizes[2] != 1 {
+		t.Fatalf("batch sizes = %v, want [2 2 1]", sizes)
+	}
+}
+
+func TestScrobblerDropsRejectedListens(t *testing.T) {
+	c := &fakeClient{name: "lastfm", batch: 50, fail: &ServiceError{Service: "lastfm", Code: 6, Message: "Invalid parameters"}}
+	var mu sync.Mutex
+	var reported []error
+	s, err := New([]Client{c}, Options{OnError: func(err error) {
+		mu.Lock()
+		reported = append(reported, err)
+		mu.Unlock()
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.Add(listen("Bad"))
+	waitFor(t, "the listen to be dropped", func() bool { return s.Pending() == 0 && len(c.submitted()) == 1 })
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 {
+		t.Fatalf("reported %d errors, want 1", len(reported))
+	}
+}
+
+func TestScrobblerParksOnAuthFailure(t *testing.T) {
+	authErr := &ServiceError{Service: "listenbrainz", Status: 401, Message: "Invalid token", Auth: true}
+	bad := &fakeClient{name: "listenbrainz", batch: 100, fail: authErr}
+	good := &fakeClient{name: "lastfm", batch: 50}
+
+	var mu sync.Mutex
+	var reported []error
+	s, err := New([]Client{bad, good}, Options{OnError: func(err error) {
+		mu.Lock()
+		reported = append(reported, err)
+		mu.Unlock()
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.Add(listen("One"))
+	waitFor(t, "the good service to get the listen", func() bool { return len(good.submitted()) == 1 })
+	waitFor(t, "the bad service to be tried", func() bool { return len(bad.submitted()) == 1 })
+
+	s.Add(listen("Two"))
+	s.NowPlaying(listen("Three"))
+	waitFor(t, "the second listen", func() bool { return len(good.submitted()) == 2 })
+	time.Sleep(50 * time.Millisecond)
+
+	if n := len(bad.submitted()); n != 1 {
+		t.Fatalf("parked service got %d submissions, want 1", n)
+	}
+	if s.Pending() != 2 {
+		t.Fatalf("Pending() = %d, want the 2 listens kept for the parked service", s.Pending())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 || !AuthFailed(reported[0]) {
+		t.Fatalf("reported %v, want the auth error once", reported)
+	}
+}
@@ -0,0 +1,82 @@
+// Package scrobble submits listening history to Last.fm and ListenBrainz
+package scrobble
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	minDuration  = 30 * time.Second
+	maxThreshold = 4 * time.Minute
+	httpTimeout  = 15 * time.Second
+)
+
+type Listen struct {
+	Artist     string    `json:"artist"`
+	Track      string    `json:"track"`
+	Duration   int       `json:"duration,omitempty"`
+	ListenedAt time.Time `json:"listened_at"`
+	URL        string    `json:"url,omitempty"`
+}
+
+type Client interface {
+	Name() string
+	NowPlaying(ctx context.Context, l Listen) error
+	Submit(ctx context.Context, listens []Listen) error
+	BatchSize() int
+}
+
+type ServiceError struct {
+	Service string
+	Status  int
+	Code    int
+	Message string
+	Retry   bool
+	Auth    bool
+}
+
+func (e *ServiceError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.Status)
+	}
+	if e.Code != 0 {
+		return fmt.Sprintf("%s: error %d: %s", e.Service, e.Code, msg)
+	}
+	return fmt.Sprintf("%s: http status %d: %s", e.Service, e.Status, msg)
+}
+
+func Retryable(err error) bool {
+	var svc *ServiceError
+	if errors.As(err, &svc) {
+		return svc.Retry
+	}
+	return err != nil
+}
+
+func AuthFailed(err error) bool {
+	var svc *ServiceError
+	return errors.As(err, &svc) && svc.Auth
+}
+
+func Eligible(listened, duration time.Duration) bool {
+	if duration > 0 && duration <= minDuration {
+		return false
+	}
+	threshold := maxThreshold
+	if duration > 0 && duration/2 < threshold {
+		threshold = duration / 2
+	}
+	return listened >= threshold
+}
+
+func newHTTPClient(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+	return &http.Client{Timeout: httpTimeout}
+}
@@ -0,0 +1,71 @@
+package scrobble
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEligible(t *testing.T) {
+	tests := []struct {
+		listened, duration time.Duration
+		want               bool
+	}{
+		{30 * time.Second, 30 * time.Second, false},
+		{20 * time.Second, 20 * time.Second, false},
+		{15500 * time.Millisecond, 31 * time.Second, true},
+		{15 * time.Second, 31 * time.Second, false},
+		{100 * time.Second, 200 * time.Second, true},
+		{99 * time.Second, 200 * time.Second, false},
+		{4 * time.Minute, 10 * time.Minute, true},
+		{4*time.Minute - time.Second, 10 * time.Minute, false},
+		{4 * time.Minute, 0, true},
+		{3 * time.Minute, 0, false},
+		{0, 0, false},
+	}
+	for _, tt := range tests {
+		if got := Eligible(tt.listened, tt.duration); got != tt.want {
+			t.Errorf("Eligible(%s, %s) = %v, want %v", tt.listened, tt.duration, got, tt.want)
+		}
+	}
+}
+
+func TestRetryableAndAuthFailed(t *testing.T) {
+	tests := []struct {
+		err         error
+		retry, auth bool
+	}{
+		{nil, false, false},
+		{errors.New("dial tcp: connection refused"), true, false},
+		{&ServiceError{Service: "lastfm", Code: 11, Retry: true}, true, false},
+		{&ServiceError{Service: "lastfm", Code: 6}, false, false},
+		{&ServiceError{Service: "listenbrainz", Status: 401, Auth: true}, false, true},
+	}
+	for _, tt := range tests {
+		if got := Retryable(tt.err); got != tt.retry {
+			t.Errorf("Retryable(%v) = %v, want %v", tt.err, got, tt.retry)
+		}
+		if got := AuthFailed(tt.err); got != tt.auth {
+			t.Errorf("AuthFailed(%v) = %v, want %v", tt.err, got, tt.auth)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		title, author string
+		artist, track string
+	}{
+		{"Rick Astley - Never Gonna Give You Up (Official Music Video)", "Rick Astley", "Rick Astley", "Never Gonna Give You Up"},
+		{"Daft Punk – \"Get Lucky\" [Lyrics]", "Lyrics Channel", "Daft Punk", "Get Lucky"},
+		{"Bohemian Rhapsody (Remastered 2011)", "Queen - Topic", "Queen", "Bohemian Rhapsody (Remastered 2011)"},
+		{"Levitating | Live", "DuaLipaVEVO", "DuaLipa", "Levitating"},
+		{"(Official Video)", "Someone", "Someone", "(Official Video)"},
+	}
+	for _, tt := range tests {
+		artist, track := Parse(tt.title, tt.author)
+		if artist != tt.artist || track != tt.track {
+			t.Errorf("Parse(%q, %q) = %q, %q; want %q, %q", tt.title, tt.author, artist, track, tt.artist, tt.track)
+		}
+	}
+}
@@ -29,7 +29,7 @@ type Result struct {
 	Description string
 }
 
-func humanDuration(sec int) string {
+func HumanDuration(sec int) string {
 	if sec <= 0 {
 		return ""
 	}
@@ -115,7 +115,7 @@ func SearchVideos(ctx context.Context, q string, limit int) ([]Result, error) {
 
 		dur := ""
 		if it.Duration > 0 {
-			dur = humanDuration(int(it.Duration))
+			dur = HumanDuration(int(it.Duration))
 		}
 
 		thumb := ""
@@ -214,7 +214,7 @@ func GetPlaylistVideos(ctx context.Context, url string, limit int) ([]Result, er
 
 		dur := ""
 		if it.Duration > 0 {
-			dur = humanDuration(int(it.Duration))
+			dur = HumanDuration(int(it.Duration))
 		}
 
 		thumb := ""
@@ -282,7 +282,7 @@ func GetVideoDetails(ctx context.Context, url string) (*Result, error) {
 
 	dur := ""
 	if it.Duration > 0 {
-		dur = humanDuration(int(it.Duration))
+		dur = HumanDuration(int(it.Duration))
 	}
 
 	thumb := ""
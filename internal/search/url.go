@@ -0,0 +1,44 @@
+package search
+
+import (
+	"net/url"
+	"strings"
+)
+
+func IsYouTubeURL(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.Contains(s, "youtube.com/watch") ||
+		strings.Contains(s, "youtu.be/") ||
+		strings.Contains(s, "youtube.com/shorts/") ||
+		strings.Contains(s, "youtube.com/playlist") ||
+		strings.Contains(s, "music.youtube.com/watch")
+}
+
+func IsPlaylistURL(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.Contains(s, "youtube.com/playlist?list=") ||
+		(IsYouTubeURL(s) && strings.Contains(s, "&list="))
+}
+
+func VideoID(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	switch {
+	case host == "youtu.be":
+		return strings.Trim(u.Path, "/")
+	case strings.HasSuffix(host, "youtube.com"):
+		if v := u.Query().Get("v"); v != "" {
+			return v
+		}
+		for _, prefix := range []string{"/shorts/", "/embed/", "/live/"} {
+			if id, ok := strings.CutPrefix(u.Path, prefix); ok {
+				return strings.Trim(id, "/")
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,279 @@
+// Package smart
+package smart
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/history"
+	"github.com/IvelOt/youtui-player/internal/library"
+	"github.com/IvelOt/youtui-player/internal/search"
+)
+
+type Rule struct {
+	Name             string `toml:"name"`
+	Source           string `toml:"source,omitempty"`
+	Favorite         bool   `toml:"favorite,omitempty"`
+	MinStars         int    `toml:"min_stars,omitempty"`
+	Author           string `toml:"author,omitempty"`
+	Title            string `toml:"title,omitempty"`
+	MinPlays         int    `toml:"min_plays,omitempty"`
+	MaxPlays         *int   `toml:"max_plays,omitempty"`
+	NeverFinished    bool   `toml:"never_finished,omitempty"`
+	AddedWithinDays  int    `toml:"added_within_days,omitempty"`
+	PlayedWithinDays int    `toml:"played_within_days,omitempty"`
+	MinDuration      string `toml:"min_duration,omitempty"`
+	MaxDuration      string `toml:"max_duration,omitempty"`
+	Sort             string `toml:"sort,omitempty"`
+	Limit            int    `toml:"limit,omitempty"`
+
+	minDuration float64
+	maxDuration float64
+}
+
+type file struct {
+	Playlists []Rule `toml:"playlist"`
+}
+
+type Candidate struct {
+	Title       string
+	Author      string
+	URL         string
+	Thumbnail   string
+	Duration    string
+	Seconds     float64
+	Favorite    bool
+	Stars       int
+	Plays       int
+	Completions int
+	LastPlayed  time.Time
+	Added       time.Time
+	InLibrary   bool
+}
+
+func Load(path string) ([]Rule, error) {
+	var f file
+	if _, err := toml.DecodeFile(path, &f); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rules := f.Playlists[:0]
+	for i, r := range f.Playlists {
+		r.Name = strings.TrimSpace(r.Name)
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("Smart #%d", i+1)
+		}
+		var err error
+		if r.minDuration, err = parseDuration(r.MinDuration); err != nil {
+			return nil, fmt.Errorf("%s: min_duration: %w", r.Name, err)
+		}
+		if r.maxDuration, err = parseDuration(r.MaxDuration); err != nil {
+			return nil, fmt.Errorf("%s: max_duration: %w", r.Name, err)
+		}
+		switch r.Source {
+		case "", "all", "library", "history", "rated":
+		default:
+			return nil, fmt.Errorf("%s: unknown source %q", r.Name, r.Source)
+		}
+		switch r.Sort {
+		case "", "plays", "rating", "recent", "added", "title", "author":
+		default:
+			return nil, fmt.Errorf("%s: unknown sort %q", r.Name, r.Sort)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func parseDuration(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d.Seconds(), nil
+	}
+	total := 0
+	for _, p := range strings.Split(s, ":") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		total = total*60 + n
+	}
+	return float64(total), nil
+}
+
+func Collect(entries []history.Entry, ratings []library.Rating, files []library.Entry, playlist []engine.Track) []*Candidate {
+	byKey := map[string]*Candidate{}
+	var order []string
+	get := func(url string) *Candidate {
+		key := library.RatingKey(url)
+		c := byKey[key]
+		if c == nil {
+			c = &Candidate{URL: url}
+			byKey[key] = c
+			order = append(order, key)
+		}
+		return c
+	}
+	fill := func(c *Candidate, title, author, thumb, duration string) {
+		if c.Title == "" {
+			c.Title = title
+		}
+		if c.Author == "" {
+			c.Author = author
+		}
+		if c.Thumbnail == "" {
+			c.Thumbnail = thumb
+		}
+		if c.Duration == "" {
+			c.Duration = duration
+		}
+	}
+	added := func(c *Candidate, t time.Time) {
+		if !t.IsZero() && (c.Added.IsZero() || t.Before(c.Added)) {
+			c.Added = t
+		}
+	}
+
+	for _, f := range files {
+		c := get(library.FileURL(f.Path))
+		thumb := ""
+		if f.Cover {
+			thumb = c.URL
+		}
+		fill(c, f.Title, f.Artist, thumb, search.HumanDuration(int(f.Duration+0.5)))
+		c.Seconds = f.Duration
+		c.InLibrary = true
+		added(c, time.Unix(f.ModTime, 0))
+	}
+
+	for _, e := range entries {
+		c := get(e.URL)
+		fill(c, e.Title, e.Author, e.Thumbnail, e.Duration)
+		c.Plays++
+		if e.Completed {
+			c.Completions++
+		}
+		if e.StartedAt.After(c.LastPlayed) {
+			c.LastPlayed = e.StartedAt
+		}
+	}
+
+	for _, r := range ratings {
+		c := get(r.URL)
+		fill(c, r.Title, r.Author, r.Thumbnail, r.Duration)
+		c.Favorite = r.Favorite
+		c.Stars = r.Stars
+	}
+
+	for _, t := range playlist {
+		c := get(t.URL)
+		fill(c, t.Title, t.Author, t.Thumbnail, t.Duration)
+		added(c, t.AddedAt)
+	}
+
+	list := make([]*Candidate, 0, len(order))
+	for _, key := range order {
+		c := byKey[key]
+		if c.Seconds == 0 {
+			c.Seconds, _ = parseDuration(c.Duration)
+		}
+		list = append(list, c)
+	}
+	return list
+}
+
+func (r Rule) Match(c *Candidate, now time.Time) bool {
+	switch r.Source {
+	case "library":
+		if !c.InLibrary {
+			return false
+		}
+	case "history":
+		if c.Plays == 0 {
+			return false
+		}
+	case "rated":
+		if !c.Favorite && c.Stars == 0 {
+			return false
+		}
+	}
+
+	switch {
+	case r.Favorite && !c.Favorite:
+		return false
+	case r.MinStars > 0 && c.Stars < r.MinStars:
+		return false
+	case r.Author != "" && !strings.Contains(strings.ToLower(c.Author), strings.ToLower(r.Author)):
+		return false
+	case r.Title != "" && !strings.Contains(strings.ToLower(c.Title), strings.ToLower(r.Title)):
+		return false
+	case r.MinPlays > 0 && c.Plays < r.MinPlays:
+		return false
+	case r.MaxPlays != nil && c.Plays > *r.MaxPlays:
+		return false
+	case r.NeverFinished && (c.Plays == 0 || c.Completions > 0):
+		return false
+	case r.AddedWithinDays > 0 && (c.Added.IsZero() || now.Sub(c.Added) > days(r.AddedWithinDays)):
+		return false
+	case r.PlayedWithinDays > 0 && (c.LastPlayed.IsZero() || now.Sub(c.LastPlayed) > days(r.PlayedWithinDays)):
+		return false
+	case r.minDuration > 0 && (c.Seconds == 0 || c.Seconds < r.minDuration):
+		return false
+	case r.maxDuration > 0 && (c.Seconds == 0 || c.Seconds > r.maxDuration):
+		return false
+	}
+	return true
+}
+
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
+
+func (r Rule) Evaluate(cands []*Candidate, now time.Time) []*Candidate {
+	var out []*Candidate
+	for _, c := range cands {
+		if r.Match(c, now) {
+			out = append(out, c)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		switch r.Sort {
+		case "plays":
+			return a.Plays > b.Plays
+		case "rating":
+			if a.Stars != b.Stars {
+				return a.Stars > b.Stars
+			}
+			return a.Favorite && !b.Favorite
+		case "recent":
+			return a.LastPlayed.After(b.LastPlayed)
+		case "added":
+			return a.Added.After(b.Added)
+		case "title":
+			return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+		case "author":
+			return strings.ToLower(a.Author) < strings.ToLower(b.Author)
+		}
+		return false
+	})
+
+	if r.Limit > 0 && len(out) > r.Limit {
+		out = out[:r.Limit]
+	}
+	return out
+}
@@ -0,0 +1,210 @@
+package smart
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/history"
+	"github.com/IvelOt/youtui-player/internal/library"
+)
+
+var now = time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+func intPtr(n int) *int { return &n }
+
+func TestRuleMatch(t *testing.T) {
+	c := &Candidate{
+		Title:       "Never Gonna Give You Up",
+		Author:      "Rick Astley",
+		Seconds:     213,
+		Favorite:    true,
+		Stars:       4,
+		Plays:       5,
+		Completions: 1,
+		LastPlayed:  now.Add(-48 * time.Hour),
+		Added:       now.Add(-10 * 24 * time.Hour),
+	}
+
+	tests := []struct {
+		name string
+		rule Rule
+		want bool
+	}{
+		{"empty rule", Rule{}, true},
+		{"favorite", Rule{Favorite: true}, true},
+		{"min stars met", Rule{MinStars: 4}, true},
+		{"min stars missed", Rule{MinStars: 5}, false},
+		{"author substring", Rule{Author: "astley"}, true},
+		{"author mismatch", Rule{Author: "queen"}, false},
+		{"title substring", Rule{Title: "GONNA"}, true},
+		{"min plays met exactly", Rule{MinPlays: 5}, true},
+		{"min plays missed", Rule{MinPlays: 6}, false},
+		{"max plays", Rule{MaxPlays: intPtr(5)}, true},
+		{"max plays exceeded", Rule{MaxPlays: intPtr(4)}, false},
+		{"max plays zero", Rule{MaxPlays: intPtr(0)}, false},
+		{"never finished", Rule{NeverFinished: true}, false},
+		{"added within", Rule{AddedWithinDays: 14}, true},
+		{"added too long ago", Rule{AddedWithinDays: 7}, false},
+		{"played within", Rule{PlayedWithinDays: 3}, true},
+		{"played too long ago", Rule{PlayedWithinDays: 1}, false},
+		{"min duration", Rule{minDuration: 180}, true},
+		{"max duration", Rule{maxDuration: 180}, false},
+		{"source history", Rule{Source: "history"}, true},
+		{"source library", Rule{Source: "library"}, false},
+		{"source rated", Rule{Source: "rated"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Match(c, now); got != tt.want {
+				t.Fatalf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleMatchUnknownValues(t *testing.T) {
+	c := &Candidate{Title: "Unplayed"}
+	for _, rule := range []Rule{
+		{AddedWithinDays: 30},
+		{PlayedWithinDays: 30},
+		{minDuration: 60},
+		{maxDuration: 600},
+		{NeverFinished: true},
+	} {
+		if rule.Match(c, now) {
+			t.Fatalf("%+v matched a candidate without the needed data", rule)
+		}
+	}
+}
+
+func TestEvaluateSortAndLimit(t *testing.T) {
+	cands := []*Candidate{
+		{Title: "b", Plays: 2, Stars: 3},
+		{Title: "a", Plays: 7, Stars: 5},
+		{Title: "c", Plays: 4, Stars: 1},
+		{Title: "d", Plays: 1},
+	}
+
+	tests := []struct {
+		rule Rule
+		want []string
+	}{
+		{Rule{Sort: "plays"}, []string{"a", "c", "b", "d"}},
+		{Rule{Sort: "rating", Limit: 2}, []string{"a", "b"}},
+		{Rule{Sort: "title"}, []string{"a", "b", "c", "d"}},
+		{Rule{MinPlays: 2, Sort: "plays"}, []string{"a", "c", "b"}},
+		{Rule{}, []string{"b", "a", "c", "d"}},
+	}
+	for _, tt := range tests {
+		got := tt.rule.Evaluate(cands, now)
+		var titles []string
+		for _, c := range got {
+			titles = append(titles, c.Title)
+		}
+		if len(titles) != len(tt.want) {
+			t.Fatalf("%+v: got %v, want %v", tt.rule, titles, tt.want)
+		}
+		for i := range titles {
+			if titles[i] != tt.want[i] {
+				t.Fatalf("%+v: got %v, want %v", tt.rule, titles, tt.want)
+			}
+		}
+	}
+}
+
+func TestCollectAddedFromPlaylist(t *testing.T) {
+	const url = "https://www.youtube.com/watch?v=dQw4w9WgXcQ"
+	entries := []history.Entry{
+		{Title: "Never Gonna Give You Up", URL: url, StartedAt: now.Add(-100 * 24 * time.Hour), Completed: true},
+		{Title: "Never Gonna Give You Up", URL: url, StartedAt: now.Add(-time.Hour)},
+	}
+	playlist := []engine.Track{
+		{Title: "Never Gonna Give You Up", URL: url, AddedAt: now.Add(-2 * 24 * time.Hour)},
+		{Title: "Queued only", URL: "https://www.youtube.com/watch?v=aaaaaaaaaaa"},
+	}
+	ratings := []library.Rating{
+		{URL: "https://www.youtube.com/watch?v=bbbbbbbbbbb", Title: "Rated", Stars: 5, UpdatedAt: now},
+	}
+
+	cands := Collect(entries, ratings, nil, playlist)
+	byTitle := map[string]*Candidate{}
+	for _, c := range cands {
+		byTitle[c.Title] = c
+	}
+
+	rick := byTitle["Never Gonna Give You Up"]
+	if rick == nil || rick.Plays != 2 || rick.Completions != 1 {
+		t.Fatalf("history not merged: %+v", rick)
+	}
+	if !rick.Added.Equal(now.Add(-2 * 24 * time.Hour)) {
+		t.Fatalf("Added = %v, want the playlist date", rick.Added)
+	}
+	if !rick.LastPlayed.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("LastPlayed = %v", rick.LastPlayed)
+	}
+	if c := byTitle["Rated"]; c == nil || !c.Added.IsZero() {
+		t.Fatalf("rating date used as added date: %+v", c)
+	}
+	if byTitle["Queued only"] == nil {
+		t.Fatal("playlist track missing from candidates")
+	}
+
+	recent := Rule{AddedWithinDays: 7}.Evaluate(cands, now)
+	if len(recent) != 1 || recent[0] != rick {
+		t.Fatalf("added_within_days matched %d tracks", len(recent))
+	}
+}
+
+func TestCollectLibraryFiles(t *testing.T) {
+	modTime := now.Add(-3 * 24 * time.Hour)
+	files := []library.Entry{
+		{Path: "/music/Song [dQw4w9WgXcQ].opus", Title: "Song", Artist: "Band", Duration: 200.4, ModTime: modTime.Unix()},
+	}
+	cands := Collect(nil, nil, files, nil)
+	if len(cands) != 1 {
+		t.Fatalf("got %d candidates", len(cands))
+	}
+	c := cands[0]
+	if !c.InLibrary || c.Seconds != 200.4 || c.Duration != "03:20" || !c.Added.Equal(modTime) {
+		t.Fatalf("library candidate = %+v", c)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "smart.toml")
+	data := `
+[[playlist]]
+name = "Short"
+max_duration = "4:30"
+
+[[playlist]]
+min_duration = "3m"
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 || rules[0].maxDuration != 270 || rules[1].Name != "Smart #2" || rules[1].minDuration != 180 {
+		t.Fatalf("Load() = %+v", rules)
+	}
+
+	if err := os.WriteFile(path, []byte("[[playlist]]\nsource = \"nowhere\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatal("unknown source accepted")
+	}
+
+	if err := os.WriteFile(path, []byte("[[playlist]]\nsort = \"popularity\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatal("unknown sort accepted")
+	}
+}
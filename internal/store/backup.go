@@ -0,0 +1,68 @@
+package store
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/atomicfile"
+	bolt "go.etcd.io/bbolt"
+)
+
+const keepBackups = 3
+
+type Backup struct {
+	Path    string
+	ModTime time.Time
+}
+
+func backupPath(path string, n int) string {
+	if n == 0 {
+		return path + ".bak"
+	}
+	return fmt.Sprintf("%s.bak.%d", path, n)
+}
+
+func (s *Store) Backup() error {
+	path := s.db.Path()
+	_ = os.Remove(backupPath(path, keepBackups-1))
+	for n := keepBackups - 2; n >= 0; n-- {
+		if _, err := os.Stat(backupPath(path, n)); err == nil {
+			if err := os.Rename(backupPath(path, n), backupPath(path, n+1)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.db.View(func(tx *bolt.Tx) error {
+		return atomicfile.Write(backupPath(path, 0), 0o644, func(w io.Writer) error {
+			_, err := tx.WriteTo(w)
+			return err
+		})
+	})
+}
+
+func Backups(path string) []Backup {
+	var list []Backup
+	for n := range keepBackups {
+		p := backupPath(path, n)
+		if info, err := os.Stat(p); err == nil {
+			list = append(list, Backup{Path: p, ModTime: info.ModTime()})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ModTime.After(list[j].ModTime) })
+	return list
+}
+
+func Recover(path, backup string) (string, error) {
+	aside := fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102-150405"))
+	if err := os.Rename(path, aside); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if backup == "" {
+		return aside, nil
+	}
+	return aside, atomicfile.Copy(path, backup, 0o644)
+}
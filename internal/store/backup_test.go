@@ -0,0 +1,197 @@
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func newStore(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "youtui.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddOffline("dQw4w9WgXcQ", "/music/song.opus"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func pageSize(t *testing.T, path string) int64 {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return int64(binary.LittleEndian.Uint32(data[24:]))
+}
+
+func TestPreflight(t *testing.T) {
+	path := newStore(t)
+	if err := preflight(path); err != nil {
+		t.Fatalf("valid database: %v", err)
+	}
+	if err := preflight(filepath.Join(t.TempDir(), "missing.db")); err != nil {
+		t.Fatalf("missing database: %v", err)
+	}
+
+	size := pageSize(t, path)
+	tests := []struct {
+		name    string
+		corrupt func(t *testing.T, path string)
+	}{
+		{"short header", func(t *testing.T, path string) {
+			if err := os.Truncate(path, 10); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"truncated pages", func(t *testing.T, path string) {
+			if err := os.Truncate(path, 3*size); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"bad magic", func(t *testing.T, path string) {
+			f, err := os.OpenFile(path, os.O_WRONLY, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			if _, err := f.WriteAt([]byte{0xde, 0xad, 0xbe, 0xef}, 16); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := newStore(t)
+			tt.corrupt(t, path)
+			if err := preflight(path); !errors.Is(err, ErrCorrupt) {
+				t.Fatalf("preflight() = %v, want ErrCorrupt", err)
+			}
+			if _, err := Open(path); !errors.Is(err, ErrCorrupt) {
+				t.Fatalf("Open() = %v, want ErrCorrupt", err)
+			}
+		})
+	}
+}
+
+func offlineMarker(t *testing.T, path string) string {
+	t.Helper()
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer s.Close()
+	paths, err := s.Offline()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paths["generation"]
+}
+
+func TestBackupRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "youtui.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 5; i++ {
+		if err := s.AddOffline("generation", strconv.Itoa(i)); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Backup(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(Backups(path)); n != keepBackups {
+		t.Fatalf("Backups() = %d copies, want %d", n, keepBackups)
+	}
+	if _, err := os.Stat(backupPath(path, keepBackups)); !os.IsNotExist(err) {
+		t.Fatalf("backup beyond the limit kept: %v", err)
+	}
+	for n, want := range []string{"5", "4", "3"} {
+		copyPath := filepath.Join(t.TempDir(), "copy.db")
+		data, err := os.ReadFile(backupPath(path, n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(copyPath, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := offlineMarker(t, copyPath); got != want {
+			t.Errorf("%s holds generation %q, want %q", backupPath(path, n), got, want)
+		}
+	}
+}
+
+func TestRecoverRestoresBackup(t *testing.T) {
+	path := newStore(t)
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddOffline("generation", "saved"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Backup(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, 10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Open() = %v, want ErrCorrupt", err)
+	}
+
+	aside, err := Recover(path, backupPath(path, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(filepath.Base(aside), "youtui.db.corrupt-") {
+		t.Fatalf("corrupt file moved to %s", aside)
+	}
+	if info, err := os.Stat(aside); err != nil || info.Size() != 10 {
+		t.Fatalf("corrupt file not kept: %v", err)
+	}
+	if got := offlineMarker(t, path); got != "saved" {
+		t.Fatalf("restored generation = %q, want %q", got, "saved")
+	}
+}
+
+func TestRecoverWithoutBackup(t *testing.T) {
+	path := newStore(t)
+	if err := os.Truncate(path, 10); err != nil {
+		t.Fatal(err)
+	}
+	aside, err := Recover(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(aside); err != nil {
+		t.Fatalf("corrupt file not kept: %v", err)
+	}
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() after recovery = %v", err)
+	}
+	defer s.Close()
+	if paths, _ := s.Offline(); len(paths) != 0 {
+		t.Fatalf("fresh store has %v", paths)
+	}
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketMeta = []byte("meta")
+	keySchema  = []byte("schema_version")
+)
+
+var migrations = []func(tx *bolt.Tx) error{
+	migrateBaseBuckets,
+	migrateHistoryBucket,
+	migrateOfflineBucket,
+}
+
+func SchemaVersion() int {
+	return len(migrations)
+}
+
+func migrateBaseBuckets(tx *bolt.Tx) error {
+	for _, name := range [][]byte{bucketTracks, bucketLists, bucketSession} {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrateHistoryBucket(tx *bolt.Tx) error {
+	_, err := tx.CreateBucketIfNotExists([]byte("history"))
+	return err
+}
+
+func migrateOfflineBucket(tx *bolt.Tx) error {
+	_, err := tx.CreateBucketIfNotExists(bucketOffline)
+	return err
+}
+
+func schemaVersion(tx *bolt.Tx) int {
+	b := tx.Bucket(bucketMeta)
+	if b == nil {
+		return 0
+	}
+	v := b.Get(keySchema)
+	if len(v) != 8 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(v))
+}
+
+func pendingMigrations(db *bolt.DB) (int, error) {
+	var version int
+	if err := db.View(func(tx *bolt.Tx) error {
+		version = schemaVersion(tx)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	if version > SchemaVersion() {
+		return 0, fmt.Errorf("%w: schema version %d, this build supports %d", ErrNewerSchema, version, SchemaVersion())
+	}
+	return SchemaVersion() - version, nil
+}
+
+func migrate(db *bolt.DB) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(bucketMeta)
+		if err != nil {
+			return err
+		}
+		for v := schemaVersion(tx); v < SchemaVersion(); v++ {
+			if err := migrations[v](tx); err != nil {
+				return fmt.Errorf("migration %d: %w", v+1, err)
+			}
+			if err := meta.Put(keySchema, binary.BigEndian.AppendUint64(nil, uint64(v+1))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
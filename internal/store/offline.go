@@ -0,0 +1,31 @@
+package store
+
+import bolt "go.etcd.io/bbolt"
+
+var bucketOffline = []byte("offline")
+
+func (s *Store) AddOffline(id, path string) error {
+	if id == "" || path == "" {
+		return nil
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketOffline).Put([]byte(id), []byte(path))
+	})
+}
+
+func (s *Store) RemoveOffline(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketOffline).Delete([]byte(id))
+	})
+}
+
+func (s *Store) Offline() (map[string]string, error) {
+	paths := map[string]string{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketOffline).ForEach(func(k, v []byte) error {
+			paths[string(k)] = string(v)
+			return nil
+		})
+	})
+	return paths, err
+}
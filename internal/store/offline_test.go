@@ -0,0 +1,39 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestOfflinePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "youtui.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddOffline("dQw4w9WgXcQ", "/music/Never Gonna Give You Up.opus"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddOffline("gone", "/music/gone.opus"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RemoveOffline("gone"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	paths, err := s.Offline()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || paths["dQw4w9WgXcQ"] != "/music/Never Gonna Give You Up.opus" {
+		t.Fatalf("Offline() = %v", paths)
+	}
+}
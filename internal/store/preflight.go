@@ -0,0 +1,61 @@
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const boltMagic = 0xED0CDAED
+
+func preflight(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+
+	header := make([]byte, 64)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return fmt.Errorf("%w: short file", ErrCorrupt)
+	}
+	if binary.LittleEndian.Uint32(header[16:]) != boltMagic {
+		return fmt.Errorf("%w: not a database file", ErrCorrupt)
+	}
+	pageSize := int64(binary.LittleEndian.Uint32(header[24:]))
+	if pageSize <= 0 {
+		return fmt.Errorf("%w: invalid page size", ErrCorrupt)
+	}
+
+	var highWater uint64
+	var txid uint64
+	for _, off := range []int64{0, pageSize} {
+		meta := make([]byte, 80)
+		if _, err := f.ReadAt(meta, off); err != nil {
+			continue
+		}
+		if binary.LittleEndian.Uint32(meta[16:]) != boltMagic {
+			continue
+		}
+		if t := binary.LittleEndian.Uint64(meta[64:]); t >= txid {
+			txid = t
+			highWater = binary.LittleEndian.Uint64(meta[56:])
+		}
+	}
+	if int64(highWater)*pageSize > info.Size() {
+		return fmt.Errorf("%w: file is truncated (%d of %d bytes)", ErrCorrupt, info.Size(), int64(highWater)*pageSize)
+	}
+	return nil
+}
@@ -0,0 +1,355 @@
+// Package store
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketTracks  = []byte("tracks")
+	bucketLists   = []byte("lists")
+	bucketSession = []byte("session")
+
+	keySession = []byte("current")
+)
+
+const (
+	listResults  = "results"
+	listPlaylist = "playlist"
+)
+
+type session struct {
+	SearchTerm        string `json:"search_term"`
+	CurrentTrackIdx   int    `json:"current_track_idx"`
+	PlaylistMode      int    `json:"playlist_mode"`
+	PlayMode          int    `json:"play_mode"`
+	SearchScrollIdx   int    `json:"search_scroll_idx"`
+	PlaylistScrollIdx int    `json:"playlist_scroll_idx"`
+	SearchPage        int    `json:"search_page"`
+	LastSaved         string `json:"last_saved"`
+}
+
+type listItem struct {
+	URL     string `json:"url"`
+	AddedAt int64  `json:"added_at,omitempty"`
+}
+
+type Store struct {
+	db *bolt.DB
+
+	mu      sync.Mutex
+	tracks  map[string][]byte
+	lists   map[string][]byte
+	session []byte
+}
+
+var (
+	ErrCorrupt     = errors.New("saved state is unreadable")
+	ErrNewerSchema = errors.New("saved state was written by a newer version")
+)
+
+func Open(path string) (s *Store, err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	var db *bolt.DB
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrCorrupt, r)
+		}
+		if err != nil && db != nil {
+			_ = db.Close()
+		}
+	}()
+
+	if err := preflight(path); err != nil {
+		return nil, err
+	}
+
+	db, err = bolt.Open(path, 0o644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		if errors.Is(err, bolt.ErrTimeout) || errors.Is(err, os.ErrPermission) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+
+	if err := check(db); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+
+	pending, err := pendingMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &Store{db: db, tracks: map[string][]byte{}, lists: map[string][]byte{}}
+	if hasSession(db) {
+		if err := s.Backup(); err != nil {
+			return nil, err
+		}
+	}
+	if pending > 0 {
+		if err := migrate(db); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.load(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	return s, nil
+}
+
+func check(db *bolt.DB) error {
+	return db.View(func(tx *bolt.Tx) error {
+		var first error
+		for err := range tx.Check() {
+			if first == nil {
+				first = err
+			}
+		}
+		return first
+	})
+}
+
+func hasSession(db *bolt.DB) bool {
+	found := false
+	_ = db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(bucketSession); b != nil {
+			found = b.Get(keySession) != nil
+		}
+		return nil
+	})
+	return found
+}
+
+func (s *Store) load() error {
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(bucketTracks).ForEach(func(k, v []byte) error {
+			s.tracks[string(k)] = bytes.Clone(v)
+			return json.Unmarshal(v, &config.Track{})
+		}); err != nil {
+			return err
+		}
+		if err := tx.Bucket(bucketLists).ForEach(func(k, v []byte) error {
+			s.lists[string(k)] = bytes.Clone(v)
+			return json.Unmarshal(v, &[]listItem{})
+		}); err != nil {
+			return err
+		}
+		s.session = bytes.Clone(tx.Bucket(bucketSession).Get(keySession))
+		return nil
+	})
+	if err != nil || s.session == nil {
+		return err
+	}
+	return json.Unmarshal(s.session, &session{})
+}
+
+func (s *Store) DB() *bolt.DB {
+	return s.db
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) HasState() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.session != nil
+}
+
+func (s *Store) LoadState() (*config.PlayerState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := &config.PlayerState{
+		CurrentTrackIdx: -1,
+		SearchResults:   []config.Track{},
+		Playlist:        []config.Track{},
+	}
+	if s.session == nil {
+		return state, nil
+	}
+
+	var sess session
+	if err := json.Unmarshal(s.session, &sess); err != nil {
+		return nil, err
+	}
+	state.SearchTerm = sess.SearchTerm
+	state.CurrentTrackIdx = sess.CurrentTrackIdx
+	state.PlaylistMode = sess.PlaylistMode
+	state.PlayMode = sess.PlayMode
+	state.SearchScrollIdx = sess.SearchScrollIdx
+	state.PlaylistScrollIdx = sess.PlaylistScrollIdx
+	state.SearchPage = sess.SearchPage
+	state.LastSaved = sess.LastSaved
+
+	var err error
+	if state.SearchResults, err = s.loadList(listResults); err != nil {
+		return nil, err
+	}
+	if state.Playlist, err = s.loadList(listPlaylist); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (s *Store) loadList(name string) ([]config.Track, error) {
+	data, ok := s.lists[name]
+	if !ok {
+		return []config.Track{}, nil
+	}
+	var items []listItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+
+	tracks := make([]config.Track, 0, len(items))
+	for _, item := range items {
+		t := config.Track{URL: item.URL}
+		if data, ok := s.tracks[item.URL]; ok {
+			if err := json.Unmarshal(data, &t); err != nil {
+				return nil, err
+			}
+		}
+		t.AddedAt = item.AddedAt
+		tracks = append(tracks, t)
+	}
+	return tracks, nil
+}
+
+func (s *Store) SaveState(state *config.PlayerState) error {
+	sess, err := json.Marshal(session{
+		SearchTerm:        state.SearchTerm,
+		CurrentTrackIdx:   state.CurrentTrackIdx,
+		PlaylistMode:      state.PlaylistMode,
+		PlayMode:          state.PlayMode,
+		SearchScrollIdx:   state.SearchScrollIdx,
+		PlaylistScrollIdx: state.PlaylistScrollIdx,
+		SearchPage:        state.SearchPage,
+		LastSaved:         time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	metas := map[string]config.Track{}
+	lists := map[string][]byte{}
+	for name, list := range map[string][]config.Track{
+		listResults:  state.SearchResults,
+		listPlaylist: state.Playlist,
+	} {
+		items := make([]listItem, 0, len(list))
+		for _, t := range list {
+			if t.URL == "" {
+				continue
+			}
+			items = append(items, listItem{URL: t.URL, AddedAt: t.AddedAt})
+			metas[t.URL] = mergeTrack(metas[t.URL], t)
+		}
+		data, err := json.Marshal(items)
+		if err != nil {
+			return err
+		}
+		lists[name] = data
+	}
+
+	tracks := make(map[string][]byte, len(metas))
+	for url, meta := range metas {
+		data, err := json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+		tracks[url] = data
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		tb := tx.Bucket(bucketTracks)
+		for url, data := range tracks {
+			if !bytes.Equal(s.tracks[url], data) {
+				if err := tb.Put([]byte(url), data); err != nil {
+					return err
+				}
+			}
+		}
+		for url := range s.tracks {
+			if _, ok := tracks[url]; !ok {
+				if err := tb.Delete([]byte(url)); err != nil {
+					return err
+				}
+			}
+		}
+
+		lb := tx.Bucket(bucketLists)
+		for name, data := range lists {
+			if !bytes.Equal(s.lists[name], data) {
+				if err := lb.Put([]byte(name), data); err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Bucket(bucketSession).Put(keySession, sess)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.tracks = tracks
+	s.lists = lists
+	s.session = sess
+	return nil
+}
+
+func mergeTrack(cur, t config.Track) config.Track {
+	t.AddedAt = 0
+	for _, f := range []struct{ dst, src *string }{
+		{&t.Title, &cur.Title},
+		{&t.Author, &cur.Author},
+		{&t.Thumbnail, &cur.Thumbnail},
+		{&t.Duration, &cur.Duration},
+		{&t.PublishedAt, &cur.PublishedAt},
+		{&t.Description, &cur.Description},
+	} {
+		if *f.dst == "" {
+			*f.dst = *f.src
+		}
+	}
+	return t
+}
+
+func (s *Store) MigrateJSON() (bool, error) {
+	path := config.GetStatePath()
+	if s.HasState() {
+		return false, nil
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	state, err := config.LoadState()
+	if err != nil {
+		return false, err
+	}
+	if err := s.SaveState(state); err != nil {
+		return false, err
+	}
+	return true, os.Rename(path, path+".migrated")
+}
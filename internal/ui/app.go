@@ -2,60 +2,44 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
-	"os/exec"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/daemon"
+	"github.com/IvelOt/youtui-player/internal/discord"
+	"github.com/IvelOt/youtui-player/internal/download"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/history"
+	"github.com/IvelOt/youtui-player/internal/hooks"
+	"github.com/IvelOt/youtui-player/internal/library"
+	"github.com/IvelOt/youtui-player/internal/mpris"
+	"github.com/IvelOt/youtui-player/internal/nowplaying"
+	"github.com/IvelOt/youtui-player/internal/persist"
+	"github.com/IvelOt/youtui-player/internal/smart"
+	"github.com/IvelOt/youtui-player/internal/store"
+	"github.com/godbus/dbus/v5"
 	"github.com/rivo/tview"
 )
 
-type PlaylistMode int
-
-const (
-	ModeNormal PlaylistMode = iota
-	ModeRepeatOne
-	ModeRepeatAll
-	ModeShuffle
+type (
+	Track        = engine.Track
+	PlaylistMode = engine.PlaylistMode
+	PlayMode     = engine.PlayMode
 )
 
-func (m PlaylistMode) String() string {
-	switch m {
-	case ModeShuffle:
-		return " Shuffle"
-	case ModeRepeatOne:
-		return "󰑘 Repeat 1"
-	case ModeRepeatAll:
-		return "󰑖 Repeat All"
-	default:
-		return "▶ Normal"
-	}
-}
-
-type PlayMode int
-
 const (
-	ModeAudio PlayMode = iota
-	ModeVideo
-)
+	ModeNormal    = engine.ModeNormal
+	ModeRepeatOne = engine.ModeRepeatOne
+	ModeRepeatAll = engine.ModeRepeatAll
+	ModeShuffle   = engine.ModeShuffle
 
-func (m PlayMode) String() string {
-	if m == ModeAudio {
-		return " Audio"
-	}
-	return "󰗃 Video"
-}
-
-type Track struct {
-	Title       string
-	Author      string
-	URL         string
-	Thumbnail   string
-	Duration    string
-	PublishedAt string
-	Description string
-}
+	ModeAudio = engine.ModeAudio
+	ModeVideo = engine.ModeVideo
+)
 
 type SimpleApp struct {
 	app            *tview.Application
@@ -74,31 +58,84 @@ type SimpleApp struct {
 	modeBadge      *tview.TextView
 	helpView       *HelpView
 	configModal    *tview.Modal
+	promptField    *tview.InputField
+	promptReturn   tview.Primitive
 
 	tracks         []Track
 	playlistTracks []Track
+	cutIndices     []int
 	pagination     *Pagination
 
-	mpvProcess   *exec.Cmd
-	mpvSocket    string
+	player   engine.Controller
+	engine   *engine.Engine
+	client   *daemon.Client
+	server   *daemon.Server
+	session  uint64
+	detached bool
+
+	playlistVersion    uint64
+	playlistSynced     uint64
+	playlistBase       uint64
+	playlistBaseTracks []Track
+	playlistSeen       uint64
+	playlistKnown      bool
+	syncMu             sync.Mutex
+
 	isPlaying    bool
 	isPaused     bool
 	currentTrack int
 	nowPlaying   string
 	currentThumb string
+	playingTrack Track
 	duration     float64
 	position     float64
+	volume       int
 
-	playlistMode PlaylistMode
-	playMode     PlayMode
-	videoQuality string
-	videoCodec   string
+	playlistMode     PlaylistMode
+	showShuffleOrder bool
+	playMode         PlayMode
+	videoQuality     string
+	videoCodec       string
 
-	stopProgress chan bool
+	thumbCache *ThumbnailCache
 
-	skipAutoPlay bool
+	downloads      *download.Manager
+	downloadCfg    config.DownloadConfig
+	downloadsPanel *tview.Flex
+	downloadsTable *tview.Table
+	downloadIDs    []string
 
-	thumbCache *ThumbnailCache
+	lib     *library.Library
+	offline *library.Index
+	ratings *library.Ratings
+
+	owner *persist.Owner
+	data  persist.Data
+
+	incognito         bool
+	historyPanel      *tview.Flex
+	historyTable      *tview.Table
+	historySearch     *tview.InputField
+	historyRows       []*history.Entry
+	historyClearArmed bool
+
+	statsPanel  *tview.Flex
+	statsView   *tview.TextView
+	statsPeriod history.Period
+
+	smartRules   []smart.Rule
+	smartModTime time.Time
+	smartLoaded  bool
+	activeSmart  string
+
+	mpris     *mpris.Bridge
+	mprisConn *dbus.Conn
+	overlay   *nowplaying.Overlay
+	hooks     *hooks.Runner
+	discord   *discord.Presence
+
+	notifications *desktopNotifications
+	scrobbling    *scrobbling
 
 	theme    *Theme
 	language Language
@@ -111,7 +148,7 @@ type SimpleApp struct {
 	mu sync.Mutex
 }
 
-func NewSimpleApp(version string) *SimpleApp {
+func NewSimpleApp(version string, client *daemon.Client) *SimpleApp {
 	cfg, _ := config.LoadConfig()
 
 	var theme *Theme
@@ -139,11 +176,17 @@ func NewSimpleApp(version string) *SimpleApp {
 		videoQuality:   normalizeVideoQuality(cfg.Playback.VideoQuality),
 		videoCodec:     normalizeVideoCodec(cfg.Playback.VideoCodec),
 		currentTrack:   -1,
+		volume:         100,
 		theme:          theme,
 		version:        version,
 		language:       lang,
 		strings:        GetStrings(lang),
 		thumbCache:     thumbCache,
+		downloads:      newDownloadManager(cfg.Download),
+		downloadCfg:    cfg.Download,
+		lib:            library.New(config.GetLibraryDirs(cfg), config.GetLibraryCachePath()),
+		offline:        library.NewIndex(),
+		ratings:        library.OpenRatings(config.GetRatingsPath()),
 	}
 
 	tview.Styles.PrimitiveBackgroundColor = theme.Base
@@ -158,7 +201,30 @@ func NewSimpleApp(version string) *SimpleApp {
 	tview.Styles.InverseTextColor = theme.Base
 	tview.Styles.ContrastSecondaryTextColor = theme.Subtext0
 
+	var storeErr error
+	if client == nil {
+		storeErr = app.openStore()
+	}
+
+	overlayErr := app.setupPlayer(client)
 	app.setupUI()
+	if storeErr != nil {
+		app.setStatusf(theme.Red, "❌ "+app.strings.StoreError, storeErr)
+		if errors.Is(storeErr, store.ErrCorrupt) {
+			app.showStoreRecovery(storeErr)
+		}
+	}
+	if overlayErr != nil {
+		app.setStatusf(theme.Red, "❌ "+app.strings.NowPlayingError, overlayErr)
+	}
+	app.setupOfflineIndex()
+	app.setupRatings()
+
+	app.downloads.SetNotify(app.onDownloadChanged)
+	app.downloads.Start()
+
+	go app.watchPlayer()
+	go app.watchSmartPlaylists()
 
 	go func() {
 		currentVersion, _, needsUpdate := CheckYtDlpVersion()
@@ -169,11 +235,7 @@ func NewSimpleApp(version string) *SimpleApp {
 		}
 	}()
 
-	go func() {
-		if err := app.RestoreState(); err != nil {
-			_ = err
-		}
-	}()
+	go app.restoreStateWithStatus()
 
 	return app
 }
@@ -182,50 +244,43 @@ func (a *SimpleApp) Run() error {
 	return a.app.Run()
 }
 
-func (a *SimpleApp) cleanup() {
+func (a *SimpleApp) openStore() error {
+	owner, err := persist.Open()
 	a.mu.Lock()
-	if a.stopProgress != nil {
-		close(a.stopProgress)
-		a.stopProgress = nil
-	}
-
-	if a.mpvProcess != nil && a.mpvProcess.Process != nil {
-		if KillError := a.mpvProcess.Process.Kill(); KillError == nil {
-			fmt.Printf("Error: %s", KillError)
-		}
-		a.mpvProcess = nil
-	}
-	a.isPlaying = false
-	a.isPaused = false
-	a.nowPlaying = ""
-	a.currentThumb = ""
-	a.mpvSocket = ""
-	a.position = 0
-	a.duration = 0
+	a.owner = owner
+	a.data = owner
 	a.mu.Unlock()
+	return err
 }
 
 func (a *SimpleApp) SaveCurrentState() error {
 	a.mu.Lock()
-	defer a.mu.Unlock()
-
+	data := a.data
+	if data == nil {
+		a.mu.Unlock()
+		return nil
+	}
 	state := &config.PlayerState{
 		SearchTerm:        a.getSearchTerm(),
-		SearchResults:     convertTracksToConfigTracks(a.tracks),
-		Playlist:          convertTracksToConfigTracks(a.playlistTracks),
-		CurrentTrackIdx:   a.currentTrack,
-		PlaylistMode:      int(a.playlistMode),
-		PlayMode:          int(a.playMode),
+		SearchResults:     persist.ConfigTracks(a.tracks),
 		SearchScrollIdx:   a.searchResults.GetCurrentItem(),
 		PlaylistScrollIdx: a.playlist.GetCurrentItem(),
 		SearchPage:        a.pagination.GetCurrentPage(),
 	}
+	a.mu.Unlock()
 
-	return config.SaveState(state)
+	return data.SaveSession(state)
 }
 
 func (a *SimpleApp) RestoreState() error {
-	state, err := config.LoadState()
+	a.mu.Lock()
+	data := a.data
+	a.mu.Unlock()
+	if data == nil {
+		return nil
+	}
+
+	state, err := data.LoadSession()
 	if err != nil {
 		return err
 	}
@@ -235,14 +290,7 @@ func (a *SimpleApp) RestoreState() error {
 	}
 
 	a.mu.Lock()
-
-	a.playlistMode = PlaylistMode(state.PlaylistMode)
-	a.playMode = PlayMode(state.PlayMode)
-	a.currentTrack = state.CurrentTrackIdx
-
-	a.tracks = convertConfigTracksToTracks(state.SearchResults)
-	a.playlistTracks = convertConfigTracksToTracks(state.Playlist)
-
+	a.tracks = persist.Tracks(state.SearchResults)
 	a.mu.Unlock()
 
 	a.app.QueueUpdateDraw(func() {
@@ -305,6 +353,7 @@ func (a *SimpleApp) RestoreState() error {
 		}
 
 		a.playlist.SetTitle(fmt.Sprintf(" Playlist [%d] ", len(a.playlistTracks)))
+		a.updateShuffleOrderView()
 
 		a.updatePlayerInfo()
 		a.updatePlaylistFooter()
@@ -317,38 +366,6 @@ func (a *SimpleApp) RestoreState() error {
 	return nil
 }
 
-func convertTracksToConfigTracks(tracks []Track) []config.Track {
-	result := make([]config.Track, len(tracks))
-	for i, t := range tracks {
-		result[i] = config.Track{
-			Title:       t.Title,
-			Author:      t.Author,
-			URL:         t.URL,
-			Thumbnail:   t.Thumbnail,
-			Duration:    t.Duration,
-			PublishedAt: t.PublishedAt,
-			Description: t.Description,
-		}
-	}
-	return result
-}
-
-func convertConfigTracksToTracks(tracks []config.Track) []Track {
-	result := make([]Track, len(tracks))
-	for i, t := range tracks {
-		result[i] = Track{
-			Title:       t.Title,
-			Author:      t.Author,
-			URL:         t.URL,
-			Thumbnail:   t.Thumbnail,
-			Duration:    t.Duration,
-			PublishedAt: t.PublishedAt,
-			Description: t.Description,
-		}
-	}
-	return result
-}
-
 func (a *SimpleApp) getSearchTerm() string {
 	return a.searchInput.GetText()
 }
@@ -356,7 +373,9 @@ func (a *SimpleApp) getSearchTerm() string {
 func (a *SimpleApp) AutoSaveState() {
 	go func() {
 		if err := a.SaveCurrentState(); err != nil {
-			_ = err
+			a.app.QueueUpdateDraw(func() {
+				a.setStatusf(a.theme.Red, "❌ "+a.strings.StateSaveError, err)
+			})
 		}
 	}()
 }
@@ -1,9 +1,14 @@
 package ui
 
 import (
+	"fmt"
 	"image"
+	"slices"
+	"strings"
 	"sync"
+	"unicode/utf8"
 
+	"github.com/IvelOt/youtui-player/internal/library"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -29,6 +34,17 @@ type CustomList struct {
 	visibleHeight int
 	lastHeight    int
 	dirty         bool
+
+	marked       map[int]bool
+	visualMode   bool
+	visualAnchor int
+
+	queuePos map[int]int
+	offline  func(Track) bool
+	rating   func(Track) trackMark
+
+	filter  string
+	visible []int
 }
 
 func NewCustomList(theme *Theme) *CustomList {
@@ -46,6 +62,8 @@ func NewCustomList(theme *Theme) *CustomList {
 		items:         []*CustomListItem{},
 		selectedIndex: 0,
 		playingIndex:  -1,
+		marked:        map[int]bool{},
+		queuePos:      map[int]int{},
 		theme:         theme,
 		visibleStart:  0,
 		visibleHeight: 10,
@@ -64,8 +82,8 @@ func NewCustomList(theme *Theme) *CustomList {
 			list.SelectNext()
 			return nil
 		case tcell.KeyEnter:
-			if list.onSelected != nil {
-				list.onSelected(list.selectedIndex)
+			if list.onSelected != nil && list.hasRows() {
+				list.onSelected(list.GetCurrentItem())
 			}
 			return nil
 		case tcell.KeyTab, tcell.KeyBacktab:
@@ -89,7 +107,7 @@ func (c *CustomList) AddItem(track Track, index int) {
 
 	info := tview.NewTextView().
 		SetDynamicColors(true).
-		SetText(formatItemInfo(track, index, c.theme)).
+		SetText(formatItemInfo(track, index, c.theme, trackMark{}, "")).
 		SetTextAlign(tview.AlignLeft)
 	info.SetBackgroundColor(c.theme.Base)
 	info.SetTextColor(c.theme.Text)
@@ -109,6 +127,12 @@ func (c *CustomList) AddItem(track Track, index int) {
 	}
 
 	c.items = append(c.items, item)
+	if c.visible != nil && fuzzyMatch(c.filter, track.Title+" "+track.Author) {
+		c.visible = append(c.visible, len(c.items)-1)
+		if c.rowOf(c.selectedIndex) < 0 {
+			c.selectedIndex = len(c.items) - 1
+		}
+	}
 	c.renderVisibleItems()
 }
 
@@ -127,6 +151,11 @@ func (c *CustomList) Clear() {
 	c.items = []*CustomListItem{}
 	c.selectedIndex = 0
 	c.visibleStart = 0
+	c.marked = map[int]bool{}
+	c.visualMode = false
+	if c.visible != nil {
+		c.visible = []int{}
+	}
 }
 
 func (c *CustomList) renderVisibleItems() {
@@ -145,15 +174,15 @@ func (c *CustomList) renderVisibleItems() {
 
 	c.visibleHeight = max(itemsPerPage, 1)
 
-	end := min(c.visibleStart+itemsPerPage, len(c.items))
+	end := min(c.visibleStart+itemsPerPage, c.rowCount())
 
-	if len(c.items) == 0 || itemsPerPage == 0 {
+	if c.rowCount() == 0 || itemsPerPage == 0 {
 		spacer := tview.NewBox().SetBackgroundColor(c.theme.Base)
 		c.container.AddItem(spacer, 0, 1, false)
 	} else {
 		itemsRendered := 0
 		for i := c.visibleStart; i < end; i++ {
-			c.container.AddItem(c.items[i].flex, itemHeight, 0, false)
+			c.container.AddItem(c.items[c.rowItem(i)].flex, itemHeight, 0, false)
 			itemsRendered++
 		}
 
@@ -168,53 +197,55 @@ func (c *CustomList) renderVisibleItems() {
 }
 
 func (c *CustomList) scrollToSelection() {
-	if c.selectedIndex >= c.visibleStart+c.visibleHeight {
-		c.visibleStart = c.selectedIndex - c.visibleHeight + 1
+	row := c.rowOf(c.selectedIndex)
+	if row < 0 {
+		return
+	}
+	if row >= c.visibleStart+c.visibleHeight {
+		c.visibleStart = row - c.visibleHeight + 1
 		c.renderVisibleItems()
 	}
-	if c.selectedIndex < c.visibleStart {
-		c.visibleStart = c.selectedIndex
+	if row < c.visibleStart {
+		c.visibleStart = row
 		c.renderVisibleItems()
 	}
 }
 
+func (c *CustomList) selectRow(row int) {
+	c.selectedIndex = c.rowItem(row)
+	c.scrollToSelection()
+	c.updateSelection()
+}
+
 func (c *CustomList) SelectNext() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if c.selectedIndex < len(c.items)-1 {
-		c.selectedIndex++
-		c.scrollToSelection()
-		c.updateSelection()
+	if row := c.rowOf(c.selectedIndex); row < c.rowCount()-1 {
+		c.selectRow(row + 1)
 	}
 }
 
 func (c *CustomList) SelectPrevious() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if c.selectedIndex > 0 {
-		c.selectedIndex--
-		c.scrollToSelection()
-		c.updateSelection()
+	if row := c.rowOf(c.selectedIndex); row > 0 {
+		c.selectRow(row - 1)
 	}
 }
 
 func (c *CustomList) SelectFirst() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if len(c.items) > 0 {
-		c.selectedIndex = 0
-		c.scrollToSelection()
-		c.updateSelection()
+	if c.rowCount() > 0 {
+		c.selectRow(0)
 	}
 }
 
 func (c *CustomList) SelectLast() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if len(c.items) > 0 {
-		c.selectedIndex = len(c.items) - 1
-		c.scrollToSelection()
-		c.updateSelection()
+	if c.rowCount() > 0 {
+		c.selectRow(c.rowCount() - 1)
 	}
 }
 
@@ -227,17 +258,23 @@ func (c *CustomList) GetCurrentItem() int {
 func (c *CustomList) SetCurrentIndex(idx int) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if idx >= 0 && idx < len(c.items) {
+	if c.rowOf(idx) >= 0 {
 		c.selectedIndex = idx
 		c.scrollToSelection()
 		c.updateSelection()
 	}
 }
 
+func (c *CustomList) ItemCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
 func (c *CustomList) GetCurrentTrack() *Track {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if c.selectedIndex >= 0 && c.selectedIndex < len(c.items) {
+	if c.rowOf(c.selectedIndex) >= 0 {
 		return &c.items[c.selectedIndex].track
 	}
 	return nil
@@ -254,24 +291,174 @@ func (c *CustomList) SetSelectedFunc(handler func(int)) {
 
 func (c *CustomList) updateSelection() {
 	for i, item := range c.items {
-		switch i {
-		case c.selectedIndex:
+		switch {
+		case i == c.selectedIndex:
 			item.flex.SetBackgroundColor(c.theme.Blue)
 			item.info.SetTextColor(c.theme.Crust)
 			item.info.SetBackgroundColor(c.theme.Blue)
-			item.info.SetText(formatItemInfoPlain(item.track, item.index))
-		case c.playingIndex:
+			item.info.SetText(formatItemInfoPlain(item.track, item.index, c.markFor(i), c.badgeFor(i)))
+		case c.isSelected(i):
+			item.flex.SetBackgroundColor(c.theme.Mauve)
+			item.info.SetTextColor(c.theme.Crust)
+			item.info.SetBackgroundColor(c.theme.Mauve)
+			item.info.SetText(formatItemInfoPlain(item.track, item.index, c.markFor(i), c.badgeFor(i)))
+		case i == c.playingIndex:
 			item.flex.SetBackgroundColor(c.theme.Green)
 			item.info.SetTextColor(c.theme.Crust)
 			item.info.SetBackgroundColor(c.theme.Green)
-			item.info.SetText(formatItemInfoPlain(item.track, item.index))
+			item.info.SetText(formatItemInfoPlain(item.track, item.index, c.markFor(i), c.badgeFor(i)))
 		default:
 			item.flex.SetBackgroundColor(c.theme.Base)
 			item.info.SetTextColor(c.theme.Text)
 			item.info.SetBackgroundColor(c.theme.Base)
-			item.info.SetText(formatItemInfo(item.track, item.index, c.theme))
+			item.info.SetText(formatItemInfo(item.track, item.index, c.theme, c.markFor(i), c.badgeFor(i)))
+		}
+	}
+}
+
+func (c *CustomList) ToggleVisual() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.visualMode {
+		for _, i := range c.visualRange() {
+			c.marked[i] = true
+		}
+		c.visualMode = false
+	} else if c.rowOf(c.selectedIndex) >= 0 {
+		c.visualMode = true
+		c.visualAnchor = c.selectedIndex
+	}
+	c.updateSelection()
+	return c.visualMode
+}
+
+func (c *CustomList) ToggleMark() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	row := c.rowOf(c.selectedIndex)
+	if row < 0 {
+		return
+	}
+	if c.marked[c.selectedIndex] {
+		delete(c.marked, c.selectedIndex)
+	} else {
+		c.marked[c.selectedIndex] = true
+	}
+	if row < c.rowCount()-1 {
+		c.selectedIndex = c.rowItem(row + 1)
+		c.scrollToSelection()
+	}
+	c.updateSelection()
+}
+
+func (c *CustomList) ClearSelection() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.marked = map[int]bool{}
+	c.visualMode = false
+	c.updateSelection()
+}
+
+func (c *CustomList) SetMarked(idxs []int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.marked = map[int]bool{}
+	for _, i := range idxs {
+		if i >= 0 && i < len(c.items) {
+			c.marked[i] = true
+		}
+	}
+	c.updateSelection()
+}
+
+func (c *CustomList) InVisualMode() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.visualMode
+}
+
+func (c *CustomList) MarkedCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.markedCount()
+}
+
+func (c *CustomList) markedCount() int {
+	n := 0
+	for i := range c.marked {
+		if c.rowOf(i) >= 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (c *CustomList) HasSelection() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.visualMode || c.markedCount() > 0
+}
+
+func (c *CustomList) SelectedIndices() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rowOf(c.selectedIndex) < 0 {
+		return nil
+	}
+	if !c.visualMode && c.markedCount() == 0 {
+		return []int{c.selectedIndex}
+	}
+	var idxs []int
+	for i := range c.items {
+		if c.isSelected(i) {
+			idxs = append(idxs, i)
 		}
 	}
+	return idxs
+}
+
+func (c *CustomList) SelectedTracks() []Track {
+	idxs := c.SelectedIndices()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tracks := make([]Track, 0, len(idxs))
+	for _, i := range idxs {
+		if i < len(c.items) {
+			tracks = append(tracks, c.items[i].track)
+		}
+	}
+	return tracks
+}
+
+func (c *CustomList) visualRange() []int {
+	if !c.visualMode {
+		return nil
+	}
+	anchor, cursor := c.rowOf(c.visualAnchor), c.rowOf(c.selectedIndex)
+	if anchor < 0 || cursor < 0 {
+		return nil
+	}
+	lo, hi := min(anchor, cursor), max(anchor, cursor)
+	idxs := make([]int, 0, hi-lo+1)
+	for row := lo; row <= hi; row++ {
+		idxs = append(idxs, c.rowItem(row))
+	}
+	return idxs
+}
+
+func (c *CustomList) isSelected(i int) bool {
+	row := c.rowOf(i)
+	if row < 0 {
+		return false
+	}
+	if c.marked[i] {
+		return true
+	}
+	if !c.visualMode {
+		return false
+	}
+	anchor, cursor := c.rowOf(c.visualAnchor), c.rowOf(c.selectedIndex)
+	return anchor >= 0 && cursor >= 0 && row >= min(anchor, cursor) && row <= max(anchor, cursor)
 }
 
 func (c *CustomList) SetTitle(title string) *CustomList {
@@ -291,27 +478,115 @@ func (c *CustomList) SetPlayingIndex(idx int) {
 	c.updateSelection()
 }
 
-func formatItemInfo(track Track, index int, theme *Theme) string {
+type trackMark struct {
+	favorite bool
+	stars    int
+}
+
+func (m trackMark) hearts() string {
+	if m.favorite {
+		return "♥"
+	}
+	return ""
+}
+
+func (m trackMark) starsText() string {
+	if m.stars <= 0 {
+		return ""
+	}
+	return strings.Repeat("★", m.stars) + strings.Repeat("☆", library.MaxStars-m.stars)
+}
+
+func formatItemInfo(track Track, index int, theme *Theme, mark trackMark, badge string) string {
 	icons := []string{"♪", "♫", "♬"}
 	icon := icons[index%len(icons)]
 	title := track.Title
 	if len(title) > 50 {
 		title = title[:47] + "..."
 	}
-	return icon + " [" + colorTag(theme.Yellow) + "::b]" + title + "[-:-:-]\n" +
+	info := icon + " [" + colorTag(theme.Yellow) + "::b]" + title + "[-:-:-]"
+	if h := mark.hearts(); h != "" {
+		info += " [" + colorTag(theme.Red) + "]" + h + "[-]"
+	}
+	if st := mark.starsText(); st != "" {
+		info += " [" + colorTag(theme.Peach) + "]" + st + "[-]"
+	}
+	info += "\n" +
 		"[" + colorTag(theme.Green) + "]⏱ " + track.Duration + "[-] " +
 		"[" + colorTag(theme.Sapphire) + "]• " + track.Author + "[-]"
+	if badge != "" {
+		info += " [" + colorTag(theme.Teal) + "]" + badge + "[-]"
+	}
+	return info
 }
 
-func formatItemInfoPlain(track Track, index int) string {
+func formatItemInfoPlain(track Track, index int, mark trackMark, badge string) string {
 	icons := []string{"♪", "♫", "♬"}
 	icon := icons[index%len(icons)]
 	title := track.Title
 	if len(title) > 50 {
 		title = title[:47] + "..."
 	}
-	return icon + " " + title + "\n" +
+	info := icon + " " + title
+	for _, m := range []string{mark.hearts(), mark.starsText()} {
+		if m != "" {
+			info += " " + m
+		}
+	}
+	info += "\n" +
 		"⏱ " + track.Duration + " • " + track.Author
+	if badge != "" {
+		info += " " + badge
+	}
+	return info
+}
+
+func (c *CustomList) badgeFor(i int) string {
+	var badges []string
+	if c.offline != nil && c.offline(c.items[i].track) {
+		badges = append(badges, "⤓ offline")
+	}
+	if pos, ok := c.queuePos[i]; ok {
+		badges = append(badges, fmt.Sprintf("⤮ %d", pos))
+	}
+	return strings.Join(badges, " ")
+}
+
+func (c *CustomList) markFor(i int) trackMark {
+	if c.rating == nil {
+		return trackMark{}
+	}
+	return c.rating(c.items[i].track)
+}
+
+func (c *CustomList) SetRatingFunc(fn func(Track) trackMark) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rating = fn
+	c.updateSelection()
+}
+
+func (c *CustomList) SetOfflineFunc(fn func(Track) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.offline = fn
+	c.updateSelection()
+}
+
+func (c *CustomList) RefreshBadges() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.updateSelection()
+}
+
+func (c *CustomList) SetQueueOrder(idxs []int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queuePos = make(map[int]int, len(idxs))
+	for pos, i := range idxs {
+		c.queuePos[i] = pos + 1
+	}
+	c.updateSelection()
 }
 
 func (c *CustomList) MarkDirty() {
@@ -345,3 +620,103 @@ func (c *CustomList) SetTheme(theme *Theme) {
 	}
 	c.renderVisibleItems()
 }
+
+func (c *CustomList) rowCount() int {
+	if c.visible == nil {
+		return len(c.items)
+	}
+	return len(c.visible)
+}
+
+func (c *CustomList) rowItem(row int) int {
+	if c.visible == nil {
+		return row
+	}
+	return c.visible[row]
+}
+
+func (c *CustomList) rowOf(idx int) int {
+	if c.visible == nil {
+		if idx >= 0 && idx < len(c.items) {
+			return idx
+		}
+		return -1
+	}
+	return slices.Index(c.visible, idx)
+}
+
+func (c *CustomList) hasRows() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rowOf(c.selectedIndex) >= 0
+}
+
+func (c *CustomList) SetFilter(query string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.filter = strings.TrimSpace(query)
+	c.visualMode = false
+	c.visibleStart = 0
+	if c.filter == "" {
+		c.visible = nil
+	} else {
+		c.visible = []int{}
+		for i, item := range c.items {
+			if fuzzyMatch(c.filter, item.track.Title+" "+item.track.Author) {
+				c.visible = append(c.visible, i)
+			}
+		}
+		if c.rowOf(c.selectedIndex) < 0 && len(c.visible) > 0 {
+			c.selectedIndex = c.visible[0]
+		}
+	}
+	c.renderVisibleItems()
+	c.scrollToSelection()
+	return c.rowCount()
+}
+
+func (c *CustomList) Filter() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.filter
+}
+
+func (c *CustomList) FilterCount() (matches, total int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rowCount(), len(c.items)
+}
+
+func (c *CustomList) JumpMatch(delta int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.rowCount()
+	if n == 0 {
+		return
+	}
+	row := c.rowOf(c.selectedIndex)
+	if row < 0 {
+		row = 0
+	} else {
+		row = ((row+delta)%n + n) % n
+	}
+	c.selectRow(row)
+}
+
+func fuzzyMatch(query, text string) bool {
+	text = strings.ToLower(text)
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		if strings.Contains(text, term) {
+			continue
+		}
+		rest := text
+		for _, r := range term {
+			i := strings.IndexRune(rest, r)
+			if i < 0 {
+				return false
+			}
+			rest = rest[i+utf8.RuneLen(r):]
+		}
+	}
+	return true
+}
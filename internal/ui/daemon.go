@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/daemon"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/library"
+	"github.com/IvelOt/youtui-player/internal/mpris"
+	"github.com/IvelOt/youtui-player/internal/persist"
+	"github.com/IvelOt/youtui-player/internal/scrobble"
+	"github.com/godbus/dbus/v5"
+)
+
+func RunDaemon() error {
+	if client, err := daemon.Dial(config.GetSocketPath()); err == nil {
+		_ = client.Close()
+		return fmt.Errorf("another instance is listening on %s", config.GetSocketPath())
+	}
+
+	cfg, _ := config.LoadConfig()
+
+	offline := library.NewIndex()
+	lib := library.New(config.GetLibraryDirs(cfg), config.GetLibraryCachePath())
+	offline.AddEntries(lib.Entries())
+
+	eng := engine.New(engine.Options{
+		Resolve: func(track Track) (string, bool) {
+			return resolveTarget(offline, track)
+		},
+		PlayMode: parsePlayMode(cfg.Playback.DefaultMode),
+		Quality:  normalizeVideoQuality(cfg.Playback.VideoQuality),
+		Codec:    normalizeVideoCodec(cfg.Playback.VideoCodec),
+	})
+	defer eng.Close()
+
+	owner, err := persist.Open()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "saved state unavailable: %v\n", err)
+	}
+	defer owner.Close()
+	owner.IndexOffline(offline)
+	if err := owner.Watch(eng, func(err error) {
+		fmt.Fprintf(os.Stderr, "history: %v\n", err)
+	}); err != nil {
+		return err
+	}
+
+	srv, err := daemon.Listen(config.GetSocketPath(), eng, owner)
+	if err != nil {
+		return err
+	}
+	defer srv.Close()
+
+	runner, err := startHooks(eng, func(event string, err error) {
+		fmt.Fprintf(os.Stderr, "hook %s: %v\n", event, err)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hooks disabled: %v\n", err)
+	}
+	if runner != nil {
+		defer runner.Close()
+	}
+
+	thumbCache, _ := NewThumbnailCache()
+	texts := GetStrings(Language(cfg.UI.Language))
+	notifications, err := startNotifications(eng, func() Strings { return texts }, thumbCache)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "desktop notifications disabled: %v\n", err)
+	}
+	if notifications != nil {
+		defer notifications.Close()
+	}
+
+	scrobbler, err := startScrobbling(eng, ownerIncognito(owner), func(err error) {
+		if scrobble.AuthFailed(err) {
+			fmt.Fprintf(os.Stderr, "scrobble paused, check the credentials: %v\n", err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "scrobble: %v\n", err)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scrobbling: %v\n", err)
+	}
+	if scrobbler != nil {
+		defer scrobbler.Close()
+	}
+
+	presence, err := startDiscord(eng, func() Strings { return texts }, func(err error) {
+		fmt.Fprintf(os.Stderr, "discord: %v\n", err)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "discord presence disabled: %v\n", err)
+	}
+	if presence != nil {
+		defer presence.Close()
+	}
+
+	overlay, err := startOverlay(eng)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "now-playing output disabled: %v\n", err)
+	}
+	if overlay != nil {
+		defer overlay.Close()
+	}
+
+	if conn, err := dbus.ConnectSessionBus(); err == nil {
+		defer conn.Close()
+		artURL := func(track Track) string { return ArtURL(thumbCache, track) }
+		if bridge, err := mpris.Attach(conn, eng, artURL); err == nil {
+			defer bridge.Close()
+		}
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve()
+	}()
+	fmt.Fprintf(os.Stderr, "youtui-player daemon listening on %s\n", srv.Path())
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	select {
+	case <-sig:
+		return nil
+	case err := <-serveErr:
+		return err
+	}
+}
@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/discord"
+	"github.com/IvelOt/youtui-player/internal/engine"
+)
+
+func startDiscord(ctrl engine.Controller, texts func() Strings, onError func(error)) (*discord.Presence, error) {
+	cfg, _ := config.LoadConfig()
+	if !cfg.Discord.Enabled {
+		return nil, nil
+	}
+	if cfg.Discord.ClientID == "" {
+		return nil, errors.New("discord: client_id is required")
+	}
+	return discord.Watch(ctrl, discord.Options{
+		ClientID:    cfg.Discord.ClientID,
+		ButtonLabel: func() string { return texts().DiscordButton },
+		StateText: func(track Track) string {
+			return fmt.Sprintf(texts().DiscordByAuthor, track.Author)
+		},
+		OnError: onError,
+	})
+}
+
+func (a *SimpleApp) onDiscordError(err error) {
+	a.app.QueueUpdateDraw(func() {
+		a.setStatusf(a.theme.Yellow, "⚠ "+a.strings.DiscordError, err)
+	})
+}
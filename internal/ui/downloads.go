@@ -0,0 +1,335 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/download"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/search"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func newDownloadManager(cfg config.DownloadConfig) *download.Manager {
+	return download.NewManager(download.Options{
+		Dir:              config.GetDownloadDir(cfg),
+		Concurrency:      cfg.Concurrency,
+		QueuePath:        config.GetDownloadQueuePath(),
+		FilenameTemplate: cfg.FilenameTemplate,
+	})
+}
+
+func (a *SimpleApp) promptDownload(tracks []Track, fromPlaylist bool) {
+	if len(tracks) == 0 {
+		return
+	}
+
+	audio := func(format, bitrate string) func() {
+		return func() {
+			go a.enqueueDownloads(tracks, fromPlaylist, download.Request{
+				Kind:         download.KindAudio,
+				AudioFormat:  format,
+				AudioBitrate: bitrate,
+			})
+		}
+	}
+	withBitrate := func(format string) func() {
+		return func() { a.promptAudioBitrate(format, audio) }
+	}
+
+	a.mu.Lock()
+	quality := a.videoQuality
+	codec := a.videoCodec
+	a.mu.Unlock()
+	if quality == "tct" {
+		quality = "best"
+	}
+
+	defaultLabel := a.downloadCfg.AudioFormat
+	if a.downloadCfg.AudioBitrate != "" {
+		defaultLabel += " " + a.downloadCfg.AudioBitrate
+	}
+
+	a.showMenu(a.strings.DownloadAs, []menuItem{
+		{a.strings.DownloadDefaultAudio + " (" + defaultLabel + ")", 'd', audio(a.downloadCfg.AudioFormat, a.downloadCfg.AudioBitrate)},
+		{"MP3", 'm', withBitrate("mp3")},
+		{"Opus", 'o', withBitrate("opus")},
+		{"M4A (AAC)", 'a', withBitrate("m4a")},
+		{"FLAC", 'f', audio("flac", "")},
+		{a.strings.DownloadVideo + " (" + qualityLabel(quality) + ", " + codecLabel(codec) + ")", 'v', func() {
+			go a.enqueueDownloads(tracks, fromPlaylist, download.Request{
+				Kind:        download.KindVideo,
+				VideoFormat: engine.BuildYtdlFormat(quality, codec),
+			})
+		}},
+	})
+}
+
+func (a *SimpleApp) promptAudioBitrate(format string, audio func(format, bitrate string) func()) {
+	a.showMenu(a.strings.AudioBitrate, []menuItem{
+		{"128 kbps", '1', audio(format, "128K")},
+		{"192 kbps", '2', audio(format, "192K")},
+		{"256 kbps", '3', audio(format, "256K")},
+		{"320 kbps", '4', audio(format, "320K")},
+		{a.strings.BitrateBest, '0', audio(format, "0")},
+	})
+}
+
+func (a *SimpleApp) enqueueDownloads(tracks []Track, fromPlaylist bool, tmpl download.Request) {
+	positions := map[string]int{}
+	if fromPlaylist {
+		tmpl.Album = a.downloadCfg.PlaylistName
+		a.mu.Lock()
+		for i, t := range a.playlistTracks {
+			if _, ok := positions[t.URL]; !ok {
+				positions[t.URL] = i + 1
+			}
+		}
+		a.mu.Unlock()
+	}
+
+	added := 0
+	for _, t := range tracks {
+		req := tmpl
+		req.URL = t.URL
+		req.Title = t.Title
+		req.Author = t.Author
+		req.TrackNumber = positions[t.URL]
+		if _, err := a.downloads.Enqueue(req); err == nil {
+			added++
+		}
+	}
+
+	a.app.QueueUpdateDraw(func() {
+		if added == 0 {
+			a.setStatus(a.theme.Yellow, "⚠ "+a.strings.DownloadAlreadyQueued)
+			return
+		}
+		a.setStatusf(a.theme.Green, "⬇ "+a.strings.DownloadsQueued, added)
+	})
+}
+
+func (a *SimpleApp) onDownloadChanged(job download.Job) {
+	if job.Status == download.StatusDone {
+		if err := a.data.AddOffline(search.VideoID(job.URL), job.Path); err != nil {
+			a.app.QueueUpdateDraw(func() {
+				a.setStatusf(a.theme.Red, "❌ "+a.strings.StateSaveError, err)
+			})
+		}
+	}
+	a.app.QueueUpdateDraw(func() {
+		a.refreshDownloads()
+		switch job.Status {
+		case download.StatusDone:
+			a.offline.Add(search.VideoID(job.URL), job.Path)
+			a.refreshOfflineBadges()
+			a.setStatusf(a.theme.Green, "✓ "+a.strings.DownloadFinished, job.Title)
+		case download.StatusFailed:
+			a.setStatusf(a.theme.Red, "❌ "+a.strings.DownloadFailed, job.Title)
+		}
+	})
+}
+
+func (a *SimpleApp) setupDownloadsPanel() {
+	a.downloadsTable = tview.NewTable().
+		SetSelectable(true, false).
+		SetSelectedStyle(tcell.StyleDefault.Foreground(a.theme.Crust).Background(a.theme.Blue))
+	a.downloadsTable.SetBackgroundColor(a.theme.Base)
+	a.downloadsTable.SetBorder(true).
+		SetTitleColor(a.theme.Text).
+		SetBorderColor(a.theme.Blue)
+
+	footer := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText(a.strings.DownloadsHint)
+	footer.SetBackgroundColor(a.theme.Mantle)
+	footer.SetTextColor(a.theme.Subtext0)
+
+	a.downloadsTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		job, ok := a.selectedDownload()
+		switch event.Rune() {
+		case 'p':
+			if ok && job.Status == download.StatusPaused {
+				go a.downloads.Resume(job.ID)
+			} else if ok {
+				go a.downloads.Pause(job.ID)
+			}
+			return nil
+		case 'c':
+			if ok {
+				go a.downloads.Cancel(job.ID)
+			}
+			return nil
+		case 'r':
+			if ok {
+				go a.downloads.Retry(job.ID)
+			}
+			return nil
+		case 'x':
+			if ok {
+				go func() {
+					a.downloads.Remove(job.ID)
+					a.app.QueueUpdateDraw(a.refreshDownloads)
+				}()
+			}
+			return nil
+		case 'C':
+			go func() {
+				n := a.downloads.ClearFinished()
+				a.app.QueueUpdateDraw(func() {
+					a.refreshDownloads()
+					a.setStatusf(a.theme.Subtext0, "  "+a.strings.DownloadsCleared, n)
+				})
+			}()
+			return nil
+		}
+		return event
+	})
+
+	a.downloadsPanel = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.downloadsTable, 0, 1, true).
+		AddItem(footer, 1, 0, false)
+}
+
+func (a *SimpleApp) showDownloads() {
+	if a.downloadsPanel == nil {
+		a.setupDownloadsPanel()
+	}
+	a.refreshDownloads()
+
+	a.inModal = true
+	a.prevFocused = a.app.GetFocus()
+	a.app.SetRoot(a.downloadsPanel, true)
+	a.app.SetFocus(a.downloadsTable)
+}
+
+func (a *SimpleApp) selectedDownload() (download.Job, bool) {
+	row, _ := a.downloadsTable.GetSelection()
+	if row < 0 || row >= len(a.downloadIDs) {
+		return download.Job{}, false
+	}
+	for _, j := range a.downloads.Jobs() {
+		if j.ID == a.downloadIDs[row] {
+			return j, true
+		}
+	}
+	return download.Job{}, false
+}
+
+func (a *SimpleApp) refreshDownloads() {
+	if a.downloadsTable == nil {
+		return
+	}
+
+	var selectedID string
+	if row, _ := a.downloadsTable.GetSelection(); row >= 0 && row < len(a.downloadIDs) {
+		selectedID = a.downloadIDs[row]
+	}
+
+	jobs := a.downloads.Jobs()
+	active, total := a.downloads.Counts()
+	a.downloadsTable.SetTitle(fmt.Sprintf(" %s [%d/%d] • %s ", a.strings.Downloads, active, total, a.downloads.Dir()))
+	a.downloadsTable.Clear()
+	a.downloadIDs = a.downloadIDs[:0]
+
+	if len(jobs) == 0 {
+		a.downloadsTable.SetCell(0, 0, tview.NewTableCell(a.strings.DownloadsEmpty).
+			SetTextColor(a.theme.Subtext0).
+			SetSelectable(false))
+		return
+	}
+
+	selectedRow := 0
+	for row, job := range jobs {
+		icon, color := a.downloadStatusIcon(job.Status)
+		a.downloadsTable.SetCell(row, 0, tview.NewTableCell(" "+icon).SetTextColor(color))
+		a.downloadsTable.SetCell(row, 1, tview.NewTableCell(tview.Escape(truncate(job.Title, 50))).
+			SetTextColor(a.theme.Text).
+			SetExpansion(1))
+		a.downloadsTable.SetCell(row, 2, tview.NewTableCell(downloadFormatLabel(job)).SetTextColor(a.theme.Sapphire))
+		a.downloadsTable.SetCell(row, 3, tview.NewTableCell(a.downloadProgressBar(job, 20)))
+		a.downloadsTable.SetCell(row, 4, tview.NewTableCell(a.downloadDetail(job)).SetTextColor(a.theme.Subtext0))
+
+		a.downloadIDs = append(a.downloadIDs, job.ID)
+		if job.ID == selectedID {
+			selectedRow = row
+		}
+	}
+	a.downloadsTable.Select(selectedRow, 0)
+}
+
+func (a *SimpleApp) downloadStatusIcon(status download.Status) (string, tcell.Color) {
+	switch status {
+	case download.StatusRunning:
+		return "⬇", a.theme.Blue
+	case download.StatusPaused:
+		return "⏸", a.theme.Yellow
+	case download.StatusDone:
+		return "✓", a.theme.Green
+	case download.StatusFailed:
+		return "✗", a.theme.Red
+	case download.StatusCanceled:
+		return "⊘", a.theme.Overlay0
+	default:
+		return "…", a.theme.Subtext0
+	}
+}
+
+func (a *SimpleApp) downloadProgressBar(job download.Job, width int) string {
+	filled := min(int(job.Progress/100*float64(width)), width)
+	color := a.theme.Blue
+	if job.Status == download.StatusDone {
+		color = a.theme.Green
+	}
+	return fmt.Sprintf("[%s]%s[%s]%s[-] %3.0f%%",
+		colorTag(color), strings.Repeat("█", filled),
+		colorTag(a.theme.Surface1), strings.Repeat("░", width-filled),
+		job.Progress)
+}
+
+func (a *SimpleApp) downloadDetail(job download.Job) string {
+	switch {
+	case job.Status == download.StatusFailed:
+		return tview.Escape(truncate(job.Error, 40))
+	case job.Processing:
+		return a.strings.DownloadProcessing
+	case job.Status == download.StatusRunning && job.Speed > 0:
+		return fmt.Sprintf("%s/s  ETA %02d:%02d", formatBytes(job.Speed), job.ETA/60, job.ETA%60)
+	}
+	return ""
+}
+
+func downloadFormatLabel(job download.Job) string {
+	if job.Kind == download.KindVideo {
+		return "video"
+	}
+	label := job.AudioFormat
+	if label == "" {
+		label = "audio"
+	}
+	if job.AudioBitrate != "" && job.AudioBitrate != "0" {
+		label += " " + job.AudioBitrate
+	}
+	return label
+}
+
+func formatBytes(n float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB"}
+	i := 0
+	for n >= 1024 && i < len(units)-1 {
+		n /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", n, units[i])
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
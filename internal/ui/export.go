@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+)
+
+func (a *SimpleApp) exportSelection(focused interface{}) {
+	list := a.focusedList(focused)
+	if list == nil {
+		return
+	}
+
+	var tracks []Track
+	name := "results"
+	if list == a.playlist {
+		name = "playlist"
+	}
+
+	if list.HasSelection() {
+		tracks = list.SelectedTracks()
+	} else {
+		a.mu.Lock()
+		if list == a.playlist {
+			tracks = make([]Track, len(a.playlistTracks))
+			copy(tracks, a.playlistTracks)
+		} else {
+			tracks = make([]Track, len(a.tracks))
+			copy(tracks, a.tracks)
+		}
+		a.mu.Unlock()
+	}
+
+	if len(tracks) == 0 {
+		a.app.QueueUpdateDraw(func() {
+			a.setStatus(a.theme.Yellow, "⚠ "+a.strings.NoTrackSelected)
+		})
+		return
+	}
+
+	path, err := exportM3U(name, tracks)
+	if err != nil {
+		a.app.QueueUpdateDraw(func() {
+			a.setStatusf(a.theme.Red, "❌ "+a.strings.ExportError, err)
+		})
+		return
+	}
+
+	a.app.QueueUpdateDraw(func() {
+		list.ClearSelection()
+		a.updateCommandBar()
+		a.setStatusf(a.theme.Green, "✓ "+a.strings.Exported, len(tracks), path)
+	})
+}
+
+func exportM3U(name string, tracks []Track) (string, error) {
+	dir := config.GetExportDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.m3u", name, time.Now().Format("20060102-150405")))
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	for _, t := range tracks {
+		secs := durationSeconds(t.Duration)
+		if secs == 0 {
+			secs = -1
+		}
+		fmt.Fprintf(&b, "#EXTINF:%d,%s - %s\n%s\n", secs, t.Author, t.Title, t.URL)
+	}
+
+	return path, os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+func durationSeconds(s string) int {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	total := 0
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return 0
+		}
+		total = total*60 + n
+	}
+	return total
+}
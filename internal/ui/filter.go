@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/rivo/tview"
+)
+
+func (a *SimpleApp) promptListFilter(list *CustomList) {
+	previous := list.Filter()
+	a.showPrompt(a.strings.FilterPrompt, previous, func(text string) {
+		list.SetFilter(text)
+	}, func(text string, ok bool) {
+		if !ok {
+			list.SetFilter(previous)
+			a.updateCommandBar()
+			return
+		}
+		if list.SetFilter(text) == 0 && text != "" {
+			a.setStatusf(a.theme.Yellow, "⚠ "+a.strings.FilterNoMatches, tview.Escape(text))
+		}
+		a.updateCommandBar()
+	})
+}
+
+func (a *SimpleApp) clearListFilter(list *CustomList) {
+	list.SetFilter("")
+	a.updateCommandBar()
+	a.setStatus(a.theme.Subtext0, "  "+a.strings.FilterCleared)
+}
+
+func (a *SimpleApp) filterHint(list *CustomList) string {
+	filter := list.Filter()
+	if filter == "" {
+		return ""
+	}
+	matches, total := list.FilterCount()
+	tag := "[" + colorTag(a.theme.Crust) + ":" + colorTag(a.theme.Teal) + ":b]"
+	return tag + " " + fmt.Sprintf(a.strings.FilterActive, tview.Escape(filter), matches, total) + " [-:-:-] "
+}
@@ -1,11 +1,36 @@
 package ui
 
 import (
+	"fmt"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
+func (a *SimpleApp) focusedList(focused interface{}) *CustomList {
+	switch focused {
+	case a.searchResults.Flex:
+		return a.searchResults
+	case a.playlist.Flex:
+		return a.playlist
+	}
+	return nil
+}
+
 func (a *SimpleApp) handleKeyPress(event *tcell.EventKey, focused tview.Primitive) *tcell.EventKey {
+	if event.Key() == tcell.KeyEsc {
+		if list := a.focusedList(focused); list != nil && list.HasSelection() {
+			list.ClearSelection()
+			a.updateCommandBar()
+			return nil
+		}
+		if list := a.focusedList(focused); list != nil && list.Filter() != "" {
+			a.clearListFilter(list)
+			return nil
+		}
+		return event
+	}
+
 	switch event.Rune() {
 	case 'j':
 		if focused == a.searchResults.Flex {
@@ -43,19 +68,47 @@ func (a *SimpleApp) handleKeyPress(event *tcell.EventKey, focused tview.Primitiv
 			return nil
 		}
 
+	case 'v', 'V':
+		if list := a.focusedList(focused); list != nil {
+			list.ToggleVisual()
+			a.updateCommandBar()
+			return nil
+		}
+
+	case 'x':
+		if list := a.focusedList(focused); list != nil {
+			list.ToggleMark()
+			a.updateCommandBar()
+			return nil
+		}
+
 	case 'y':
 		go a.yankURL(focused)
 		return nil
 
+	case 'e':
+		if a.focusedList(focused) != nil {
+			go a.exportSelection(focused)
+			return nil
+		}
+
 	case 'a':
 		if focused == a.searchResults.Flex {
-			track := a.searchResults.GetCurrentTrack()
-			if track != nil {
-				go a.addToPlaylist(*track)
+			tracks := a.searchResults.SelectedTracks()
+			if len(tracks) > 0 {
+				a.searchResults.ClearSelection()
+				a.updateCommandBar()
+				go a.addTracksToPlaylist(tracks)
 			}
 			return nil
 		}
 
+	case 'D':
+		if list := a.focusedList(focused); list != nil {
+			a.promptDownload(list.SelectedTracks(), list == a.playlist)
+			return nil
+		}
+
 	case 'A':
 		if focused == a.searchResults.Flex {
 			go a.addAllToPlaylist()
@@ -64,22 +117,64 @@ func (a *SimpleApp) handleKeyPress(event *tcell.EventKey, focused tview.Primitiv
 
 	case 'd':
 		if focused == a.playlist.Flex {
-			idx := a.playlist.GetCurrentItem()
-			go a.removeFromPlaylist(idx)
+			idxs := a.playlist.SelectedIndices()
+			go a.removeFromPlaylist(idxs)
 			return nil
 		}
 
 	case 'J':
 		if focused == a.playlist.Flex {
-			idx := a.playlist.GetCurrentItem()
-			go a.movePlaylistItem(idx, idx+1)
+			go a.shiftPlaylistSelection(1)
 			return nil
 		}
 
 	case 'K':
 		if focused == a.playlist.Flex {
-			idx := a.playlist.GetCurrentItem()
-			go a.movePlaylistItem(idx, idx-1)
+			go a.shiftPlaylistSelection(-1)
+			return nil
+		}
+
+	case 'T':
+		if focused == a.playlist.Flex {
+			idxs := a.playlist.SelectedIndices()
+			go a.movePlaylistItems(idxs, 0)
+			return nil
+		}
+
+	case 'B':
+		if focused == a.playlist.Flex {
+			idxs := a.playlist.SelectedIndices()
+			go a.movePlaylistItems(idxs, a.playlist.ItemCount())
+			return nil
+		}
+
+	case 'M':
+		if focused == a.playlist.Flex {
+			a.promptMovePlaylistItems()
+			return nil
+		}
+
+	case 'X':
+		if focused == a.playlist.Flex {
+			a.cutPlaylistItems()
+			return nil
+		}
+
+	case 'P':
+		if focused == a.playlist.Flex {
+			go a.pastePlaylistItems(false)
+			return nil
+		}
+
+	case 'u':
+		if focused == a.playlist.Flex {
+			a.toggleShuffleOrderView()
+			return nil
+		}
+
+	case 'o':
+		if focused == a.playlist.Flex {
+			a.showPlaylistActions()
 			return nil
 		}
 
@@ -129,15 +224,28 @@ func (a *SimpleApp) handleKeyPress(event *tcell.EventKey, focused tview.Primitiv
 		}
 
 	case 'n':
+		if list := a.focusedList(focused); list != nil && list.Filter() != "" {
+			list.JumpMatch(1)
+			return nil
+		}
 		if focused == a.playerBox {
 			go a.playNext()
 			return nil
 		}
 
+	case 'N':
+		if list := a.focusedList(focused); list != nil && list.Filter() != "" {
+			list.JumpMatch(-1)
+			return nil
+		}
+
 	case 'p':
 		if focused == a.playerBox {
 			go a.playPrevious()
 			return nil
+		} else if focused == a.playlist.Flex {
+			go a.pastePlaylistItems(true)
+			return nil
 		}
 
 	case 'm':
@@ -145,6 +253,35 @@ func (a *SimpleApp) handleKeyPress(event *tcell.EventKey, focused tview.Primitiv
 		return nil
 
 	case '/':
+		if list := a.focusedList(focused); list != nil {
+			a.promptListFilter(list)
+			return nil
+		}
+		a.app.SetFocus(a.searchInput)
+		a.updateCommandBar()
+		return nil
+
+	case 'f':
+		if list := a.focusedList(focused); list != nil {
+			a.toggleFavorite(list)
+			return nil
+		}
+
+	case 'F':
+		go a.showFavorites()
+		return nil
+
+	case 'S':
+		a.showSmartPlaylists()
+		return nil
+
+	case '0', '1', '2', '3', '4', '5':
+		if list := a.focusedList(focused); list != nil {
+			a.rateTracks(list, int(event.Rune()-'0'))
+			return nil
+		}
+
+	case 'i':
 		a.app.SetFocus(a.searchInput)
 		a.updateCommandBar()
 		return nil
@@ -184,11 +321,11 @@ func (a *SimpleApp) updateCommandBar() {
 
 	case a.searchResults.Flex:
 		a.searchResults.SetBorderColor(a.theme.Blue)
-		help = a.strings.CmdResultsBar
+		help = a.filterHint(a.searchResults) + a.selectionHint(a.searchResults) + a.strings.CmdResultsBar
 
 	case a.playlist.Flex:
 		a.playlist.SetBorderColor(a.theme.Blue)
-		help = a.strings.CmdPlaylistBar
+		help = a.filterHint(a.playlist) + a.selectionHint(a.playlist) + a.strings.CmdPlaylistBar
 
 	case a.playerBox:
 		a.playerBox.SetBorderColor(a.theme.Blue)
@@ -200,3 +337,14 @@ func (a *SimpleApp) updateCommandBar() {
 
 	a.commandBar.SetText(help)
 }
+
+func (a *SimpleApp) selectionHint(list *CustomList) string {
+	tag := "[" + colorTag(a.theme.Crust) + ":" + colorTag(a.theme.Mauve) + ":b]"
+	if list.InVisualMode() {
+		return tag + " " + a.strings.VisualMode + " [-:-:-] "
+	}
+	if n := list.MarkedCount(); n > 0 {
+		return tag + " " + fmt.Sprintf(a.strings.MarkedCount, n) + " [-:-:-] "
+	}
+	return ""
+}
@@ -0,0 +1,263 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/history"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func (a *SimpleApp) historyEntries() []history.Entry {
+	a.mu.Lock()
+	data := a.data
+	a.mu.Unlock()
+	if data == nil {
+		return nil
+	}
+	entries, err := data.History()
+	if err != nil {
+		return nil
+	}
+	return entries
+}
+
+func (a *SimpleApp) onHistoryChanged() {
+	a.mu.Lock()
+	data := a.data
+	a.mu.Unlock()
+	if data != nil {
+		if on, err := data.Incognito(); err == nil {
+			a.mu.Lock()
+			a.incognito = on
+			a.mu.Unlock()
+		}
+	}
+	a.app.QueueUpdateDraw(a.refreshHistory)
+	a.refreshSmartPlaylist()
+}
+
+func (a *SimpleApp) toggleIncognito() {
+	a.mu.Lock()
+	on := !a.incognito
+	data := a.data
+	a.mu.Unlock()
+	if data == nil {
+		return
+	}
+
+	if err := data.SetIncognito(on); err != nil {
+		a.setStatusf(a.theme.Red, "❌ "+a.strings.HistoryError, err)
+		return
+	}
+	a.mu.Lock()
+	a.incognito = on
+	a.mu.Unlock()
+
+	if on {
+		a.setStatus(a.theme.Mauve, "🕶 "+a.strings.IncognitoOn)
+	} else {
+		a.setStatus(a.theme.Subtext0, "  "+a.strings.IncognitoOff)
+	}
+	a.refreshHistory()
+}
+
+func historyTrack(e history.Entry) Track {
+	return Track{
+		Title:     e.Title,
+		Author:    e.Author,
+		URL:       e.URL,
+		Thumbnail: e.Thumbnail,
+		Duration:  e.Duration,
+	}
+}
+
+func (a *SimpleApp) setupHistoryPanel() {
+	a.historySearch = tview.NewInputField().
+		SetLabel(" / ").
+		SetLabelColor(a.theme.Mauve).
+		SetFieldBackgroundColor(a.theme.Surface0).
+		SetFieldTextColor(a.theme.Text).
+		SetPlaceholder(a.strings.HistorySearch).
+		SetPlaceholderTextColor(a.theme.Overlay0)
+	a.historySearch.SetBackgroundColor(a.theme.Mantle)
+	a.historySearch.SetChangedFunc(func(string) {
+		a.refreshHistory()
+	})
+	a.historySearch.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEsc {
+			a.historySearch.SetText("")
+		}
+		a.app.SetFocus(a.historyTable)
+	})
+
+	a.historyTable = tview.NewTable().
+		SetSelectable(true, false).
+		SetSelectedStyle(tcell.StyleDefault.Foreground(a.theme.Crust).Background(a.theme.Blue))
+	a.historyTable.SetBackgroundColor(a.theme.Base)
+	a.historyTable.SetBorder(true).
+		SetTitleColor(a.theme.Text).
+		SetBorderColor(a.theme.Blue)
+
+	footer := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText(a.strings.HistoryHint)
+	footer.SetBackgroundColor(a.theme.Mantle)
+	footer.SetTextColor(a.theme.Subtext0)
+
+	a.historyTable.SetSelectedFunc(func(row, _ int) {
+		if e, ok := a.historyEntryAt(row); ok {
+			go a.playTrackDirect(historyTrack(e))
+		}
+	})
+
+	a.historyTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() != 'C' {
+			a.historyClearArmed = false
+		}
+		switch event.Rune() {
+		case '/':
+			a.app.SetFocus(a.historySearch)
+			return nil
+		case 'a':
+			row, _ := a.historyTable.GetSelection()
+			if e, ok := a.historyEntryAt(row); ok {
+				go a.addToPlaylist(historyTrack(e))
+			}
+			return nil
+		case 'I':
+			a.toggleIncognito()
+			return nil
+		case 'C':
+			if !a.historyClearArmed {
+				a.historyClearArmed = true
+				a.setStatus(a.theme.Yellow, "⚠ "+a.strings.HistoryClearConfirm)
+				return nil
+			}
+			a.historyClearArmed = false
+			if err := a.data.ClearHistory(); err != nil {
+				a.setStatusf(a.theme.Red, "❌ "+a.strings.HistoryError, err)
+				return nil
+			}
+			a.refreshHistory()
+			a.setStatus(a.theme.Subtext0, "  "+a.strings.HistoryCleared)
+			return nil
+		}
+		return event
+	})
+
+	a.historyPanel = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.historySearch, 1, 0, false).
+		AddItem(a.historyTable, 0, 1, true).
+		AddItem(footer, 1, 0, false)
+}
+
+func (a *SimpleApp) showHistory() {
+	if a.historyPanel == nil {
+		a.setupHistoryPanel()
+	}
+	a.refreshHistory()
+
+	a.inModal = true
+	a.prevFocused = a.app.GetFocus()
+	a.app.SetRoot(a.historyPanel, true)
+	a.app.SetFocus(a.historyTable)
+}
+
+func (a *SimpleApp) historyEntryAt(row int) (history.Entry, bool) {
+	if row < 0 || row >= len(a.historyRows) || a.historyRows[row] == nil {
+		return history.Entry{}, false
+	}
+	return *a.historyRows[row], true
+}
+
+func (a *SimpleApp) refreshHistory() {
+	if a.historyTable == nil {
+		return
+	}
+
+	selectedRow, _ := a.historyTable.GetSelection()
+	query := a.historySearch.GetText()
+
+	entries := a.historyEntries()
+	title := fmt.Sprintf(" %s [%d] ", a.strings.History, len(entries))
+	a.mu.Lock()
+	if a.incognito {
+		title += "• 🕶 " + a.strings.Incognito + " "
+	}
+	a.mu.Unlock()
+	a.historyTable.SetTitle(title)
+	a.historyTable.Clear()
+	a.historyRows = a.historyRows[:0]
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var lastDay time.Time
+	row := 0
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if query != "" && !fuzzyMatch(query, e.Title+" "+e.Author) {
+			continue
+		}
+
+		started := e.StartedAt.Local()
+		day := time.Date(started.Year(), started.Month(), started.Day(), 0, 0, 0, 0, now.Location())
+		if !day.Equal(lastDay) {
+			lastDay = day
+			a.historyTable.SetCell(row, 0, tview.NewTableCell(" "+a.historyDayLabel(day, today)).
+				SetTextColor(a.theme.Mauve).
+				SetAttributes(tcell.AttrBold).
+				SetSelectable(false))
+			a.historyRows = append(a.historyRows, nil)
+			row++
+		}
+
+		icon, color := "◐", a.theme.Yellow
+		if e.Completed {
+			icon, color = "✓", a.theme.Green
+		}
+		listened := fmt.Sprintf("%d:%02d", int(e.Listened)/60, int(e.Listened)%60)
+		if e.Duration != "" {
+			listened += " / " + e.Duration
+		}
+
+		a.historyTable.SetCell(row, 0, tview.NewTableCell("   "+started.Format("15:04")).SetTextColor(a.theme.Subtext0))
+		a.historyTable.SetCell(row, 1, tview.NewTableCell(icon).SetTextColor(color))
+		a.historyTable.SetCell(row, 2, tview.NewTableCell(tview.Escape(truncate(e.Title, 60))).
+			SetTextColor(a.theme.Text).
+			SetExpansion(1))
+		a.historyTable.SetCell(row, 3, tview.NewTableCell(tview.Escape(truncate(e.Author, 30))).SetTextColor(a.theme.Sapphire))
+		a.historyTable.SetCell(row, 4, tview.NewTableCell(listened).SetTextColor(a.theme.Subtext0))
+
+		entry := e
+		a.historyRows = append(a.historyRows, &entry)
+		row++
+	}
+
+	if row == 0 {
+		a.historyTable.SetCell(0, 0, tview.NewTableCell(a.strings.HistoryEmpty).
+			SetTextColor(a.theme.Subtext0).
+			SetSelectable(false))
+		return
+	}
+
+	if selectedRow >= row {
+		selectedRow = row - 1
+	}
+	for selectedRow < row && a.historyRows[max(selectedRow, 0)] == nil {
+		selectedRow++
+	}
+	a.historyTable.Select(max(selectedRow, 0), 0)
+}
+
+func (a *SimpleApp) historyDayLabel(day, today time.Time) string {
+	switch {
+	case day.Equal(today):
+		return a.strings.HistoryToday
+	case day.Equal(today.AddDate(0, 0, -1)):
+		return a.strings.HistoryYesterday
+	}
+	return day.Format("2006-01-02 (Mon)")
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/hooks"
+)
+
+func startHooks(ctrl engine.Controller, onError func(event string, err error)) (*hooks.Runner, error) {
+	cfg, _ := config.LoadConfig()
+	h := cfg.Hooks
+	commands := map[string]string{
+		hooks.Start:       h.OnStart,
+		hooks.End:         h.OnEnd,
+		hooks.Pause:       h.OnPause,
+		hooks.Resume:      h.OnResume,
+		hooks.PlaylistEnd: h.OnPlaylistEnd,
+		hooks.Error:       h.OnError,
+	}
+	configured := false
+	for _, cmd := range commands {
+		configured = configured || cmd != ""
+	}
+	if !configured {
+		return nil, nil
+	}
+
+	return hooks.Attach(ctrl, hooks.Options{
+		Commands: commands,
+		Timeout:  time.Duration(h.Timeout) * time.Second,
+		OnError:  onError,
+	})
+}
+
+func (a *SimpleApp) onHookError(event string, err error) {
+	a.app.QueueUpdateDraw(func() {
+		a.setStatusf(a.theme.Yellow, "⚠ "+a.strings.HookFailed, event, err)
+	})
+}
+
+func (a *SimpleApp) onHooksDisabled(err error) {
+	a.app.QueueUpdateDraw(func() {
+		a.setStatusf(a.theme.Yellow, "⚠ "+a.strings.HooksDisabled, err)
+	})
+}
@@ -30,18 +30,17 @@ type Strings struct {
 	RepeatAll string
 	NoRepeat  string
 
-	NoTrackPlaying         string
-	Playing                string
-	PlayingWithoutPlaylist string
-	Paused                 string
-	Stopped                string
-	PlaylistFinished       string
-	PlaybackFinished       string
-	SkippingTo             string
-	EnteringPlaylist       string
-	AlreadyLastSong        string
-	AlreadyFirstSong       string
-	NothingPlaying         string
+	NoTrackPlaying           string
+	Playing                  string
+	PlayingWithoutPlaylist   string
+	Paused                   string
+	Stopped                  string
+	PlaylistFinished         string
+	PlaylistChangedElsewhere string
+	PlaybackFinished         string
+	AlreadyLastSong          string
+	AlreadyFirstSong         string
+	NothingPlaying           string
 
 	AddedToPlaylist     string
 	RemovedFromPlaylist string
@@ -112,8 +111,8 @@ type Strings struct {
 	HelpGlobalText     string
 	HelpIconsText      string
 
-	ConfigText  string
-	EscToClose  string
+	ConfigText string
+	EscToClose string
 
 	URLCopied        string
 	NoTrackSelected  string
@@ -122,6 +121,123 @@ type Strings struct {
 	LoadingPlaylist  string
 	PlaylistImported string
 
+	RemovedManyFromPlaylist string
+	URLsCopied              string
+	Exported                string
+	ExportError             string
+	VisualMode              string
+	MarkedCount             string
+	MoveToPrompt            string
+	InvalidPosition         string
+	MovedTo                 string
+	ItemsCut                string
+	NothingToPaste          string
+
+	PlaylistActions          string
+	SortByTitle              string
+	SortByAuthor             string
+	SortByDuration           string
+	SortByAdded              string
+	ReverseOrder             string
+	RemoveDuplicates         string
+	ShuffleOrder             string
+	PlaylistSorted           string
+	PlaylistReversed         string
+	PlaylistShuffled         string
+	DuplicatesRemoved        string
+	ShuffleOrderShown        string
+	ShuffleOrderHidden       string
+	ShuffleOrderNeedsShuffle string
+	FilterPrompt             string
+	FilterActive             string
+	FilterNoMatches          string
+	FilterCleared            string
+	Downloads                string
+	DownloadAs               string
+	DownloadDefaultAudio     string
+	DownloadVideo            string
+	AudioBitrate             string
+	BitrateBest              string
+	DownloadsQueued          string
+	DownloadAlreadyQueued    string
+	DownloadFinished         string
+	DownloadFailed           string
+	DownloadProcessing       string
+	DownloadsEmpty           string
+	DownloadsHint            string
+	DownloadsCleared         string
+	LibraryScanning          string
+	LibraryScanError         string
+	LibraryEmpty             string
+	LibraryLoaded            string
+	PlayingOffline           string
+	MakeOffline              string
+	PlaylistAlreadyOffline   string
+	History                  string
+	HistoryToday             string
+	HistoryYesterday         string
+	HistorySearch            string
+	HistoryEmpty             string
+	HistoryHint              string
+	HistoryClearConfirm      string
+	HistoryCleared           string
+	HistoryError             string
+	Incognito                string
+	IncognitoOn              string
+	IncognitoOff             string
+	Stats                    string
+	StatsWeek                string
+	StatsMonth               string
+	StatsYear                string
+	StatsAll                 string
+	StatsTotal               string
+	StatsPlays               string
+	StatsCompleted           string
+	StatsActiveDays          string
+	StatsStreak              string
+	StatsTopTracks           string
+	StatsTopChannels         string
+	StatsByHour              string
+	StatsByWeekday           string
+	StatsWeekdays            string
+	StatsEmpty               string
+	StatsHint                string
+	StatsExported            string
+	SortByRating             string
+	FavoriteAdded            string
+	FavoriteRemoved          string
+	FavoritesAdded           string
+	FavoritesRemoved         string
+	Rated                    string
+	RatingCleared            string
+	FavoritesEmpty           string
+	FavoritesLoaded          string
+	SmartPlaylists           string
+	SmartPlaylistsEmpty      string
+	SmartPlaylistError       string
+	SmartPlaylistLoaded      string
+	SmartPlaylistNoMatches   string
+	StoreError               string
+	StateSaveError           string
+	StateRestoreError        string
+	RecoveryText             string
+	RecoveryRestore          string
+	RecoveryStartFresh       string
+	RecoveryContinue         string
+	RecoveryRestored         string
+	RecoveryFresh            string
+	VolumeSet                string
+	PlayerDisconnected       string
+	NowPlayingError          string
+	HookFailed               string
+	HooksDisabled            string
+	ScrobbleDisabled         string
+	ScrobbleFailed           string
+	ScrobbleAuthFailed       string
+	DiscordButton            string
+	DiscordByAuthor          string
+	DiscordError             string
+
 	EmptyQuery       string
 	NoResultsFor     string
 	YtDlpNotFound    string
@@ -131,19 +247,14 @@ type Strings struct {
 	TerminalVideo         string
 	TerminalVideoStarting string
 
-	warnFailedKillMpv     string
-	errorPause            string
-	socatNotInstalled     string
-	socatCmdNotFound      string
-	nothingPlaying        string
-	youtubeBlocked        string
-	errorStartMpv         string
-	warnFailedKillPrevMpv string
-	stateRestored         string
-	ytDlpOutdated         string
-	shuffle               string
-	repeatOne             string
-	repeatAll             string
+	socatNotInstalled string
+	youtubeBlocked    string
+	errorStartMpv     string
+	stateRestored     string
+	ytDlpOutdated     string
+	shuffle           string
+	repeatOne         string
+	repeatAll         string
 }
 
 var translations = map[Language]Strings{
@@ -170,18 +281,17 @@ var translations = map[Language]Strings{
 		RepeatAll: "Repetir Todas",
 		NoRepeat:  "Sem Repetição",
 
-		NoTrackPlaying:         "Nenhuma faixa tocando",
-		Playing:                "Tocando",
-		PlayingWithoutPlaylist: "Tocando: %s (sem playlist)",
-		Paused:                 "Pausado",
-		Stopped:                "Parado",
-		PlaylistFinished:       "Playlist finalizada",
-		PlaybackFinished:       "Reprodução finalizada",
-		SkippingTo:             "Pulando para: %d/%d - %s",
-		EnteringPlaylist:       "Entrando na playlist...",
-		AlreadyLastSong:        "Já está na última música",
-		AlreadyFirstSong:       "Já está na primeira música",
-		NothingPlaying:         "Nada tocando. Inicie a playlist primeiro.",
+		NoTrackPlaying:           "Nenhuma faixa tocando",
+		Playing:                  "Tocando",
+		PlayingWithoutPlaylist:   "Tocando: %s (sem playlist)",
+		Paused:                   "Pausado",
+		Stopped:                  "Parado",
+		PlaylistFinished:         "Playlist finalizada",
+		PlaylistChangedElsewhere: "Playlist alterada por outro cliente, edição descartada",
+		PlaybackFinished:         "Reprodução finalizada",
+		AlreadyLastSong:          "Já está na última música",
+		AlreadyFirstSong:         "Já está na primeira música",
+		NothingPlaying:           "Nada tocando. Inicie a playlist primeiro.",
 
 		AddedToPlaylist:     "Adicionado: %s",
 		RemovedFromPlaylist: "Removido da playlist",
@@ -239,17 +349,17 @@ var translations = map[Language]Strings{
 		Page:             "Página",
 
 		CmdSearchBar:   "Digite para buscar (ou cole URL) | [#89b4fa]Enter[-] Buscar | [#89b4fa]Tab[-] Próximo | [#f38ba8]Ctrl+Q[-] Sair | [#cba6f7]Ctrl+C[-] Config",
-		CmdResultsBar:  "[#89b4fa]j/k[-] Nav | [#89b4fa]Enter[-] Tocar | [#a6e3a1]a[-] Add | [#a6e3a1]A[-] Add todos | [#94e2d5]y[-] Copiar URL | [#cba6f7][ ][-] Pág | [#89b4fa]/[-] Buscar | [#f38ba8]Ctrl+Q[-] Sair",
-		CmdPlaylistBar: "[#89b4fa]j/k[-] Nav | [#89b4fa]Enter[-] Tocar | [#f38ba8]d[-] Del | [#cba6f7]J/K[-] Move | [#94e2d5]y[-] Copiar URL | [#fab387]r[-] Repetir | [#94e2d5]h[-] Aleatório | [#f38ba8]Ctrl+Q[-] Sair",
+		CmdResultsBar:  "[#89b4fa]j/k[-] Nav | [#cba6f7]v/x[-] Sel | [#89b4fa]Enter[-] Tocar | [#a6e3a1]a[-] Add | [#a6e3a1]A[-] Add todos | [#94e2d5]y[-] Copiar URL | [#cba6f7][ ][-] Pág | [#89b4fa]/[-] Filtrar | [#89b4fa]i[-] Buscar | [#f38ba8]Ctrl+Q[-] Sair",
+		CmdPlaylistBar: "[#89b4fa]j/k[-] Nav | [#cba6f7]v/x[-] Sel | [#89b4fa]Enter[-] Tocar | [#f38ba8]d[-] Del | [#cba6f7]J/K/M[-] Move | [#94e2d5]y[-] Copiar URL | [#f9e2af]o[-] Ações | [#fab387]r[-] Repetir | [#94e2d5]h[-] Aleatório | [#f38ba8]Ctrl+Q[-] Sair",
 		CmdPlayerBar:   "[#a6e3a1]Space[-] Pausa | [#89dceb]n/p[-] Next/Prev | [#fab387]h/l[-] ±5s | [#fab387]H/L[-] ±30s | [#f38ba8]s[-] Parar | [#94e2d5]y[-] Copiar URL | [#cba6f7]m[-] Modo | [#f38ba8]Ctrl+Q[-] Sair",
 		CmdDefaultBar:  "[#89b4fa]Tab[-] Navegar entre painéis | [#94e2d5]y[-] Copiar URL | [#f38ba8]Ctrl+Q[-] Sair | [#cba6f7]Ctrl+C[-] Config",
 
-		HelpNavigationText: "  Tab         Alternar entre painéis (Busca → Resultados → Playlist → Player)\n  /           Focar na busca\n  ↑/↓  j/k    Navegar nas listas\n  g / G       Ir ao topo / fim da lista\n  ?           Mostrar esta ajuda",
+		HelpNavigationText: "  Tab         Alternar entre painéis (Busca → Resultados → Playlist → Player)\n  /           Filtrar lista focada (ou focar na busca)\n  i           Focar na busca\n  n / N       Próximo / anterior item filtrado\n  Esc         Limpar filtro\n  ↑/↓  j/k    Navegar nas listas\n  g / G       Ir ao topo / fim da lista\n  ?           Mostrar esta ajuda",
 		HelpSearchText:     "  Digite    Texto para buscar ou cole uma URL do YouTube\n  Enter     Executar busca / tocar URL / importar playlist",
-		HelpResultsText:    "  Enter     Tocar faixa diretamente (sem playlist)\n  a         Adicionar à playlist\n  A         Adicionar todos à playlist\n  y         Copiar URL da faixa\n  [ ]       Navegar entre páginas (anterior/próxima)",
-		HelpPlaylistText:   "  Enter     Tocar faixa da playlist\n  Space     Tocar playlist do início\n  d         Remover item\n  J         Mover item para baixo\n  K         Mover item para cima\n  r         Ciclar repetição (󰑗 → 󰑘 → 󰑖 → 󰑗)\n  h         Toggle shuffle ()",
+		HelpResultsText:    "  Enter     Tocar faixa diretamente (sem playlist)\n  a         Adicionar à playlist (ou seleção)\n  A         Adicionar todos à playlist\n  y         Copiar URL da faixa (ou seleção)\n  [ ]       Navegar entre páginas (anterior/próxima)\n  v / V     Modo visual (selecionar intervalo)\n  x         Marcar/desmarcar item\n  e         Exportar seleção (ou lista) para M3U\n  D         Baixar item/seleção (áudio ou vídeo)\n  f         Favoritar/desfavoritar (ou seleção)\n  1-5 / 0   Avaliar com estrelas / limpar avaliação\n  F         Mostrar favoritos\n  Esc       Limpar seleção",
+		HelpPlaylistText:   "  Enter     Tocar faixa da playlist\n  Space     Tocar playlist do início\n  d         Remover item (ou seleção)\n  J         Mover item/seleção para baixo\n  K         Mover item/seleção para cima\n  T / B     Mover para o topo / fim\n  M         Mover para a posição N\n  X         Recortar item/seleção\n  p / P     Colar depois / antes do cursor\n  o         Ações: ordenar, inverter, remover duplicadas, embaralhar, offline\n  r         Ciclar repetição (󰑗 → 󰑘 → 󰑖 → 󰑗)\n  h         Toggle shuffle ()\n  u         Mostrar/ocultar ordem do shuffle\n  v / V     Modo visual (selecionar intervalo)\n  x         Marcar/desmarcar item\n  y         Copiar URL (ou seleção)\n  e         Exportar seleção (ou playlist) para M3U\n  D         Baixar item/seleção (áudio ou vídeo)\n  f         Favoritar/desfavoritar (ou seleção)\n  1-5 / 0   Avaliar com estrelas / limpar avaliação\n  Esc       Limpar seleção",
 		HelpPlayerText:     "  Space     Pause/Play\n  s         Stop\n  n         Próxima música\n  p         Música anterior\n  h / l     Voltar / Avançar 5 segundos\n  H / L     Voltar / Avançar 30 segundos",
-		HelpGlobalText:     "  m         Alternar áudio/vídeo\n  y         Copiar URL (faixa tocando ou selecionada)\n  Ctrl+Q    Sair da aplicação\n  Ctrl+C    Configurações\n  ?         Esta janela de atalhos\n  Esc       Fechar janela/modal",
+		HelpGlobalText:     "  m         Alternar áudio/vídeo\n  y         Copiar URL (faixa tocando ou selecionada)\n  Ctrl+Q    Sair da aplicação\n  Ctrl+C    Configurações\n  Ctrl+D    Downloads (p pausar, c cancelar, r tentar de novo)\n  Ctrl+L    Biblioteca local (arquivos baixados e pastas configuradas)\n  S         Playlists inteligentes (regras em smart_playlists.toml)\n  Ctrl+R    Histórico (/ buscar, a adicionar, I anônimo, C C limpar)\n  Ctrl+T    Estatísticas (w/m/y/a período, c CSV, J JSON)\n  ?         Esta janela de atalhos\n  Esc       Fechar janela/modal",
 		HelpIconsText:      "  󰑗 Sem Repetição  󰑘 Repetir Uma  󰑖 Repetir Todas   Aleatório",
 
 		ConfigText: "⚙️  CONFIGURAÇÕES\n\nEscolha uma opção abaixo para configurar o YouTui.\nUse as setas ←/→ para navegar e Enter para selecionar.\n\nPressione Esc para fechar.",
@@ -262,6 +372,123 @@ var translations = map[Language]Strings{
 		LoadingPlaylist:  "Importando playlist...",
 		PlaylistImported: "Adicionadas %d faixas à playlist",
 
+		RemovedManyFromPlaylist: "%d itens removidos da playlist",
+		URLsCopied:              "%d URLs copiadas",
+		Exported:                "%d faixas exportadas para %s",
+		ExportError:             "Erro ao exportar: %v",
+		VisualMode:              "-- VISUAL --",
+		MarkedCount:             "%d marcados",
+		MoveToPrompt:            "Mover para a posição (1-%d):",
+		InvalidPosition:         "Posição inválida: %s",
+		MovedTo:                 "Movido para a posição %d",
+		ItemsCut:                "%d itens recortados (p/P para colar)",
+		NothingToPaste:          "Nada para colar",
+
+		PlaylistActions:          "Ações da playlist",
+		SortByTitle:              "Ordenar por título",
+		SortByAuthor:             "Ordenar por autor",
+		SortByDuration:           "Ordenar por duração",
+		SortByAdded:              "Ordenar por data de adição",
+		ReverseOrder:             "Inverter ordem",
+		RemoveDuplicates:         "Remover duplicadas",
+		ShuffleOrder:             "Embaralhar ordem (permanente)",
+		PlaylistSorted:           "Playlist: %s",
+		PlaylistReversed:         "Ordem da playlist invertida",
+		PlaylistShuffled:         "Ordem da playlist embaralhada",
+		DuplicatesRemoved:        "%d duplicadas removidas",
+		ShuffleOrderShown:        "Ordem do shuffle visível",
+		ShuffleOrderHidden:       "Ordem do shuffle oculta",
+		ShuffleOrderNeedsShuffle: "Ative o shuffle (h) para ver a ordem",
+		FilterPrompt:             "Filtrar:",
+		FilterActive:             "filtro \"%s\" %d/%d",
+		FilterNoMatches:          "Nenhum item corresponde a \"%s\"",
+		FilterCleared:            "Filtro removido",
+		Downloads:                "Downloads",
+		DownloadAs:               "Baixar como",
+		DownloadDefaultAudio:     "Áudio padrão",
+		DownloadVideo:            "Vídeo",
+		AudioBitrate:             "Bitrate do áudio",
+		BitrateBest:              "Melhor (VBR)",
+		DownloadsQueued:          "%d downloads na fila",
+		DownloadAlreadyQueued:    "Já está na fila de downloads",
+		DownloadFinished:         "Download concluído: %s",
+		DownloadFailed:           "Falha no download: %s",
+		DownloadProcessing:       "processando...",
+		DownloadsEmpty:           "Nenhum download. Pressione D em um item para baixar.",
+		DownloadsHint:            "[#89b4fa]j/k[-] Nav | [#f9e2af]p[-] Pausar/Retomar | [#f38ba8]c[-] Cancelar | [#a6e3a1]r[-] Tentar de novo | [#f38ba8]x[-] Remover | [#cba6f7]C[-] Limpar concluídos | [#89b4fa]Esc[-] Fechar",
+		DownloadsCleared:         "%d downloads removidos da lista",
+		LibraryScanning:          "Escaneando biblioteca local...",
+		LibraryScanError:         "Erro ao escanear biblioteca: %v",
+		LibraryEmpty:             "Nenhum arquivo de mídia em %s",
+		LibraryLoaded:            "%d faixas locais",
+		PlayingOffline:           "Tocando (offline)",
+		MakeOffline:              "Disponibilizar playlist offline",
+		PlaylistAlreadyOffline:   "Todas as faixas da playlist já estão offline",
+		History:                  "Histórico",
+		HistoryToday:             "Hoje",
+		HistoryYesterday:         "Ontem",
+		HistorySearch:            "Buscar no histórico...",
+		HistoryEmpty:             "  Nenhuma reprodução registrada",
+		HistoryHint:              "Enter tocar • a adicionar à playlist • / buscar • I anônimo • C C limpar • Esc fechar",
+		HistoryClearConfirm:      "Pressione C novamente para apagar todo o histórico",
+		HistoryCleared:           "Histórico apagado",
+		HistoryError:             "Erro no histórico: %v",
+		Incognito:                "anônimo",
+		IncognitoOn:              "Modo anônimo: reproduções não serão registradas",
+		IncognitoOff:             "Modo anônimo desativado",
+		Stats:                    "Estatísticas",
+		StatsWeek:                "7 dias",
+		StatsMonth:               "Mês",
+		StatsYear:                "Ano",
+		StatsAll:                 "Tudo",
+		StatsTotal:               "Tempo ouvido",
+		StatsPlays:               "Reproduções",
+		StatsCompleted:           "completas",
+		StatsActiveDays:          "Dias ativos",
+		StatsStreak:              "Sequência atual: %d dias • recorde: %d dias",
+		StatsTopTracks:           "Faixas mais ouvidas",
+		StatsTopChannels:         "Canais mais ouvidos",
+		StatsByHour:              "Por hora do dia",
+		StatsByWeekday:           "Por dia da semana",
+		StatsWeekdays:            "Dom,Seg,Ter,Qua,Qui,Sex,Sáb",
+		StatsEmpty:               "Nenhuma reprodução neste período",
+		StatsHint:                "w/m/y/a período • c exportar CSV • J exportar JSON • ↑↓ rolar • Esc fechar",
+		StatsExported:            "Estatísticas exportadas para %s",
+		SortByRating:             "Ordenar por avaliação",
+		FavoriteAdded:            "Favoritada: %s",
+		FavoriteRemoved:          "Removida dos favoritos: %s",
+		FavoritesAdded:           "%d faixas favoritadas",
+		FavoritesRemoved:         "%d faixas removidas dos favoritos",
+		Rated:                    "%d faixa(s) avaliada(s) %s",
+		RatingCleared:            "Avaliação removida de %d faixa(s)",
+		FavoritesEmpty:           "Nenhuma faixa favorita ainda (f para favoritar)",
+		FavoritesLoaded:          "Favoritos: %d faixas",
+		SmartPlaylists:           "Playlists inteligentes",
+		SmartPlaylistsEmpty:      "Nenhuma playlist inteligente definida em %s",
+		SmartPlaylistError:       "Erro nas playlists inteligentes: %v",
+		SmartPlaylistLoaded:      "%s: %d faixas",
+		SmartPlaylistNoMatches:   "Nenhuma faixa corresponde a \"%s\"",
+		StoreError:               "Erro ao abrir o banco de dados local: %v",
+		StateSaveError:           "Erro ao salvar o estado: %v",
+		StateRestoreError:        "Erro ao restaurar o estado: %v",
+		RecoveryText:             "O estado salvo não pôde ser lido:\n%v\n\n%s\n\nRestaurar um backup ou começar do zero? O arquivo danificado será mantido com o sufixo .corrupt.",
+		RecoveryRestore:          "Restaurar backup de %s",
+		RecoveryStartFresh:       "Começar do zero",
+		RecoveryContinue:         "Continuar sem salvar",
+		RecoveryRestored:         "Backup restaurado; arquivo danificado movido para %s",
+		RecoveryFresh:            "Estado reiniciado; arquivo danificado movido para %s",
+		VolumeSet:                "Volume: %d%%",
+		PlayerDisconnected:       "Conexão com o player perdida: %v",
+		NowPlayingError:          "Saída now-playing desativada: %v",
+		HookFailed:               "Hook %s falhou: %v",
+		HooksDisabled:            "Hooks desativados: %v",
+		ScrobbleDisabled:         "Scrobbling desativado: %v",
+		ScrobbleFailed:           "Falha no scrobble: %v",
+		ScrobbleAuthFailed:       "Scrobble pausado, verifique as credenciais: %v",
+		DiscordButton:            "Ouvir no YouTube",
+		DiscordByAuthor:          "por %s",
+		DiscordError:             "Discord Rich Presence: %v",
+
 		EmptyQuery:       "Consulta vazia",
 		NoResultsFor:     "Nenhum resultado para: %q",
 		YtDlpNotFound:    "yt-dlp não encontrado no PATH. Instale com 'pipx install yt-dlp' ou 'pip install --user yt-dlp'",
@@ -271,19 +498,14 @@ var translations = map[Language]Strings{
 		TerminalVideo:         "Terminal (vídeo no terminal)",
 		TerminalVideoStarting: "Iniciando vídeo no terminal... (q para voltar)",
 
-		warnFailedKillMpv:     "falha ao encerrar o processo mpv:",
-		errorPause:            "Erro ao pausar",
-		socatNotInstalled:     "socat não instalado. Instale",
-		socatCmdNotFound:      "socat comando não encontrado",
-		nothingPlaying:        "Nada tocando no momento",
-		youtubeBlocked:        "YouTube bloqueou (403). Atualize yt-dlp: sudo yt-dlp -U",
-		errorStartMpv:         "Erro ao iniciar mpv: ",
-		warnFailedKillPrevMpv: "falha ao encerrar o processo mpv anterior: ",
-		stateRestored:         "Estado restaurado com sucesso!",
-		ytDlpOutdated:         "yt-dlp desatualizado",
-		shuffle:               "Aleatório",
-		repeatOne:             "Repetir 1",
-		repeatAll:             "Repetir tudo",
+		socatNotInstalled: "socat não instalado. Instale",
+		youtubeBlocked:    "YouTube bloqueou (403). Atualize yt-dlp: sudo yt-dlp -U",
+		errorStartMpv:     "Erro ao iniciar mpv: ",
+		stateRestored:     "Estado restaurado com sucesso!",
+		ytDlpOutdated:     "yt-dlp desatualizado",
+		shuffle:           "Aleatório",
+		repeatOne:         "Repetir 1",
+		repeatAll:         "Repetir tudo",
 	},
 
 	LanguageEN: {
@@ -309,18 +531,17 @@ var translations = map[Language]Strings{
 		RepeatAll: "Repeat All",
 		NoRepeat:  "No Repeat",
 
-		NoTrackPlaying:         "No track playing",
-		Playing:                "Playing",
-		PlayingWithoutPlaylist: "Playing: %s (no playlist)",
-		Paused:                 "Paused",
-		Stopped:                "Stopped",
-		PlaylistFinished:       "Playlist finished",
-		PlaybackFinished:       "Playback finished",
-		SkippingTo:             "Skipping to: %d/%d - %s",
-		EnteringPlaylist:       "Entering playlist...",
-		AlreadyLastSong:        "Already at last song",
-		AlreadyFirstSong:       "Already at first song",
-		NothingPlaying:         "Nothing playing. Start playlist first.",
+		NoTrackPlaying:           "No track playing",
+		Playing:                  "Playing",
+		PlayingWithoutPlaylist:   "Playing: %s (no playlist)",
+		Paused:                   "Paused",
+		Stopped:                  "Stopped",
+		PlaylistFinished:         "Playlist finished",
+		PlaylistChangedElsewhere: "Playlist changed by another client, edit discarded",
+		PlaybackFinished:         "Playback finished",
+		AlreadyLastSong:          "Already at last song",
+		AlreadyFirstSong:         "Already at first song",
+		NothingPlaying:           "Nothing playing. Start playlist first.",
 
 		AddedToPlaylist:     "Added: %s",
 		RemovedFromPlaylist: "Removed from playlist",
@@ -378,17 +599,17 @@ var translations = map[Language]Strings{
 		Page:             "Page",
 
 		CmdSearchBar:   "Type to search (or paste URL) | [#89b4fa]Enter[-] Search | [#89b4fa]Tab[-] Next | [#f38ba8]Ctrl+Q[-] Quit | [#cba6f7]Ctrl+C[-] Config",
-		CmdResultsBar:  "[#89b4fa]j/k[-] Nav | [#89b4fa]Enter[-] Play | [#a6e3a1]a[-] Add | [#a6e3a1]A[-] Add all | [#94e2d5]y[-] Copy URL | [#cba6f7][ ][-] Page | [#89b4fa]/[-] Search | [#f38ba8]Ctrl+Q[-] Quit",
-		CmdPlaylistBar: "[#89b4fa]j/k[-] Nav | [#89b4fa]Enter[-] Play | [#f38ba8]d[-] Del | [#cba6f7]J/K[-] Move | [#94e2d5]y[-] Copy URL | [#fab387]r[-] Repeat | [#94e2d5]h[-] Shuffle | [#f38ba8]Ctrl+Q[-] Quit",
+		CmdResultsBar:  "[#89b4fa]j/k[-] Nav | [#cba6f7]v/x[-] Sel | [#89b4fa]Enter[-] Play | [#a6e3a1]a[-] Add | [#a6e3a1]A[-] Add all | [#94e2d5]y[-] Copy URL | [#cba6f7][ ][-] Page | [#89b4fa]/[-] Filter | [#89b4fa]i[-] Search | [#f38ba8]Ctrl+Q[-] Quit",
+		CmdPlaylistBar: "[#89b4fa]j/k[-] Nav | [#cba6f7]v/x[-] Sel | [#89b4fa]Enter[-] Play | [#f38ba8]d[-] Del | [#cba6f7]J/K/M[-] Move | [#94e2d5]y[-] Copy URL | [#f9e2af]o[-] Actions | [#fab387]r[-] Repeat | [#94e2d5]h[-] Shuffle | [#f38ba8]Ctrl+Q[-] Quit",
 		CmdPlayerBar:   "[#a6e3a1]Space[-] Pause | [#89dceb]n/p[-] Next/Prev | [#fab387]h/l[-] ±5s | [#fab387]H/L[-] ±30s | [#f38ba8]s[-] Stop | [#94e2d5]y[-] Copy URL | [#cba6f7]m[-] Mode | [#f38ba8]Ctrl+Q[-] Quit",
 		CmdDefaultBar:  "[#89b4fa]Tab[-] Navigate panels | [#94e2d5]y[-] Copy URL | [#f38ba8]Ctrl+Q[-] Quit | [#cba6f7]Ctrl+C[-] Config",
 
-		HelpNavigationText: "  Tab         Switch panels (Search → Results → Playlist → Player)\n  /           Focus search\n  ↑/↓  j/k    Navigate lists\n  g / G       Go to top / end of list\n  ?           Show this help",
+		HelpNavigationText: "  Tab         Switch panels (Search → Results → Playlist → Player)\n  /           Filter focused list (or focus search)\n  i           Focus search\n  n / N       Next / previous filtered item\n  Esc         Clear filter\n  ↑/↓  j/k    Navigate lists\n  g / G       Go to top / end of list\n  ?           Show this help",
 		HelpSearchText:     "  Type      Text to search or paste a YouTube URL\n  Enter     Search / play URL / import playlist",
-		HelpResultsText:    "  Enter     Play track directly (no playlist)\n  a         Add to playlist\n  A         Add all to playlist\n  y         Copy track URL\n  [ ]       Navigate pages (previous/next)",
-		HelpPlaylistText:   "  Enter     Play track from playlist\n  Space     Play playlist from start\n  d         Remove item\n  J         Move item down\n  K         Move item up\n  r         Cycle repeat (󰑗 → 󰑘 → 󰑖 → 󰑗)\n  h         Toggle shuffle ()",
+		HelpResultsText:    "  Enter     Play track directly (no playlist)\n  a         Add to playlist (or selection)\n  A         Add all to playlist\n  y         Copy track URL (or selection)\n  [ ]       Navigate pages (previous/next)\n  v / V     Visual mode (select range)\n  x         Mark/unmark item\n  e         Export selection (or list) to M3U\n  D         Download item/selection (audio or video)\n  f         Toggle favorite (or selection)\n  1-5 / 0   Rate with stars / clear rating\n  F         Show favorites\n  Esc       Clear selection",
+		HelpPlaylistText:   "  Enter     Play track from playlist\n  Space     Play playlist from start\n  d         Remove item (or selection)\n  J         Move item/selection down\n  K         Move item/selection up\n  T / B     Move to top / bottom\n  M         Move to position N\n  X         Cut item/selection\n  p / P     Paste after / before cursor\n  o         Actions: sort, reverse, remove duplicates, shuffle, offline\n  r         Cycle repeat (󰑗 → 󰑘 → 󰑖 → 󰑗)\n  h         Toggle shuffle ()\n  u         Show/hide shuffle order\n  v / V     Visual mode (select range)\n  x         Mark/unmark item\n  y         Copy URL (or selection)\n  e         Export selection (or playlist) to M3U\n  D         Download item/selection (audio or video)\n  f         Toggle favorite (or selection)\n  1-5 / 0   Rate with stars / clear rating\n  Esc       Clear selection",
 		HelpPlayerText:     "  Space     Pause/Play\n  s         Stop\n  n         Next song\n  p         Previous song\n  h / l     Seek -5s / +5s\n  H / L     Seek -30s / +30s",
-		HelpGlobalText:     "  m         Toggle audio/video\n  y         Copy URL (playing or selected track)\n  Ctrl+Q    Quit application\n  Ctrl+C    Settings\n  ?         This shortcuts window\n  Esc       Close window/modal",
+		HelpGlobalText:     "  m         Toggle audio/video\n  y         Copy URL (playing or selected track)\n  Ctrl+Q    Quit application\n  Ctrl+C    Settings\n  Ctrl+D    Downloads (p pause, c cancel, r retry)\n  Ctrl+L    Local library (downloads and configured folders)\n  S         Smart playlists (rules in smart_playlists.toml)\n  Ctrl+R    History (/ search, a add, I incognito, C C clear)\n  Ctrl+T    Statistics (w/m/y/a period, c CSV, J JSON)\n  ?         This shortcuts window\n  Esc       Close window/modal",
 		HelpIconsText:      "  󰑗 No Repeat  󰑘 Repeat One  󰑖 Repeat All   Shuffle",
 
 		ConfigText: "⚙️  SETTINGS\n\nChoose an option below to configure YouTui.\nUse ←/→ arrows to navigate and Enter to select.\n\nPress Esc to close.",
@@ -401,6 +622,123 @@ var translations = map[Language]Strings{
 		LoadingPlaylist:  "Importing playlist...",
 		PlaylistImported: "Added %d tracks to playlist",
 
+		RemovedManyFromPlaylist: "Removed %d items from playlist",
+		URLsCopied:              "%d URLs copied",
+		Exported:                "Exported %d tracks to %s",
+		ExportError:             "Export error: %v",
+		VisualMode:              "-- VISUAL --",
+		MarkedCount:             "%d marked",
+		MoveToPrompt:            "Move to position (1-%d):",
+		InvalidPosition:         "Invalid position: %s",
+		MovedTo:                 "Moved to position %d",
+		ItemsCut:                "%d items cut (p/P to paste)",
+		NothingToPaste:          "Nothing to paste",
+
+		PlaylistActions:          "Playlist actions",
+		SortByTitle:              "Sort by title",
+		SortByAuthor:             "Sort by author",
+		SortByDuration:           "Sort by duration",
+		SortByAdded:              "Sort by date added",
+		ReverseOrder:             "Reverse order",
+		RemoveDuplicates:         "Remove duplicates",
+		ShuffleOrder:             "Shuffle order (permanent)",
+		PlaylistSorted:           "Playlist: %s",
+		PlaylistReversed:         "Playlist order reversed",
+		PlaylistShuffled:         "Playlist order shuffled",
+		DuplicatesRemoved:        "Removed %d duplicates",
+		ShuffleOrderShown:        "Shuffle order shown",
+		ShuffleOrderHidden:       "Shuffle order hidden",
+		ShuffleOrderNeedsShuffle: "Enable shuffle (h) to see the order",
+		FilterPrompt:             "Filter:",
+		FilterActive:             "filter \"%s\" %d/%d",
+		FilterNoMatches:          "No items match \"%s\"",
+		FilterCleared:            "Filter cleared",
+		Downloads:                "Downloads",
+		DownloadAs:               "Download as",
+		DownloadDefaultAudio:     "Default audio",
+		DownloadVideo:            "Video",
+		AudioBitrate:             "Audio bitrate",
+		BitrateBest:              "Best (VBR)",
+		DownloadsQueued:          "%d downloads queued",
+		DownloadAlreadyQueued:    "Already in the download queue",
+		DownloadFinished:         "Downloaded: %s",
+		DownloadFailed:           "Download failed: %s",
+		DownloadProcessing:       "processing...",
+		DownloadsEmpty:           "No downloads. Press D on an item to download it.",
+		DownloadsHint:            "[#89b4fa]j/k[-] Nav | [#f9e2af]p[-] Pause/Resume | [#f38ba8]c[-] Cancel | [#a6e3a1]r[-] Retry | [#f38ba8]x[-] Remove | [#cba6f7]C[-] Clear finished | [#89b4fa]Esc[-] Close",
+		DownloadsCleared:         "Removed %d downloads from the list",
+		LibraryScanning:          "Scanning local library...",
+		LibraryScanError:         "Library scan failed: %v",
+		LibraryEmpty:             "No media files found in %s",
+		LibraryLoaded:            "%d local tracks",
+		PlayingOffline:           "Playing (offline)",
+		MakeOffline:              "Make playlist available offline",
+		PlaylistAlreadyOffline:   "Every playlist track is already available offline",
+		History:                  "History",
+		HistoryToday:             "Today",
+		HistoryYesterday:         "Yesterday",
+		HistorySearch:            "Search history...",
+		HistoryEmpty:             "  No playback recorded yet",
+		HistoryHint:              "Enter play • a add to playlist • / search • I incognito • C C clear • Esc close",
+		HistoryClearConfirm:      "Press C again to erase the whole history",
+		HistoryCleared:           "History cleared",
+		HistoryError:             "History error: %v",
+		Incognito:                "incognito",
+		IncognitoOn:              "Incognito: playbacks won't be recorded",
+		IncognitoOff:             "Incognito disabled",
+		Stats:                    "Statistics",
+		StatsWeek:                "7 days",
+		StatsMonth:               "Month",
+		StatsYear:                "Year",
+		StatsAll:                 "All time",
+		StatsTotal:               "Listening time",
+		StatsPlays:               "Plays",
+		StatsCompleted:           "completed",
+		StatsActiveDays:          "Active days",
+		StatsStreak:              "Current streak: %d days • longest: %d days",
+		StatsTopTracks:           "Top tracks",
+		StatsTopChannels:         "Top channels",
+		StatsByHour:              "By hour of day",
+		StatsByWeekday:           "By weekday",
+		StatsWeekdays:            "Sun,Mon,Tue,Wed,Thu,Fri,Sat",
+		StatsEmpty:               "No playback in this period",
+		StatsHint:                "w/m/y/a period • c export CSV • J export JSON • ↑↓ scroll • Esc close",
+		StatsExported:            "Statistics exported to %s",
+		SortByRating:             "Sort by rating",
+		FavoriteAdded:            "Favorited: %s",
+		FavoriteRemoved:          "Removed from favorites: %s",
+		FavoritesAdded:           "%d tracks favorited",
+		FavoritesRemoved:         "%d tracks removed from favorites",
+		Rated:                    "Rated %d track(s) %s",
+		RatingCleared:            "Rating cleared on %d track(s)",
+		FavoritesEmpty:           "No favorite tracks yet (press f to add one)",
+		FavoritesLoaded:          "Favorites: %d tracks",
+		SmartPlaylists:           "Smart playlists",
+		SmartPlaylistsEmpty:      "No smart playlists defined in %s",
+		SmartPlaylistError:       "Smart playlist error: %v",
+		SmartPlaylistLoaded:      "%s: %d tracks",
+		SmartPlaylistNoMatches:   "No tracks match \"%s\"",
+		StoreError:               "Could not open the local database: %v",
+		StateSaveError:           "Could not save state: %v",
+		StateRestoreError:        "Could not restore state: %v",
+		RecoveryText:             "The saved state could not be read:\n%v\n\n%s\n\nRestore a backup or start fresh? The damaged file is kept with a .corrupt suffix.",
+		RecoveryRestore:          "Restore backup from %s",
+		RecoveryStartFresh:       "Start fresh",
+		RecoveryContinue:         "Continue without saving",
+		RecoveryRestored:         "Backup restored; damaged file moved to %s",
+		RecoveryFresh:            "State reset; damaged file moved to %s",
+		VolumeSet:                "Volume: %d%%",
+		PlayerDisconnected:       "Lost connection to the player: %v",
+		NowPlayingError:          "Now-playing output disabled: %v",
+		HookFailed:               "Hook %s failed: %v",
+		HooksDisabled:            "Hooks disabled: %v",
+		ScrobbleDisabled:         "Scrobbling disabled: %v",
+		ScrobbleFailed:           "Scrobble failed: %v",
+		ScrobbleAuthFailed:       "Scrobbling paused, check your credentials: %v",
+		DiscordButton:            "Listen on YouTube",
+		DiscordByAuthor:          "by %s",
+		DiscordError:             "Discord Rich Presence: %v",
+
 		EmptyQuery:       "Empty query",
 		NoResultsFor:     "No results for: %q",
 		YtDlpNotFound:    "yt-dlp not found in PATH. Install via 'pipx install yt-dlp' or 'pip install --user yt-dlp'",
@@ -410,19 +748,14 @@ var translations = map[Language]Strings{
 		TerminalVideo:         "Terminal (video in terminal)",
 		TerminalVideoStarting: "Starting terminal video... (q to return)",
 
-		warnFailedKillMpv:     "failed to kill mpv process: ",
-		errorPause:            "Error pausing: ",
-		socatNotInstalled:     "socat not installed. Install",
-		socatCmdNotFound:      "socat: command not found",
-		nothingPlaying:        "Nothing playing at the moment",
-		youtubeBlocked:        "YouTube blocked (403). Update yt-dlp: sudo yt-dlp -U",
-		errorStartMpv:         "Error starting mpv: ",
-		warnFailedKillPrevMpv: "failed to kill previous mpv process: ",
-		stateRestored:         "State restored successfully!",
-		ytDlpOutdated:         "yt-dlp outdated",
-		shuffle:               "Shuffle",
-		repeatOne:             "Repeat 1",
-		repeatAll:             "Repeat All",
+		socatNotInstalled: "socat not installed. Install",
+		youtubeBlocked:    "YouTube blocked (403). Update yt-dlp: sudo yt-dlp -U",
+		errorStartMpv:     "Error starting mpv: ",
+		stateRestored:     "State restored successfully!",
+		ytDlpOutdated:     "yt-dlp outdated",
+		shuffle:           "Shuffle",
+		repeatOne:         "Repeat 1",
+		repeatAll:         "Repeat All",
 	},
 }
 
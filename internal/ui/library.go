@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"math"
+	"strings"
+
+	"github.com/IvelOt/youtui-player/internal/library"
+	"github.com/IvelOt/youtui-player/internal/search"
+)
+
+func libraryTrack(e library.Entry) Track {
+	t := Track{
+		Title:    e.Title,
+		Author:   e.Artist,
+		URL:      library.FileURL(e.Path),
+		Duration: search.HumanDuration(int(math.Round(e.Duration))),
+	}
+	if e.Cover {
+		t.Thumbnail = t.URL
+	}
+	var desc []string
+	if e.Album != "" {
+		desc = append(desc, e.Album)
+	}
+	t.Description = strings.Join(append(desc, e.Path), "\n")
+	return t
+}
+
+func (a *SimpleApp) showLibrary() {
+	a.app.QueueUpdateDraw(func() {
+		a.setStatus(a.theme.Sapphire, "⟳ "+a.strings.LibraryScanning)
+	})
+
+	entries, err := a.lib.Scan()
+	if err != nil {
+		a.app.QueueUpdateDraw(func() {
+			a.setStatusf(a.theme.Red, "❌ "+a.strings.LibraryScanError, err)
+		})
+		return
+	}
+	if len(entries) == 0 {
+		a.app.QueueUpdateDraw(func() {
+			a.setStatusf(a.theme.Yellow, "⚠ "+a.strings.LibraryEmpty, strings.Join(a.lib.Dirs(), ", "))
+		})
+		return
+	}
+
+	a.offline.AddEntries(entries)
+
+	tracks := make([]Track, len(entries))
+	for i, e := range entries {
+		tracks[i] = libraryTrack(e)
+	}
+
+	a.loadResultTracks(tracks, "")
+
+	a.app.QueueUpdateDraw(func() {
+		a.setStatusf(a.theme.Green, "✓ "+a.strings.LibraryLoaded, len(tracks))
+		a.app.SetFocus(a.searchResults.Flex)
+		a.updateCommandBar()
+	})
+
+	a.AutoSaveState()
+}
+
+func (a *SimpleApp) loadResultTracks(tracks []Track, smartName string) {
+	a.mu.Lock()
+	a.tracks = tracks
+	a.activeSmart = smartName
+	a.mu.Unlock()
+
+	a.app.QueueUpdateDraw(func() {
+		a.searchResults.SetFilter("")
+	})
+
+	a.pagination.SetTotalItems(len(tracks))
+	a.pagination.Reset()
+
+	a.displayCurrentPage()
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"github.com/rivo/tview"
+)
+
+type menuItem struct {
+	label  string
+	key    rune
+	action func()
+}
+
+func (a *SimpleApp) showMenu(title string, items []menuItem) {
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetMainTextColor(a.theme.Text).
+		SetShortcutColor(a.theme.Peach).
+		SetSelectedTextColor(a.theme.Crust).
+		SetSelectedBackgroundColor(a.theme.Blue).
+		SetHighlightFullLine(true)
+	list.SetBackgroundColor(a.theme.Base)
+	list.SetBorder(true).
+		SetTitle(" " + title + " ").
+		SetTitleColor(a.theme.Text).
+		SetBorderColor(a.theme.Blue)
+
+	for _, item := range items {
+		action := item.action
+		list.AddItem(item.label, "", item.key, func() {
+			a.closeMenu()
+			if action != nil {
+				action()
+			}
+		})
+	}
+
+	width := len(title) + 8
+	for _, item := range items {
+		width = max(width, len([]rune(item.label))+10)
+	}
+
+	centered := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(list, width, 0, true).
+			AddItem(nil, 0, 1, false), len(items)+2, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	a.inModal = true
+	a.prevFocused = a.app.GetFocus()
+	a.app.SetRoot(centered, true)
+	a.app.SetFocus(list)
+}
+
+func (a *SimpleApp) closeMenu() {
+	a.inModal = false
+	a.app.SetRoot(a.getMainLayout(), true)
+	if a.prevFocused != nil {
+		a.app.SetFocus(a.prevFocused)
+	}
+	a.updateCommandBar()
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/IvelOt/youtui-player/internal/mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+func (a *SimpleApp) startMPRIS() {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return
+	}
+	bridge, err := mpris.Attach(conn, a.player, a.artURL)
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	a.mu.Lock()
+	if a.detached {
+		a.mu.Unlock()
+		_ = bridge.Close()
+		_ = conn.Close()
+		return
+	}
+	a.mpris, a.mprisConn = bridge, conn
+	a.mu.Unlock()
+}
+
+func (a *SimpleApp) stopMPRIS() {
+	a.mu.Lock()
+	bridge, conn := a.mpris, a.mprisConn
+	a.mpris, a.mprisConn = nil, nil
+	a.mu.Unlock()
+
+	if bridge != nil {
+		_ = bridge.Close()
+	}
+	if conn != nil {
+		_ = conn.Close()
+	}
+}
+
+func (a *SimpleApp) artURL(track Track) string {
+	return ArtURL(a.thumbCache, track)
+}
+
+func ArtURL(cache *ThumbnailCache, track Track) string {
+	if cache != nil {
+		if path := cache.CachedPath(track.Thumbnail); path != "" {
+			return "file://" + path
+		}
+	}
+	if strings.HasPrefix(track.Thumbnail, "http") {
+		return track.Thumbnail
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/notify"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	notifyTrackStart = "track_start"
+	notifyError      = "error"
+)
+
+type desktopNotifications struct {
+	conn    *dbus.Conn
+	watcher *notify.Watcher
+}
+
+func startNotifications(ctrl engine.Controller, texts func() Strings, cache *ThumbnailCache) (*desktopNotifications, error) {
+	cfg, _ := config.LoadConfig()
+	if !cfg.Notifications.Enabled {
+		return nil, nil
+	}
+	events := cfg.Notifications.Events
+	if len(events) == 0 {
+		events = []string{notifyTrackStart, notifyError}
+	}
+
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+	watcher, err := notify.Watch(notify.New(conn, "youtui-player"), ctrl, notify.Options{
+		TrackStart: slices.Contains(events, notifyTrackStart),
+		Errors:     slices.Contains(events, notifyError),
+		Icon:       func(track Track) string { return notificationIcon(cache, track) },
+		ErrorText: func(ev engine.Event) string {
+			str := texts()
+			if ev.Blocked {
+				return str.youtubeBlocked
+			}
+			return fmt.Sprintf(str.MpvError, ev.Error)
+		},
+	})
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return &desktopNotifications{conn: conn, watcher: watcher}, nil
+}
+
+func (d *desktopNotifications) Close() {
+	d.watcher.Close()
+	_ = d.conn.Close()
+}
+
+func notificationIcon(cache *ThumbnailCache, track Track) string {
+	if cache == nil || track.Thumbnail == "" {
+		return ""
+	}
+	if path := cache.CachedPath(track.Thumbnail); path != "" {
+		return path
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if _, err := cache.GetThumbnailImageWithContext(ctx, track.Thumbnail); err != nil {
+		return ""
+	}
+	return cache.CachedPath(track.Thumbnail)
+}
@@ -0,0 +1,20 @@
+package ui
+
+import (
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/nowplaying"
+)
+
+func startOverlay(ctrl engine.Controller) (*nowplaying.Overlay, error) {
+	cfg, _ := config.LoadConfig()
+	if !cfg.NowPlaying.Enabled {
+		return nil, nil
+	}
+	return nowplaying.Attach(ctrl, nowplaying.Options{
+		Dir:         config.GetNowPlayingDir(cfg.NowPlaying),
+		Files:       cfg.NowPlaying.Files,
+		Thumbnail:   cfg.NowPlaying.Thumbnail,
+		EverySecond: cfg.NowPlaying.EverySecond,
+	})
+}
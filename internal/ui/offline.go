@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/IvelOt/youtui-player/internal/download"
+	"github.com/IvelOt/youtui-player/internal/library"
+	"github.com/IvelOt/youtui-player/internal/search"
+)
+
+func (a *SimpleApp) setupOfflineIndex() {
+	a.offline.AddEntries(a.lib.Entries())
+	if paths, err := a.data.Offline(); err == nil {
+		for id, path := range paths {
+			a.offline.Add(id, path)
+		}
+		a.importDownloads(paths)
+	}
+
+	a.searchResults.SetOfflineFunc(a.isOffline)
+	a.playlist.SetOfflineFunc(a.isOffline)
+}
+
+func (a *SimpleApp) isOffline(track Track) bool {
+	id := search.VideoID(track.URL)
+	return id != "" && a.offline.Has(id)
+}
+
+func (a *SimpleApp) mpvTarget(track Track) (string, bool) {
+	return resolveTarget(a.offline, track)
+}
+
+func (a *SimpleApp) importDownloads(known map[string]string) {
+	for _, job := range a.downloads.Jobs() {
+		id := search.VideoID(job.URL)
+		if _, ok := known[id]; ok || id == "" || job.Status != download.StatusDone || job.Path == "" {
+			continue
+		}
+		if _, err := os.Stat(job.Path); err == nil && a.data.AddOffline(id, job.Path) == nil {
+			a.offline.Add(id, job.Path)
+		}
+	}
+}
+
+func resolveTarget(index *library.Index, track Track) (string, bool) {
+	if path, ok := library.PathFromURL(track.URL); ok {
+		return path, true
+	}
+	if id := search.VideoID(track.URL); id != "" {
+		if path, ok := index.Lookup(id); ok {
+			return path, true
+		}
+	}
+	return track.URL, false
+}
+
+func (a *SimpleApp) refreshOfflineBadges() {
+	a.searchResults.RefreshBadges()
+	a.playlist.RefreshBadges()
+}
+
+func (a *SimpleApp) makePlaylistOffline() {
+	a.mu.Lock()
+	var missing []Track
+	for _, t := range a.playlistTracks {
+		if library.IsLocal(t.URL) || a.isOffline(t) {
+			continue
+		}
+		missing = append(missing, t)
+	}
+	total := len(a.playlistTracks)
+	a.mu.Unlock()
+
+	if total == 0 {
+		a.setStatus(a.theme.Yellow, "⚠ "+a.strings.PlaylistEmpty)
+		return
+	}
+	if len(missing) == 0 {
+		a.setStatus(a.theme.Green, "✓ "+a.strings.PlaylistAlreadyOffline)
+		return
+	}
+	a.promptDownload(missing, true)
+}
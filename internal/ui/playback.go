@@ -0,0 +1,412 @@
+package ui
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/daemon"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/library"
+)
+
+const maxPlaylistRebase = 3
+
+var errPlaylistDiscarded = errors.New("playlist edit discarded")
+
+func (a *SimpleApp) setupPlayer(client *daemon.Client) error {
+	if client != nil {
+		a.client = client
+		a.player = client
+		a.data = client
+		return nil
+	}
+
+	a.engine = engine.New(engine.Options{
+		Resolve:  a.mpvTarget,
+		PlayMode: a.playMode,
+		Quality:  a.videoQuality,
+		Codec:    a.videoCodec,
+	})
+	a.player = a.engine
+	_ = a.owner.Watch(a.engine, a.onOwnerError)
+
+	if srv, err := daemon.Listen(config.GetSocketPath(), a.engine, a.owner); err == nil {
+		a.server = srv
+		go func() {
+			_ = srv.Serve()
+		}()
+	}
+	go a.startMPRIS()
+
+	runner, err := startHooks(a.engine, a.onHookError)
+	if err != nil {
+		a.onHooksDisabled(err)
+	}
+	a.hooks = runner
+	presence, err := startDiscord(a.engine, a.currentStrings, a.onDiscordError)
+	if err != nil {
+		a.onDiscordError(err)
+	}
+	a.discord = presence
+	go a.startNotifications()
+	go a.startScrobbling()
+
+	overlay, err := startOverlay(a.engine)
+	a.overlay = overlay
+	return err
+}
+
+func (a *SimpleApp) attached() bool {
+	return a.client != nil
+}
+
+func (a *SimpleApp) shutdownPlayer() {
+	a.mu.Lock()
+	a.detached = true
+	a.mu.Unlock()
+
+	a.stopMPRIS()
+	if a.overlay != nil {
+		a.overlay.Close()
+	}
+	if a.hooks != nil {
+		a.hooks.Close()
+	}
+	if a.discord != nil {
+		a.discord.Close()
+	}
+	a.mu.Lock()
+	notifications := a.notifications
+	a.notifications = nil
+	scrobbling := a.scrobbling
+	a.scrobbling = nil
+	a.mu.Unlock()
+	if notifications != nil {
+		notifications.Close()
+	}
+	if scrobbling != nil {
+		scrobbling.Close()
+	}
+	if a.server != nil {
+		_ = a.server.Close()
+	}
+	if a.owner != nil {
+		a.owner.Close()
+	}
+	if a.engine != nil {
+		a.engine.Close()
+	}
+	if a.client != nil {
+		_ = a.client.Close()
+	}
+}
+
+func (a *SimpleApp) watchPlayer() {
+	events, cancel, err := a.player.Subscribe()
+	if err != nil {
+		a.app.QueueUpdateDraw(func() {
+			a.setStatusf(a.theme.Red, "❌ "+a.strings.PlayerDisconnected, err)
+		})
+		return
+	}
+	defer cancel()
+
+	a.onHistoryChanged()
+	if st, err := a.player.State(); err == nil {
+		a.applyPlayerState(st)
+		if tracks, err := a.player.Playlist(); err == nil {
+			a.applyPlaylist(tracks, st.Index, st.Version)
+		}
+		a.app.QueueUpdateDraw(func() {
+			a.updatePlayerInfo()
+			a.updateModeBadge()
+			a.updatePlaylistFooter()
+			a.playlist.SetPlayingIndex(st.Index)
+			if st.Playing {
+				a.updateThumbnail(st.Track.Thumbnail)
+			}
+		})
+	}
+
+	for ev := range events {
+		a.handlePlayerEvent(ev)
+	}
+
+	a.mu.Lock()
+	detached := a.detached
+	a.mu.Unlock()
+	if !detached {
+		a.app.QueueUpdateDraw(func() {
+			a.setStatusf(a.theme.Red, "❌ "+a.strings.PlayerDisconnected, config.GetSocketPath())
+		})
+	}
+}
+
+func (a *SimpleApp) applyPlayerState(st engine.State) (pauseChanged, modeChanged, stopped bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pauseChanged = a.isPlaying && st.Playing && a.session == st.Session && a.isPaused != st.Paused
+	modeChanged = a.playlistMode != st.Mode || a.playMode != st.PlayMode
+	stopped = a.isPlaying && !st.Playing && st.Index < 0
+
+	a.isPlaying = st.Playing
+	a.isPaused = st.Paused
+	a.nowPlaying = st.Track.Title
+	a.currentThumb = st.Track.Thumbnail
+	a.playingTrack = st.Track
+	a.position = st.Position
+	a.duration = st.Duration
+	a.volume = st.Volume
+	a.playlistMode = st.Mode
+	a.playMode = st.PlayMode
+	a.videoQuality = st.Quality
+	a.videoCodec = st.Codec
+	if a.playlistVersion == a.playlistSynced {
+		a.currentTrack = st.Index
+	}
+	return pauseChanged, modeChanged, stopped
+}
+
+func (a *SimpleApp) handlePlayerEvent(ev engine.Event) {
+	switch ev.Type {
+	case engine.EventStarted:
+		a.applyPlayerState(ev.State)
+		a.mu.Lock()
+		a.session = ev.Session
+		a.mu.Unlock()
+
+		track := *ev.Track
+		a.app.QueueUpdateDraw(func() {
+			a.updatePlayerInfo()
+			a.updateThumbnail(track.Thumbnail)
+			a.playlist.SetPlayingIndex(ev.Index)
+			a.updateShuffleOrderView()
+			switch {
+			case ev.Index < 0:
+				a.setStatusf(a.theme.Green, "▶ "+a.strings.PlayingWithoutPlaylist, track.Title)
+			case ev.State.Local && !library.IsLocal(track.URL):
+				a.setStatusf(a.theme.Green, "▶ %s: %s", a.strings.PlayingOffline, track.Title)
+			default:
+				a.setStatusf(a.theme.Green, "▶ %s: %s", a.strings.Playing, track.Title)
+			}
+		})
+
+	case engine.EventState:
+		pauseChanged, modeChanged, stopped := a.applyPlayerState(ev.State)
+		a.app.QueueUpdateDraw(func() {
+			a.updatePlayerInfo()
+			a.playlist.SetPlayingIndex(ev.State.Index)
+			if modeChanged {
+				a.updateModeBadge()
+				a.updatePlaylistFooter()
+				a.updateShuffleOrderView()
+			}
+			if stopped {
+				a.updateThumbnail("")
+			}
+			switch {
+			case pauseChanged && ev.State.Paused:
+				a.setStatus(a.theme.Yellow, "⏸ "+a.strings.Paused)
+			case pauseChanged:
+				a.setStatus(a.theme.Green, "▶ "+a.strings.Playing)
+			}
+		})
+
+	case engine.EventEnded:
+		a.applyPlayerState(ev.State)
+
+		a.mu.Lock()
+		current := ev.Session == a.session
+		a.mu.Unlock()
+		if !current || ev.State.Playing {
+			return
+		}
+
+		a.app.QueueUpdateDraw(func() {
+			a.updatePlayerInfo()
+			switch {
+			case ev.Blocked:
+				a.setStatus(a.theme.Red, "❌"+a.strings.youtubeBlocked)
+			case ev.Error != "":
+				a.setStatusf(a.theme.Red, "❌ "+a.strings.MpvError, ev.Error)
+			case ev.Finished && ev.Index >= 0:
+				a.setStatus(a.theme.Yellow, a.strings.PlaylistFinished)
+			case ev.Finished:
+				a.setStatus(a.theme.Yellow, a.strings.PlaybackFinished)
+			}
+		})
+
+	case engine.EventPlaylist:
+		a.applyPlaylist(ev.Playlist, ev.Index, ev.State.Version)
+
+	case engine.EventHistory:
+		a.onHistoryChanged()
+	}
+}
+
+func (a *SimpleApp) applyPlaylist(tracks []Track, current int, version uint64) {
+	a.mu.Lock()
+	a.playlistSeen = max(a.playlistSeen, version)
+	if a.playlistVersion != a.playlistSynced || (a.playlistKnown && version < a.playlistBase) {
+		a.mu.Unlock()
+		return
+	}
+	a.playlistBase, a.playlistBaseTracks, a.playlistKnown = version, tracks, true
+	if slices.EqualFunc(a.playlistTracks, tracks, sameURL) {
+		a.mu.Unlock()
+		return
+	}
+	a.playlistTracks = tracks
+	a.currentTrack = current
+	a.cutIndices = nil
+	a.mu.Unlock()
+
+	a.app.QueueUpdateDraw(func() {
+		a.refreshPlaylistView(a.playlist.GetCurrentItem(), nil)
+	})
+}
+
+func (a *SimpleApp) syncPlaylistLocked() {
+	a.playlistVersion++
+	version := a.playlistVersion
+
+	go func() {
+		a.syncMu.Lock()
+		defer a.syncMu.Unlock()
+
+		a.mu.Lock()
+		stale := version != a.playlistVersion
+		a.mu.Unlock()
+		if stale {
+			return
+		}
+
+		err := a.pushPlaylist()
+
+		a.mu.Lock()
+		if a.playlistSynced < version {
+			a.playlistSynced = version
+		}
+		behind := a.playlistSynced == a.playlistVersion && a.playlistSeen > a.playlistBase
+		a.mu.Unlock()
+		if behind {
+			a.reloadPlaylist()
+		}
+		switch {
+		case errors.Is(err, errPlaylistDiscarded):
+			a.app.QueueUpdateDraw(func() {
+				a.setStatus(a.theme.Yellow, "⚠ "+a.strings.PlaylistChangedElsewhere)
+			})
+		case err != nil:
+			a.showPlayerError(err)
+		}
+	}()
+}
+
+func (a *SimpleApp) pushPlaylist() error {
+	for attempt := 0; ; attempt++ {
+		a.mu.Lock()
+		base, tracks := a.playlistBase, slices.Clone(a.playlistTracks)
+		a.mu.Unlock()
+
+		version, err := a.player.EditPlaylist(base, tracks)
+		if err == nil {
+			a.mu.Lock()
+			a.playlistBase, a.playlistBaseTracks, a.playlistKnown = version, tracks, true
+			a.mu.Unlock()
+			return nil
+		}
+		if !errors.Is(err, engine.ErrPlaylistChanged) || attempt == maxPlaylistRebase {
+			return err
+		}
+		if err := a.rebasePlaylist(); err != nil {
+			return err
+		}
+	}
+}
+
+func (a *SimpleApp) rebasePlaylist() error {
+	st, err := a.player.State()
+	if err != nil {
+		return err
+	}
+	tracks, err := a.player.Playlist()
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	base := a.playlistBaseTracks
+	appended := a.playlistKnown && len(tracks) >= len(base) &&
+		slices.EqualFunc(tracks[:len(base)], base, sameURL)
+	if appended {
+		a.playlistTracks = append(a.playlistTracks, tracks[len(base):]...)
+	} else {
+		a.playlistTracks = tracks
+		a.currentTrack = st.Index
+		a.cutIndices = nil
+	}
+	a.playlistBase, a.playlistBaseTracks, a.playlistKnown = st.Version, tracks, true
+	a.mu.Unlock()
+
+	a.app.QueueUpdateDraw(func() {
+		a.refreshPlaylistView(a.playlist.GetCurrentItem(), nil)
+	})
+	if !appended {
+		return errPlaylistDiscarded
+	}
+	return nil
+}
+
+func (a *SimpleApp) reloadPlaylist() {
+	st, err := a.player.State()
+	if err != nil {
+		return
+	}
+	if tracks, err := a.player.Playlist(); err == nil {
+		a.applyPlaylist(tracks, st.Index, st.Version)
+	}
+}
+
+func sameURL(x, y Track) bool {
+	return x.URL == y.URL
+}
+
+func (a *SimpleApp) startNotifications() {
+	n, err := startNotifications(a.engine, a.currentStrings, a.thumbCache)
+	if err != nil || n == nil {
+		return
+	}
+	a.mu.Lock()
+	if a.detached {
+		a.mu.Unlock()
+		n.Close()
+		return
+	}
+	a.notifications = n
+	a.mu.Unlock()
+}
+
+func (a *SimpleApp) onOwnerError(err error) {
+	a.app.QueueUpdateDraw(func() {
+		a.setStatusf(a.theme.Red, "❌ "+a.strings.HistoryError, err)
+	})
+}
+
+func (a *SimpleApp) currentStrings() Strings {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.strings
+}
+
+func (a *SimpleApp) syncVideoSettings() {
+	a.mu.Lock()
+	quality, codec := a.videoQuality, a.videoCodec
+	a.mu.Unlock()
+
+	go func() {
+		_ = a.player.SetVideo(quality, codec)
+	}()
+}
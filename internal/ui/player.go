@@ -1,50 +1,16 @@
 package ui
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
-	"math/rand/v2"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"strings"
-	"syscall"
-	"time"
 
 	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/engine"
 	"github.com/gdamore/tcell/v2"
 )
 
-func buildYtdlFormat(quality, codec string) string {
-	var codecFilter string
-	switch codec {
-	case "vp9":
-		codecFilter = "[vcodec^=vp9]"
-	case "av1":
-		codecFilter = "[vcodec^=av01]"
-	}
-
-	if quality == "tct" {
-		return "bestvideo[height<=360]+bestaudio/best[height<=360]"
-	}
-
-	if quality == "best" || quality == "" {
-		if codecFilter == "" {
-			return "bestvideo+bestaudio/best"
-		}
-		return fmt.Sprintf("bestvideo%s+bestaudio/bestvideo+bestaudio/best", codecFilter)
-	}
-
-	if codecFilter == "" {
-		return fmt.Sprintf("bestvideo[height<=%s]+bestaudio/best[height<=%s]", quality, quality)
-	}
-
-	return fmt.Sprintf(
-		"bestvideo%s[height<=%s]+bestaudio/bestvideo[height<=%s]+bestaudio/best[height<=%s]",
-		codecFilter, quality, quality, quality,
-	)
-}
-
 func (a *SimpleApp) setStatus(color tcell.Color, msg string) {
 	a.statusBar.SetText("[" + colorTag(color) + "]" + msg)
 }
@@ -54,531 +20,113 @@ func (a *SimpleApp) setStatusf(color tcell.Color, format string, args ...any) {
 }
 
 func (a *SimpleApp) playTrackSimple(track Track, idx int) {
-	a.mu.Lock()
-	if a.stopProgress != nil {
-		select {
-		case <-a.stopProgress:
-		default:
-			close(a.stopProgress)
-		}
-		a.stopProgress = nil
-	}
-	if a.mpvProcess != nil && a.mpvProcess.Process != nil {
-		if err := a.mpvProcess.Process.Signal(os.Signal(syscall.Signal(0))); err == nil {
-			if err := a.mpvProcess.Process.Kill(); err != nil {
-				fmt.Printf("Warning %s %v", a.strings.warnFailedKillPrevMpv, err)
-			}
-		}
-		a.mpvProcess = nil
-	}
-	a.mu.Unlock()
-
-	socketPath := filepath.Join(os.TempDir(), fmt.Sprintf("mpv-socket-%d", time.Now().UnixNano()))
-
-	args := []string{
-		"--no-terminal",
-		"--script-opts=ytdl_hook-ytdl_path=yt-dlp",
-		fmt.Sprintf("--title=%s", track.Title),
-		fmt.Sprintf("--input-ipc-server=%s", socketPath),
-	}
-
-	a.mu.Lock()
-	playMode := a.playMode
-	quality := a.videoQuality
-	codec := a.videoCodec
-	a.mu.Unlock()
-
-	if quality == "tct" {
-		a.app.QueueUpdateDraw(func() {
-			a.setStatus(a.theme.Sapphire, a.strings.TerminalVideoStarting)
-		})
-		tctArgs := []string{
-			"--vo=tct",
-			"--vo-tct-algo=half-blocks",
-			"--vo-tct-256=yes",
-			"--really-quiet",
-			"--script-opts=ytdl_hook-ytdl_path=yt-dlp",
-			"--ytdl-format=" + buildYtdlFormat("tct", ""),
-			track.URL,
-		}
-		a.app.Suspend(func() {
-			tctCmd := exec.Command("mpv", tctArgs...)
-			tctCmd.Stdin = os.Stdin
-			tctCmd.Stdout = os.Stdout
-			tctCmd.Stderr = os.Stderr
-			_ = tctCmd.Run()
-		})
-		a.app.QueueUpdateDraw(func() {
-			a.setStatus(a.theme.Green, "▶ "+a.strings.PlaybackFinished)
-		})
+	if a.playTerminalVideo(track) {
 		return
 	}
-
-	if playMode == ModeAudio {
-		args = append(args, "--no-video", "--ytdl-format=bestaudio")
-	} else {
-		args = append(args, "--ytdl-format="+buildYtdlFormat(quality, codec))
-	}
-
-	args = append(args, track.URL)
-	cmd := exec.Command("mpv", args...)
-
-	var stderrBuf bytes.Buffer
-	cmd.Stderr = &stderrBuf
-
-	if err := cmd.Start(); err != nil {
-		a.app.QueueUpdateDraw(func() {
-			a.setStatusf(a.theme.Red, "❌%s %v", a.strings.errorStartMpv, err)
-		})
-		return
+	if err := a.player.Play(idx); err != nil {
+		a.showPlayerError(err)
 	}
-
-	a.mu.Lock()
-	a.mpvProcess = cmd
-	a.mpvSocket = socketPath
-	a.isPlaying = true
-	a.isPaused = false
-	a.nowPlaying = track.Title
-	a.currentThumb = track.Thumbnail
-	a.currentTrack = idx
-	a.position = 0
-	a.duration = 0
-	a.mu.Unlock()
-
-	a.app.QueueUpdateDraw(func() {
-		a.updatePlayerInfo()
-		a.updateThumbnail(track.Thumbnail)
-		a.playlist.SetPlayingIndex(idx)
-		a.setStatusf(a.theme.Green, "▶ %s: %s", a.strings.Playing, track.Title)
-	})
-
-	a.startProgressUpdater()
-
-	go func(expectedCmd *exec.Cmd, trackCopy Track, idxCopy int) {
-		err := expectedCmd.Wait()
-		time.Sleep(500 * time.Millisecond)
-
-		a.mu.Lock()
-		if a.mpvProcess != expectedCmd {
-			a.mu.Unlock()
-			return
-		}
-
-		if err != nil {
-			a.isPlaying = false
-			a.mu.Unlock()
-
-			stderrOutput := stderrBuf.String()
-			if strings.Contains(stderrOutput, "403") || strings.Contains(stderrOutput, "HTTP error 403") {
-				a.app.QueueUpdateDraw(func() {
-					a.updatePlayerInfo()
-					a.setStatus(a.theme.Red, "❌"+a.strings.youtubeBlocked)
-				})
-			} else {
-				a.app.QueueUpdateDraw(func() {
-					a.updatePlayerInfo()
-					a.setStatusf(a.theme.Red, "❌"+a.strings.MpvError, err)
-				})
-			}
-			return
-		}
-
-		if a.skipAutoPlay {
-			a.skipAutoPlay = false
-			a.mu.Unlock()
-			return
-		}
-
-		mode := a.playlistMode
-		var shouldPlayNext bool
-		var nextTrack Track
-		var nextIdx int
-
-		switch mode {
-		case ModeRepeatOne:
-			shouldPlayNext = true
-			nextTrack = trackCopy
-			nextIdx = idxCopy
-
-		case ModeShuffle:
-			if len(a.playlistTracks) > 0 {
-				if len(a.playlistTracks) == 1 {
-					nextIdx = 0
-				} else {
-					for {
-						nextIdx = rand.IntN(len(a.playlistTracks))
-						if nextIdx != idxCopy || len(a.playlistTracks) == 1 {
-							break
-						}
-					}
-				}
-				shouldPlayNext = true
-				nextTrack = a.playlistTracks[nextIdx]
-			}
-
-		case ModeRepeatAll, ModeNormal:
-			if len(a.playlistTracks) > 0 {
-				next := idxCopy + 1
-				if next >= len(a.playlistTracks) {
-					if mode == ModeRepeatAll {
-						next = 0
-						shouldPlayNext = true
-						nextTrack = a.playlistTracks[next]
-						nextIdx = next
-					} else {
-						shouldPlayNext = false
-					}
-				} else {
-					shouldPlayNext = true
-					nextTrack = a.playlistTracks[next]
-					nextIdx = next
-				}
-			}
-		}
-
-		a.mu.Unlock()
-
-		if shouldPlayNext {
-			go a.playTrackSimple(nextTrack, nextIdx)
-		} else {
-			a.mu.Lock()
-			a.isPlaying = false
-			a.mu.Unlock()
-
-			a.app.QueueUpdateDraw(func() {
-				a.updatePlayerInfo()
-				a.setStatus(a.theme.Yellow, a.strings.PlaylistFinished)
-			})
-		}
-	}(cmd, track, idx)
 }
 
 func (a *SimpleApp) playTrackDirect(track Track) {
-	a.cleanup()
-
-	socketPath := filepath.Join(os.TempDir(), fmt.Sprintf("mpv-socket-%d", time.Now().UnixNano()))
-
-	args := []string{
-		"--no-terminal",
-		"--script-opts=ytdl_hook-ytdl_path=yt-dlp",
-		fmt.Sprintf("--title=%s", track.Title),
-		fmt.Sprintf("--input-ipc-server=%s", socketPath),
+	if a.playTerminalVideo(track) {
+		return
 	}
+	if err := a.player.PlayTrack(track); err != nil {
+		a.showPlayerError(err)
+	}
+}
 
+func (a *SimpleApp) playTerminalVideo(track Track) bool {
 	a.mu.Lock()
-	playMode := a.playMode
 	quality := a.videoQuality
-	codec := a.videoCodec
 	a.mu.Unlock()
-
-	if quality == "tct" {
-		a.app.QueueUpdateDraw(func() {
-			a.setStatus(a.theme.Sapphire, a.strings.TerminalVideoStarting)
-		})
-		tctArgs := []string{
-			"--vo=tct",
-			"--vo-tct-algo=half-blocks",
-			"--vo-tct-256=yes",
-			"--really-quiet",
-			"--script-opts=ytdl_hook-ytdl_path=yt-dlp",
-			"--ytdl-format=" + buildYtdlFormat("tct", ""),
-			track.URL,
-		}
-		a.app.Suspend(func() {
-			tctCmd := exec.Command("mpv", tctArgs...)
-			tctCmd.Stdin = os.Stdin
-			tctCmd.Stdout = os.Stdout
-			tctCmd.Stderr = os.Stderr
-			_ = tctCmd.Run()
-		})
-		a.app.QueueUpdateDraw(func() {
-			a.setStatus(a.theme.Green, "▶ "+a.strings.PlaybackFinished)
-		})
-		return
-	}
-
-	if playMode == ModeAudio {
-		args = append(args, "--no-video", "--ytdl-format=bestaudio")
-	} else {
-		args = append(args, "--ytdl-format="+buildYtdlFormat(quality, codec))
-	}
-
-	args = append(args, track.URL)
-	cmd := exec.Command("mpv", args...)
-
-	var stderrBuf bytes.Buffer
-	cmd.Stderr = &stderrBuf
-
-	if err := cmd.Start(); err != nil {
-		a.app.QueueUpdateDraw(func() {
-			a.setStatusf(a.theme.Red, "❌%s %v", a.strings.errorStartMpv, err)
-		})
-		return
+	if quality != "tct" {
+		return false
 	}
 
-	a.mu.Lock()
-	a.mpvProcess = cmd
-	a.mpvSocket = socketPath
-	a.isPlaying = true
-	a.isPaused = false
-	a.nowPlaying = track.Title
-	a.currentThumb = track.Thumbnail
-	a.currentTrack = -1
-	a.position = 0
-	a.duration = 0
-	a.mu.Unlock()
+	_ = a.player.Stop()
+	target, isLocal := a.mpvTarget(track)
 
 	a.app.QueueUpdateDraw(func() {
-		a.updatePlayerInfo()
-		a.updateThumbnail(track.Thumbnail)
-		a.playlist.SetPlayingIndex(-1)
-		a.setStatusf(a.theme.Green, "▶ "+a.strings.PlayingWithoutPlaylist, track.Title)
+		a.setStatus(a.theme.Sapphire, a.strings.TerminalVideoStarting)
 	})
+	a.app.Suspend(func() {
+		tctCmd := exec.Command("mpv", engine.TerminalVideoArgs(target, isLocal)...)
+		tctCmd.Stdin = os.Stdin
+		tctCmd.Stdout = os.Stdout
+		tctCmd.Stderr = os.Stderr
+		_ = tctCmd.Run()
+	})
+	a.app.QueueUpdateDraw(func() {
+		a.setStatus(a.theme.Green, "▶ "+a.strings.PlaybackFinished)
+	})
+	return true
+}
 
-	a.startProgressUpdater()
-
-	go func(expectedCmd *exec.Cmd) {
-		err := expectedCmd.Wait()
-
-		a.mu.Lock()
-		if a.mpvProcess != expectedCmd {
-			a.mu.Unlock()
-			return
+func (a *SimpleApp) showPlayerError(err error) {
+	a.app.QueueUpdateDraw(func() {
+		switch {
+		case errors.Is(err, engine.ErrNothingPlaying):
+			a.setStatus(a.theme.Yellow, "⚠ "+a.strings.NothingPlaying)
+		case errors.Is(err, engine.ErrPlaylistEmpty):
+			a.setStatus(a.theme.Yellow, "⚠ "+a.strings.PlaylistEmpty)
+		case errors.Is(err, engine.ErrLastTrack):
+			a.setStatus(a.theme.Yellow, a.strings.AlreadyLastSong)
+		case errors.Is(err, engine.ErrFirstTrack):
+			a.setStatus(a.theme.Yellow, a.strings.AlreadyFirstSong)
+		case errors.Is(err, engine.ErrSocatMissing):
+			a.setStatus(a.theme.Red, "❌"+a.strings.socatNotInstalled)
+		default:
+			a.setStatusf(a.theme.Red, "❌%s %v", a.strings.errorStartMpv, err)
 		}
-		a.isPlaying = false
-		a.mu.Unlock()
-
-		a.app.QueueUpdateDraw(func() {
-			a.updatePlayerInfo()
-			if err != nil {
-				stderrOutput := stderrBuf.String()
-				if strings.Contains(stderrOutput, "403") || strings.Contains(stderrOutput, "HTTP error 403") {
-					a.setStatus(a.theme.Red, "❌"+a.strings.youtubeBlocked)
-				} else {
-					a.setStatusf(a.theme.Red, "❌ "+a.strings.MpvError, err)
-				}
-			} else {
-				a.setStatus(a.theme.Yellow, a.strings.PlaybackFinished)
-			}
-		})
-	}(cmd)
+	})
 }
 
 func (a *SimpleApp) togglePause() {
-	a.mu.Lock()
-	isPlaying := a.isPlaying
-	socket := a.mpvSocket
-	a.mu.Unlock()
-
-	if !isPlaying || socket == "" {
-		a.app.QueueUpdateDraw(func() {
-			a.setStatus(a.theme.Yellow, a.strings.nothingPlaying)
-		})
-		return
-	}
-
-	cmd := exec.Command("sh", "-c", fmt.Sprintf("echo '{ \"command\": [\"cycle\", \"pause\"] }' | socat - \"%s\" 2>&1", socket))
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		if strings.Contains(string(output), a.strings.socatCmdNotFound) {
-			a.app.QueueUpdateDraw(func() {
-				a.setStatus(a.theme.Red, "❌"+a.strings.socatNotInstalled)
-			})
-			return
-		}
-
-		a.app.QueueUpdateDraw(func() {
-			a.setStatusf(a.theme.Red, "❌"+a.strings.errorPause+"%v", err)
-		})
-		return
+	if err := a.player.TogglePause(); err != nil {
+		a.showPlayerError(err)
 	}
-
-	a.mu.Lock()
-	a.isPaused = !a.isPaused
-	isPaused := a.isPaused
-	a.mu.Unlock()
-
-	a.app.QueueUpdateDraw(func() {
-		a.updatePlayerInfo()
-		if isPaused {
-			a.setStatus(a.theme.Yellow, "⏸ "+a.strings.Paused)
-		} else {
-			a.setStatus(a.theme.Green, "▶ "+a.strings.Playing)
-		}
-	})
 }
 
 func (a *SimpleApp) stopPlayback() {
-	a.mu.Lock()
-	if a.mpvProcess != nil && a.mpvProcess.Process != nil {
-		if err := a.mpvProcess.Process.Kill(); err != nil {
-			fmt.Print("Warning:", a.strings.warnFailedKillMpv, err)
-		}
-		a.mpvProcess = nil
-	}
-	a.isPlaying = false
-	a.isPaused = false
-	a.currentTrack = -1
-	a.mu.Unlock()
+	_ = a.player.Stop()
 
 	a.app.QueueUpdateDraw(func() {
-		a.updatePlayerInfo()
-		a.updateThumbnail("")
-		a.playlist.SetPlayingIndex(-1)
 		a.setStatus(a.theme.Red, "⏹ "+a.strings.Stopped)
 	})
 }
 
 func (a *SimpleApp) playNext() {
-	a.mu.Lock()
-	currentIsPlaying := a.isPlaying
-	currentTrack := a.currentTrack
-	playlistLen := len(a.playlistTracks)
-	mode := a.playlistMode
-	a.mu.Unlock()
-
-	if playlistLen == 0 {
-		a.app.QueueUpdateDraw(func() {
-			a.setStatus(a.theme.Yellow, "⚠ "+a.strings.PlaylistEmpty)
-		})
-		return
-	}
-
-	if !currentIsPlaying {
-		a.app.QueueUpdateDraw(func() {
-			a.setStatus(a.theme.Yellow, "⚠ "+a.strings.NothingPlaying)
-		})
-		return
-	}
-
-	if currentTrack < 0 {
-		a.mu.Lock()
-		track := a.playlistTracks[0]
-		a.skipAutoPlay = true
-		a.mu.Unlock()
-
-		a.app.QueueUpdateDraw(func() {
-			a.setStatus(a.theme.Sapphire, "▶ "+a.strings.EnteringPlaylist)
-		})
-		go a.playTrackSimple(track, 0)
-		return
-	}
-
-	var next int
-	if mode == ModeShuffle {
-		if playlistLen == 1 {
-			next = 0
-		} else {
-			for {
-				next = rand.IntN(playlistLen)
-				if next != currentTrack {
-					break
-				}
-			}
-		}
-	} else {
-		next = currentTrack + 1
-		if next >= playlistLen {
-			if mode == ModeRepeatAll {
-				next = 0
-			} else {
-				a.app.QueueUpdateDraw(func() {
-					a.setStatus(a.theme.Yellow, a.strings.AlreadyLastSong)
-				})
-				return
-			}
-		}
+	if err := a.player.Next(); err != nil {
+		a.showPlayerError(err)
 	}
-
-	a.mu.Lock()
-	track := a.playlistTracks[next]
-	a.skipAutoPlay = true
-	a.mu.Unlock()
-
-	a.app.QueueUpdateDraw(func() {
-		a.setStatusf(a.theme.Green, "▶ "+a.strings.SkippingTo, next+1, playlistLen, track.Title)
-	})
-
-	go a.playTrackSimple(track, next)
 }
 
 func (a *SimpleApp) playPrevious() {
-	a.mu.Lock()
-	currentTrack := a.currentTrack
-	playlistLen := len(a.playlistTracks)
-	isPlaying := a.isPlaying
-	mode := a.playlistMode
-	a.mu.Unlock()
-
-	if playlistLen == 0 {
-		a.app.QueueUpdateDraw(func() {
-			a.setStatus(a.theme.Yellow, "⚠ "+a.strings.PlaylistEmpty)
-		})
-		return
+	if err := a.player.Previous(); err != nil {
+		a.showPlayerError(err)
 	}
+}
 
-	if !isPlaying {
-		a.app.QueueUpdateDraw(func() {
-			a.setStatus(a.theme.Yellow, "⚠ "+a.strings.NothingPlaying)
-		})
-		return
-	}
+func (a *SimpleApp) seekMedia(seconds float64) {
+	_ = a.player.Seek(seconds, false)
+}
 
-	if currentTrack < 0 {
-		lastIdx := playlistLen - 1
-		a.mu.Lock()
-		track := a.playlistTracks[lastIdx]
-		a.skipAutoPlay = true
-		a.mu.Unlock()
+func (a *SimpleApp) seekMediaTo(seconds float64) {
+	_ = a.player.Seek(seconds, true)
+}
 
-		a.app.QueueUpdateDraw(func() {
-			a.setStatus(a.theme.Sapphire, "▶ "+a.strings.EnteringPlaylist)
-		})
-		go a.playTrackSimple(track, lastIdx)
+func (a *SimpleApp) setVolume(volume int) {
+	volume = min(max(volume, 0), engine.MaxVolume)
+	if err := a.player.SetVolume(volume); err != nil {
+		a.showPlayerError(err)
 		return
 	}
 
-	prev := currentTrack - 1
-	if prev < 0 {
-		if mode == ModeRepeatAll {
-			prev = playlistLen - 1
-		} else {
-			a.app.QueueUpdateDraw(func() {
-				a.setStatus(a.theme.Yellow, a.strings.AlreadyFirstSong)
-			})
-			return
-		}
-	}
-
-	a.mu.Lock()
-	track := a.playlistTracks[prev]
-	a.skipAutoPlay = true
-	a.mu.Unlock()
-
 	a.app.QueueUpdateDraw(func() {
-		a.setStatusf(a.theme.Green, "⏮ Back to: %s", track.Title)
+		a.setStatusf(a.theme.Sapphire, "🔊 "+a.strings.VolumeSet, volume)
 	})
-
-	go a.playTrackSimple(track, prev)
-}
-
-func (a *SimpleApp) seekMedia(seconds float64) {
-	a.mu.Lock()
-	isPlaying := a.isPlaying
-	socket := a.mpvSocket
-	a.mu.Unlock()
-
-	if !isPlaying || socket == "" {
-		return
-	}
-
-	cmd := exec.Command("sh", "-c", fmt.Sprintf(`echo '{ "command": ["seek", %g, "relative"] }' | socat - "%s" 2>&1`, seconds, socket))
-	cmd.Run()
-
-	go func() {
-		time.Sleep(150 * time.Millisecond)
-		a.updateProgress()
-	}()
 }
 
 func (a *SimpleApp) toggleMode() {
@@ -591,6 +139,8 @@ func (a *SimpleApp) toggleMode() {
 	newMode := a.playMode
 	a.mu.Unlock()
 
+	_ = a.player.SetPlayMode(newMode)
+
 	go func() {
 		cfg, _ := config.LoadConfig()
 		if newMode == ModeVideo {
@@ -2,6 +2,14 @@ package ui
 
 import (
 	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/search"
 )
 
 func (a *SimpleApp) onPlaylistSelectedCustom() {
@@ -15,16 +23,62 @@ func (a *SimpleApp) onPlaylistSelectedCustom() {
 }
 
 func (a *SimpleApp) addToPlaylist(track Track) {
+	a.addTracksToPlaylist([]Track{track})
+}
+
+func (a *SimpleApp) addTracksToPlaylist(tracks []Track) {
+	if len(tracks) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for i := range tracks {
+		if tracks[i].AddedAt.IsZero() {
+			tracks[i].AddedAt = now
+		}
+	}
+
 	a.mu.Lock()
-	a.playlistTracks = append(a.playlistTracks, track)
+	first := len(a.playlistTracks)
+	a.playlistTracks = append(a.playlistTracks, tracks...)
 	count := len(a.playlistTracks)
-	index := count - 1
+	a.syncPlaylistLocked()
 	a.mu.Unlock()
 
 	a.app.QueueUpdateDraw(func() {
-		a.playlist.AddItem(track, index)
+		for i, track := range tracks {
+			index := first + i
+			a.playlist.AddItem(track, index)
+
+			if track.Thumbnail != "" && a.thumbCache != nil {
+				go func(idx int, url string) {
+					img, err := a.thumbCache.GetThumbnailImage(url)
+					if err == nil && img != nil {
+						a.app.QueueUpdateDraw(func() {
+							a.playlist.SetThumbnail(idx, img)
+						})
+					}
+				}(index, track.Thumbnail)
+			}
+		}
+
+		a.AutoSaveState()
+		a.playlist.SetTitle(fmt.Sprintf(" Playlist [%d] ", count))
+		a.updateShuffleOrderView()
+		if len(tracks) == 1 {
+			a.setStatus(a.theme.Green, "✓ "+fmt.Sprintf(a.strings.AddedToPlaylist, tracks[0].Title))
+		} else {
+			a.setStatusf(a.theme.Green, "✓ "+a.strings.PlaylistImported, len(tracks))
+		}
+	})
+}
 
-		if track.Thumbnail != "" && a.thumbCache != nil {
+func (a *SimpleApp) reloadPlaylistView(tracks []Track) {
+	a.playlist.Clear()
+	for i, t := range tracks {
+		a.playlist.AddItem(t, i)
+
+		if t.Thumbnail != "" && a.thumbCache != nil {
 			go func(idx int, url string) {
 				img, err := a.thumbCache.GetThumbnailImage(url)
 				if err == nil && img != nil {
@@ -32,122 +86,217 @@ func (a *SimpleApp) addToPlaylist(track Track) {
 						a.playlist.SetThumbnail(idx, img)
 					})
 				}
-			}(index, track.Thumbnail)
+			}(i, t.Thumbnail)
 		}
+	}
+	a.playlist.SetTitle(fmt.Sprintf(" Playlist [%d] ", len(tracks)))
+}
 
-		a.AutoSaveState()
-		a.playlist.SetTitle(fmt.Sprintf(" Playlist [%d] ", count))
-		a.setStatus(a.theme.Green, "✓ "+fmt.Sprintf(a.strings.AddedToPlaylist, track.Title))
-	})
+func (a *SimpleApp) applyPlaylistOrder(order []int) {
+	tracks := make([]Track, 0, len(order))
+	current := -1
+	for i, old := range order {
+		tracks = append(tracks, a.playlistTracks[old])
+		if old == a.currentTrack {
+			current = i
+		}
+	}
+	a.playlistTracks = tracks
+	a.currentTrack = current
+	a.cutIndices = nil
+	a.syncPlaylistLocked()
+}
+
+func (a *SimpleApp) refreshPlaylistView(cursor int, marked []int) {
+	a.mu.Lock()
+	tracks := make([]Track, len(a.playlistTracks))
+	copy(tracks, a.playlistTracks)
+	currentIdx := a.currentTrack
+	a.mu.Unlock()
+
+	a.reloadPlaylistView(tracks)
+	a.playlist.SetCurrentIndex(min(cursor, len(tracks)-1))
+	if len(marked) > 0 {
+		a.playlist.SetMarked(marked)
+	}
+	a.playlist.SetPlayingIndex(currentIdx)
+	a.updateShuffleOrderView()
+	a.updateCommandBar()
+	a.AutoSaveState()
 }
 
-func (a *SimpleApp) removeFromPlaylist(idx int) {
+func (a *SimpleApp) removeFromPlaylist(idxs []int) {
 	a.mu.Lock()
-	if idx < 0 || idx >= len(a.playlistTracks) {
+	remove := make(map[int]bool, len(idxs))
+	for _, idx := range idxs {
+		if idx >= 0 && idx < len(a.playlistTracks) {
+			remove[idx] = true
+		}
+	}
+	if len(remove) == 0 {
 		a.mu.Unlock()
 		return
 	}
 
-	if idx == a.currentTrack {
+	if remove[a.currentTrack] {
 		a.mu.Unlock()
 		a.stopPlayback()
 		a.mu.Lock()
-	} else if idx < a.currentTrack {
-		a.currentTrack--
 	}
 
-	a.playlistTracks = append(a.playlistTracks[:idx], a.playlistTracks[idx+1:]...)
-	tracks := make([]Track, len(a.playlistTracks))
-	copy(tracks, a.playlistTracks)
-	count := len(a.playlistTracks)
+	order := make([]int, 0, len(a.playlistTracks)-len(remove))
+	for i := range a.playlistTracks {
+		if !remove[i] {
+			order = append(order, i)
+		}
+	}
+	a.applyPlaylistOrder(order)
 	a.mu.Unlock()
 
+	cursor := a.playlist.GetCurrentItem()
+	removed := len(remove)
+
 	a.app.QueueUpdateDraw(func() {
-		a.playlist.Clear()
-		for i, t := range tracks {
-			a.playlist.AddItem(t, i)
+		a.refreshPlaylistView(cursor, nil)
 
-			if t.Thumbnail != "" && a.thumbCache != nil {
-				go func(idx int, url string) {
-					img, err := a.thumbCache.GetThumbnailImage(url)
-					if err == nil && img != nil {
-						a.app.QueueUpdateDraw(func() {
-							a.playlist.SetThumbnail(idx, img)
-						})
-					}
-				}(i, t.Thumbnail)
-			}
+		if removed == 1 {
+			a.setStatus(a.theme.Yellow, "✓ "+a.strings.RemovedFromPlaylist)
+		} else {
+			a.setStatusf(a.theme.Yellow, "✓ "+a.strings.RemovedManyFromPlaylist, removed)
 		}
-		a.playlist.SetTitle(fmt.Sprintf(" Playlist [%d] ", count))
-
-		a.mu.Lock()
-		currentIdx := a.currentTrack
-		a.mu.Unlock()
-		a.playlist.SetPlayingIndex(currentIdx)
-
-		a.setStatus(a.theme.Yellow, "✓ "+a.strings.RemovedFromPlaylist)
-		a.AutoSaveState()
 	})
 }
 
-func (a *SimpleApp) movePlaylistItem(from, to int) {
+func (a *SimpleApp) movePlaylistItems(idxs []int, to int) {
 	a.mu.Lock()
-	if from < 0 || from >= len(a.playlistTracks) {
+	n := len(a.playlistTracks)
+	moving := make(map[int]bool, len(idxs))
+	for _, idx := range idxs {
+		if idx >= 0 && idx < n {
+			moving[idx] = true
+		}
+	}
+	if len(moving) == 0 {
 		a.mu.Unlock()
 		return
 	}
-	if to < 0 || to >= len(a.playlistTracks) {
+
+	var moved, rest []int
+	for i := range n {
+		if moving[i] {
+			moved = append(moved, i)
+		} else {
+			rest = append(rest, i)
+		}
+	}
+
+	to = max(0, min(to, len(rest)))
+	if to == moved[0] && moved[len(moved)-1]-moved[0] == len(moved)-1 {
 		a.mu.Unlock()
 		return
 	}
-	if from == to {
-		a.mu.Unlock()
+
+	order := make([]int, 0, n)
+	order = append(order, rest[:to]...)
+	order = append(order, moved...)
+	order = append(order, rest[to:]...)
+	a.applyPlaylistOrder(order)
+	a.mu.Unlock()
+
+	hadSelection := a.playlist.HasSelection()
+	var marked []int
+	if hadSelection {
+		for i := range moved {
+			marked = append(marked, to+i)
+		}
+	}
+
+	a.app.QueueUpdateDraw(func() {
+		a.refreshPlaylistView(to, marked)
+		a.setStatusf(a.theme.Sapphire, "✓ "+a.strings.MovedTo, to+1)
+	})
+}
+
+func (a *SimpleApp) shiftPlaylistSelection(delta int) {
+	idxs := a.playlist.SelectedIndices()
+	if len(idxs) == 0 {
+		return
+	}
+	a.movePlaylistItems(idxs, idxs[0]+delta)
+}
+
+func (a *SimpleApp) promptMovePlaylistItems() {
+	idxs := a.playlist.SelectedIndices()
+	if len(idxs) == 0 {
 		return
 	}
 
-	a.playlistTracks[from], a.playlistTracks[to] = a.playlistTracks[to], a.playlistTracks[from]
+	a.mu.Lock()
+	n := len(a.playlistTracks)
+	a.mu.Unlock()
+
+	a.showPrompt(fmt.Sprintf(a.strings.MoveToPrompt, n), "", nil, func(text string, ok bool) {
+		if !ok {
+			return
+		}
+		pos, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil || pos < 1 || pos > n {
+			a.setStatusf(a.theme.Red, "❌ "+a.strings.InvalidPosition, text)
+			return
+		}
+		go a.movePlaylistItems(idxs, pos-1)
+	})
+}
 
-	switch a.currentTrack {
-	case from:
-		a.currentTrack = to
-	case to:
-		a.currentTrack = from
+func (a *SimpleApp) cutPlaylistItems() {
+	idxs := a.playlist.SelectedIndices()
+	if len(idxs) == 0 {
+		return
 	}
 
-	tracks := make([]Track, len(a.playlistTracks))
-	copy(tracks, a.playlistTracks)
-	newPos := to
+	a.mu.Lock()
+	a.cutIndices = idxs
 	a.mu.Unlock()
 
-	a.app.QueueUpdateDraw(func() {
-		a.playlist.Clear()
-		for i, t := range tracks {
-			a.playlist.AddItem(t, i)
+	a.playlist.ClearSelection()
+	a.updateCommandBar()
+	a.setStatusf(a.theme.Sapphire, "✂ "+a.strings.ItemsCut, len(idxs))
+}
 
-			if t.Thumbnail != "" && a.thumbCache != nil {
-				go func(idx int, url string) {
-					img, err := a.thumbCache.GetThumbnailImage(url)
-					if err == nil && img != nil {
-						a.app.QueueUpdateDraw(func() {
-							a.playlist.SetThumbnail(idx, img)
-						})
-					}
-				}(i, t.Thumbnail)
-			}
-		}
-		a.playlist.SetCurrentIndex(newPos)
+func (a *SimpleApp) pastePlaylistItems(after bool) {
+	cursor := a.playlist.GetCurrentItem()
 
-		a.mu.Lock()
-		currentIdx := a.currentTrack
-		a.mu.Unlock()
-		a.playlist.SetPlayingIndex(currentIdx)
+	a.mu.Lock()
+	cut := a.cutIndices
+	a.mu.Unlock()
 
-		a.setStatus(a.theme.Sapphire, "✓ "+a.strings.ItemMoved)
-		a.AutoSaveState()
-	})
+	if len(cut) == 0 {
+		a.app.QueueUpdateDraw(func() {
+			a.setStatus(a.theme.Yellow, "⚠ "+a.strings.NothingToPaste)
+		})
+		return
+	}
+
+	isCut := make(map[int]bool, len(cut))
+	for _, i := range cut {
+		isCut[i] = true
+	}
+
+	to := 0
+	for i := 0; i < cursor; i++ {
+		if !isCut[i] {
+			to++
+		}
+	}
+	if after && !isCut[cursor] {
+		to++
+	}
+
+	a.movePlaylistItems(cut, to)
 }
 
 func (a *SimpleApp) cycleRepeatMode() {
+	a.mu.Lock()
 	switch a.playlistMode {
 	case ModeNormal:
 		a.playlistMode = ModeRepeatOne
@@ -156,6 +305,10 @@ func (a *SimpleApp) cycleRepeatMode() {
 	case ModeRepeatAll:
 		a.playlistMode = ModeNormal
 	}
+	mode := a.playlistMode
+	a.mu.Unlock()
+
+	_ = a.player.SetPlaylistMode(mode)
 
 	a.app.QueueUpdateDraw(func() {
 		a.updatePlayerInfo()
@@ -165,15 +318,206 @@ func (a *SimpleApp) cycleRepeatMode() {
 }
 
 func (a *SimpleApp) toggleShuffle() {
+	a.mu.Lock()
 	if a.playlistMode == ModeShuffle {
 		a.playlistMode = ModeNormal
 	} else {
 		a.playlistMode = ModeShuffle
 	}
+	mode := a.playlistMode
+	a.mu.Unlock()
+
+	_ = a.player.SetPlaylistMode(mode)
 
 	a.app.QueueUpdateDraw(func() {
 		a.updatePlayerInfo()
 		a.updatePlaylistFooter()
+		a.updateShuffleOrderView()
 		a.setStatus(a.theme.Sapphire, "  "+fmt.Sprintf(a.strings.ModeChanged, a.playlistMode.String()))
 	})
 }
+
+type playlistSortKey int
+
+const (
+	sortByTitle playlistSortKey = iota
+	sortByAuthor
+	sortByDuration
+	sortByAdded
+	sortByRating
+)
+
+func (a *SimpleApp) showPlaylistActions() {
+	a.showMenu(a.strings.PlaylistActions, []menuItem{
+		{a.strings.SortByTitle, 't', func() { go a.sortPlaylist(sortByTitle) }},
+		{a.strings.SortByAuthor, 'a', func() { go a.sortPlaylist(sortByAuthor) }},
+		{a.strings.SortByDuration, 'd', func() { go a.sortPlaylist(sortByDuration) }},
+		{a.strings.SortByAdded, 'n', func() { go a.sortPlaylist(sortByAdded) }},
+		{a.strings.SortByRating, '*', func() { go a.sortPlaylist(sortByRating) }},
+		{a.strings.ReverseOrder, 'r', func() { go a.reversePlaylist() }},
+		{a.strings.RemoveDuplicates, 'u', func() { go a.dedupePlaylist() }},
+		{a.strings.ShuffleOrder, 's', func() { go a.shufflePlaylistOrder() }},
+		{a.strings.MakeOffline, 'f', a.makePlaylistOffline},
+		{a.strings.Close, 'q', nil},
+	})
+}
+
+func (a *SimpleApp) reorderPlaylist(plan func(tracks []Track, current int) []int) (removed int, ok bool) {
+	a.mu.Lock()
+	before := len(a.playlistTracks)
+	if before == 0 {
+		a.mu.Unlock()
+		a.app.QueueUpdateDraw(func() {
+			a.setStatus(a.theme.Yellow, "⚠ "+a.strings.PlaylistEmpty)
+		})
+		return 0, false
+	}
+	order := plan(a.playlistTracks, a.currentTrack)
+	a.applyPlaylistOrder(order)
+	a.mu.Unlock()
+
+	a.app.QueueUpdateDraw(func() {
+		a.playlist.ClearSelection()
+		a.refreshPlaylistView(0, nil)
+	})
+	return before - len(order), true
+}
+
+func (a *SimpleApp) reorderPlaylistWithStatus(plan func(tracks []Track, current int) []int, status string) {
+	if _, ok := a.reorderPlaylist(plan); ok {
+		a.app.QueueUpdateDraw(func() {
+			a.setStatus(a.theme.Sapphire, "✓ "+status)
+		})
+	}
+}
+
+func identityOrder(n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+func (a *SimpleApp) sortPlaylist(by playlistSortKey) {
+	labels := map[playlistSortKey]string{
+		sortByTitle:    a.strings.SortByTitle,
+		sortByAuthor:   a.strings.SortByAuthor,
+		sortByDuration: a.strings.SortByDuration,
+		sortByAdded:    a.strings.SortByAdded,
+		sortByRating:   a.strings.SortByRating,
+	}
+
+	a.reorderPlaylistWithStatus(func(tracks []Track, _ int) []int {
+		order := identityOrder(len(tracks))
+		sort.SliceStable(order, func(i, j int) bool {
+			ti, tj := tracks[order[i]], tracks[order[j]]
+			switch by {
+			case sortByAuthor:
+				return strings.ToLower(ti.Author) < strings.ToLower(tj.Author)
+			case sortByDuration:
+				return durationSeconds(ti.Duration) < durationSeconds(tj.Duration)
+			case sortByAdded:
+				return ti.AddedAt.Before(tj.AddedAt)
+			case sortByRating:
+				ri, rj := a.ratings.Get(ti.URL), a.ratings.Get(tj.URL)
+				if ri.Stars != rj.Stars {
+					return ri.Stars > rj.Stars
+				}
+				return ri.Favorite && !rj.Favorite
+			default:
+				return strings.ToLower(ti.Title) < strings.ToLower(tj.Title)
+			}
+		})
+		return order
+	}, fmt.Sprintf(a.strings.PlaylistSorted, labels[by]))
+}
+
+func (a *SimpleApp) reversePlaylist() {
+	a.reorderPlaylistWithStatus(func(tracks []Track, _ int) []int {
+		order := identityOrder(len(tracks))
+		slices.Reverse(order)
+		return order
+	}, a.strings.PlaylistReversed)
+}
+
+func (a *SimpleApp) shufflePlaylistOrder() {
+	a.reorderPlaylistWithStatus(func(tracks []Track, _ int) []int {
+		return rand.Perm(len(tracks))
+	}, a.strings.PlaylistShuffled)
+}
+
+func (a *SimpleApp) dedupePlaylist() {
+	removed, ok := a.reorderPlaylist(func(tracks []Track, current int) []int {
+		keep := make(map[string]int, len(tracks))
+		for i, t := range tracks {
+			if _, ok := keep[trackKey(t)]; !ok {
+				keep[trackKey(t)] = i
+			}
+		}
+		if current >= 0 && current < len(tracks) {
+			keep[trackKey(tracks[current])] = current
+		}
+
+		order := make([]int, 0, len(keep))
+		for i, t := range tracks {
+			if keep[trackKey(t)] == i {
+				order = append(order, i)
+			}
+		}
+		return order
+	})
+	if !ok {
+		return
+	}
+
+	a.app.QueueUpdateDraw(func() {
+		a.setStatusf(a.theme.Sapphire, "✓ "+a.strings.DuplicatesRemoved, removed)
+	})
+}
+
+func trackKey(t Track) string {
+	if id := search.VideoID(t.URL); id != "" {
+		return id
+	}
+	return t.URL
+}
+
+func (a *SimpleApp) toggleShuffleOrderView() {
+	a.mu.Lock()
+	a.showShuffleOrder = !a.showShuffleOrder
+	show := a.showShuffleOrder
+	mode := a.playlistMode
+	a.mu.Unlock()
+
+	a.updateShuffleOrderView()
+	switch {
+	case show && mode != ModeShuffle:
+		a.setStatus(a.theme.Yellow, "⚠ "+a.strings.ShuffleOrderNeedsShuffle)
+	case show:
+		a.setStatus(a.theme.Teal, "  "+a.strings.ShuffleOrderShown)
+	default:
+		a.setStatus(a.theme.Subtext0, "  "+a.strings.ShuffleOrderHidden)
+	}
+}
+
+func (a *SimpleApp) updateShuffleOrderView() {
+	a.mu.Lock()
+	show := a.showShuffleOrder && a.playlistMode == ModeShuffle
+	count := len(a.playlistTracks)
+	a.mu.Unlock()
+
+	if !show {
+		a.playlist.SetQueueOrder(nil)
+		return
+	}
+	go func() {
+		upcoming, err := a.player.Upcoming(count)
+		if err != nil {
+			return
+		}
+		a.app.QueueUpdateDraw(func() {
+			a.playlist.SetQueueOrder(upcoming)
+		})
+	}()
+}
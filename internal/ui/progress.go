@@ -2,71 +2,9 @@ package ui
 
 import (
 	"fmt"
-	"os/exec"
-	"strconv"
 	"strings"
-	"time"
 )
 
-func (a *SimpleApp) startProgressUpdater() {
-	a.mu.Lock()
-	if a.stopProgress != nil {
-		select {
-		case <-a.stopProgress:
-		default:
-			close(a.stopProgress)
-		}
-	}
-	a.stopProgress = make(chan bool)
-	stopChan := a.stopProgress
-	a.mu.Unlock()
-
-	go func() {
-		ticker := time.NewTicker(500 * time.Millisecond)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				a.mu.Lock()
-				isPlaying := a.isPlaying
-				a.mu.Unlock()
-				if !isPlaying {
-					return
-				}
-				a.updateProgress()
-			case <-stopChan:
-				return
-			}
-		}
-	}()
-}
-
-func (a *SimpleApp) updateProgress() {
-	if a.mpvSocket == "" {
-		return
-	}
-
-	posCmd := exec.Command("sh", "-c", fmt.Sprintf("echo '{ \"command\": [\"get_property\", \"time-pos\"] }' | socat - UNIX-CONNECT:%s 2>/dev/null | grep -o '\"data\":[0-9.]*' | cut -d: -f2", a.mpvSocket))
-	posOut, _ := posCmd.Output()
-	if len(posOut) > 0 {
-		if pos, err := strconv.ParseFloat(strings.TrimSpace(string(posOut)), 64); err == nil {
-			a.position = pos
-		}
-	}
-
-	durCmd := exec.Command("sh", "-c", fmt.Sprintf("echo '{ \"command\": [\"get_property\", \"duration\"] }' | socat - UNIX-CONNECT:%s 2>/dev/null | grep -o '\"data\":[0-9.]*' | cut -d: -f2", a.mpvSocket))
-	durOut, _ := durCmd.Output()
-	if len(durOut) > 0 {
-		if dur, err := strconv.ParseFloat(strings.TrimSpace(string(durOut)), 64); err == nil && dur > 0 {
-			a.duration = dur
-		}
-	}
-
-	a.app.QueueUpdateDraw(func() {
-		a.updatePlayerInfo()
-	})
-}
-
 func (a *SimpleApp) updatePlayerInfo() {
 	_, _, width, _ := a.playerInfo.GetInnerRect()
 	if width <= 0 {
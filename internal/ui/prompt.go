@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func (a *SimpleApp) showPrompt(label, initial string, onChange func(string), onDone func(text string, ok bool)) {
+	if a.promptField != nil {
+		a.closePrompt()
+	}
+
+	field := tview.NewInputField().
+		SetLabel(" " + label + " ").
+		SetText(initial).
+		SetLabelColor(a.theme.Mauve).
+		SetFieldBackgroundColor(a.theme.Surface0).
+		SetFieldTextColor(a.theme.Text)
+	field.SetBackgroundColor(a.theme.Base)
+
+	if onChange != nil {
+		field.SetChangedFunc(onChange)
+	}
+
+	field.SetDoneFunc(func(key tcell.Key) {
+		text := field.GetText()
+		a.closePrompt()
+		onDone(text, key == tcell.KeyEnter)
+	})
+
+	a.promptReturn = a.app.GetFocus()
+	a.promptField = field
+	a.app.SetRoot(a.getMainLayout(), true)
+	a.app.SetFocus(field)
+}
+
+func (a *SimpleApp) closePrompt() {
+	if a.promptField == nil {
+		return
+	}
+	a.promptField = nil
+	a.app.SetRoot(a.getMainLayout(), true)
+	if a.promptReturn != nil {
+		a.app.SetFocus(a.promptReturn)
+	}
+	a.updateCommandBar()
+}
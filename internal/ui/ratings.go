@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"github.com/IvelOt/youtui-player/internal/library"
+)
+
+func ratingMeta(t Track) library.Rating {
+	return library.Rating{
+		Title:     t.Title,
+		Author:    t.Author,
+		URL:       t.URL,
+		Thumbnail: t.Thumbnail,
+		Duration:  t.Duration,
+	}
+}
+
+func ratingTrack(r library.Rating) Track {
+	return Track{
+		Title:     r.Title,
+		Author:    r.Author,
+		URL:       r.URL,
+		Thumbnail: r.Thumbnail,
+		Duration:  r.Duration,
+		AddedAt:   r.UpdatedAt,
+	}
+}
+
+func (a *SimpleApp) trackMarkFor(t Track) trackMark {
+	r := a.ratings.Get(t.URL)
+	return trackMark{favorite: r.Favorite, stars: r.Stars}
+}
+
+func (a *SimpleApp) setupRatings() {
+	a.searchResults.SetRatingFunc(a.trackMarkFor)
+	a.playlist.SetRatingFunc(a.trackMarkFor)
+}
+
+func (a *SimpleApp) refreshRatingMarks() {
+	a.searchResults.RefreshBadges()
+	a.playlist.RefreshBadges()
+}
+
+func (a *SimpleApp) toggleFavorite(list *CustomList) {
+	tracks := list.SelectedTracks()
+	if len(tracks) == 0 {
+		a.setStatus(a.theme.Yellow, "⚠ "+a.strings.NoTrackSelected)
+		return
+	}
+
+	favorite := false
+	for _, t := range tracks {
+		if !a.ratings.Get(t.URL).Favorite {
+			favorite = true
+			break
+		}
+	}
+	for _, t := range tracks {
+		a.ratings.SetFavorite(ratingMeta(t), favorite)
+	}
+
+	a.refreshRatingMarks()
+	go a.refreshSmartPlaylist()
+	switch {
+	case len(tracks) > 1 && favorite:
+		a.setStatusf(a.theme.Red, "♥ "+a.strings.FavoritesAdded, len(tracks))
+	case len(tracks) > 1:
+		a.setStatusf(a.theme.Subtext0, "♡ "+a.strings.FavoritesRemoved, len(tracks))
+	case favorite:
+		a.setStatusf(a.theme.Red, "♥ "+a.strings.FavoriteAdded, tracks[0].Title)
+	default:
+		a.setStatusf(a.theme.Subtext0, "♡ "+a.strings.FavoriteRemoved, tracks[0].Title)
+	}
+}
+
+func (a *SimpleApp) rateTracks(list *CustomList, stars int) {
+	tracks := list.SelectedTracks()
+	if len(tracks) == 0 {
+		a.setStatus(a.theme.Yellow, "⚠ "+a.strings.NoTrackSelected)
+		return
+	}
+
+	for _, t := range tracks {
+		a.ratings.SetStars(ratingMeta(t), stars)
+	}
+
+	a.refreshRatingMarks()
+	go a.refreshSmartPlaylist()
+	if stars == 0 {
+		a.setStatusf(a.theme.Subtext0, "☆ "+a.strings.RatingCleared, len(tracks))
+		return
+	}
+	a.setStatusf(a.theme.Peach, "★ "+a.strings.Rated, len(tracks), trackMark{stars: stars}.starsText())
+}
+
+func (a *SimpleApp) showFavorites() {
+	favs := a.ratings.Favorites()
+	if len(favs) == 0 {
+		a.app.QueueUpdateDraw(func() {
+			a.setStatus(a.theme.Yellow, "⚠ "+a.strings.FavoritesEmpty)
+		})
+		return
+	}
+
+	tracks := make([]Track, len(favs))
+	for i, r := range favs {
+		tracks[i] = ratingTrack(r)
+	}
+
+	a.loadResultTracks(tracks, "")
+
+	a.app.QueueUpdateDraw(func() {
+		a.setStatusf(a.theme.Red, "♥ "+a.strings.FavoritesLoaded, len(tracks))
+		a.app.SetFocus(a.searchResults.Flex)
+		a.updateCommandBar()
+	})
+
+	a.AutoSaveState()
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/store"
+	"github.com/rivo/tview"
+)
+
+func (a *SimpleApp) showStoreRecovery(cause error) {
+	path := config.GetStorePath()
+	backups := store.Backups(path)
+
+	var labels []string
+	for _, b := range backups {
+		labels = append(labels, fmt.Sprintf(a.strings.RecoveryRestore, b.ModTime.Format("2006-01-02 15:04")))
+	}
+	labels = append(labels, a.strings.RecoveryStartFresh, a.strings.RecoveryContinue)
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf(a.strings.RecoveryText, cause, path)).
+		AddButtons(labels).
+		SetDoneFunc(func(index int, _ string) {
+			a.inModal = false
+			a.app.SetRoot(a.getMainLayout(), true)
+
+			switch {
+			case index < len(backups):
+				go a.recoverStore(backups[index].Path)
+			case index == len(backups):
+				go a.recoverStore("")
+			}
+		})
+	modal.SetBackgroundColor(a.theme.Surface0)
+	modal.SetTextColor(a.theme.Text)
+	modal.SetButtonBackgroundColor(a.theme.Surface1)
+	modal.SetButtonTextColor(a.theme.Text)
+	modal.SetBorderColor(a.theme.Red)
+
+	a.inModal = true
+	a.app.SetRoot(modal, true)
+}
+
+func (a *SimpleApp) recoverStore(backup string) {
+	aside, err := a.owner.Recover(backup)
+	if err != nil {
+		a.app.QueueUpdateDraw(func() {
+			a.setStatusf(a.theme.Red, "❌ "+a.strings.StoreError, err)
+		})
+		return
+	}
+
+	a.app.QueueUpdateDraw(func() {
+		if backup != "" {
+			a.setStatusf(a.theme.Green, "✓ "+a.strings.RecoveryRestored, aside)
+		} else {
+			a.setStatusf(a.theme.Yellow, "⚠ "+a.strings.RecoveryFresh, aside)
+		}
+	})
+
+	if backup != "" {
+		a.restoreStateWithStatus()
+	}
+}
+
+func (a *SimpleApp) restoreStateWithStatus() {
+	if err := a.RestoreState(); err != nil {
+		a.app.QueueUpdateDraw(func() {
+			a.setStatusf(a.theme.Red, "❌ "+a.strings.StateRestoreError, err)
+		})
+	}
+}
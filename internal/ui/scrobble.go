@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"errors"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/engine"
+	"github.com/IvelOt/youtui-player/internal/persist"
+	"github.com/IvelOt/youtui-player/internal/scrobble"
+)
+
+type scrobbling struct {
+	scrobbler *scrobble.Scrobbler
+	watcher   *scrobble.Watcher
+}
+
+func scrobbleClients(cfg config.ScrobbleConfig) ([]scrobble.Client, error) {
+	var clients []scrobble.Client
+	var errs []error
+	if lf := cfg.LastFM; lf.Enabled {
+		c, err := scrobble.NewLastFM(scrobble.LastFMOptions{
+			APIKey:     lf.APIKey,
+			APISecret:  lf.APISecret,
+			SessionKey: lf.SessionKey,
+			Username:   lf.Username,
+			Password:   lf.Password,
+			BaseURL:    lf.BaseURL,
+		})
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			clients = append(clients, c)
+		}
+	}
+	if lb := cfg.ListenBrainz; lb.Enabled {
+		c, err := scrobble.NewListenBrainz(scrobble.ListenBrainzOptions{
+			Token:   lb.Token,
+			BaseURL: lb.BaseURL,
+		})
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			clients = append(clients, c)
+		}
+	}
+	return clients, errors.Join(errs...)
+}
+
+func startScrobbling(ctrl engine.Controller, skip func() bool, onError func(error)) (*scrobbling, error) {
+	cfg, _ := config.LoadConfig()
+	clients, cfgErr := scrobbleClients(cfg.Scrobble)
+	if len(clients) == 0 {
+		return nil, cfgErr
+	}
+
+	s, err := scrobble.New(clients, scrobble.Options{
+		QueuePath: config.GetScrobbleQueuePath(),
+		OnError:   onError,
+	})
+	if err != nil {
+		return nil, errors.Join(cfgErr, err)
+	}
+	w, err := scrobble.Watch(s, ctrl, skip)
+	if err != nil {
+		s.Close()
+		return nil, errors.Join(cfgErr, err)
+	}
+	return &scrobbling{scrobbler: s, watcher: w}, cfgErr
+}
+
+func (s *scrobbling) Close() {
+	s.watcher.Close()
+	s.scrobbler.Close()
+}
+
+func (a *SimpleApp) startScrobbling() {
+	s, err := startScrobbling(a.engine, ownerIncognito(a.owner), a.onScrobbleError)
+	if err != nil {
+		a.app.QueueUpdateDraw(func() {
+			a.setStatusf(a.theme.Yellow, "⚠ "+a.strings.ScrobbleDisabled, err)
+		})
+	}
+	if s == nil {
+		return
+	}
+	a.mu.Lock()
+	if a.detached {
+		a.mu.Unlock()
+		s.Close()
+		return
+	}
+	a.scrobbling = s
+	a.mu.Unlock()
+}
+
+func ownerIncognito(owner *persist.Owner) func() bool {
+	return func() bool {
+		on, _ := owner.Incognito()
+		return on
+	}
+}
+
+func (a *SimpleApp) onScrobbleError(err error) {
+	a.app.QueueUpdateDraw(func() {
+		if scrobble.AuthFailed(err) {
+			a.setStatusf(a.theme.Red, "❌ "+a.strings.ScrobbleAuthFailed, err)
+			return
+		}
+		a.setStatusf(a.theme.Yellow, "⚠ "+a.strings.ScrobbleFailed, err)
+	})
+}
@@ -10,28 +10,13 @@ import (
 	"github.com/IvelOt/youtui-player/internal/search"
 )
 
-func isYouTubeURL(s string) bool {
-	s = strings.TrimSpace(s)
-	return strings.Contains(s, "youtube.com/watch") ||
-		strings.Contains(s, "youtu.be/") ||
-		strings.Contains(s, "youtube.com/shorts/") ||
-		strings.Contains(s, "youtube.com/playlist") ||
-		strings.Contains(s, "music.youtube.com/watch")
-}
-
-func isPlaylistURL(s string) bool {
-	s = strings.TrimSpace(s)
-	return strings.Contains(s, "youtube.com/playlist?list=") ||
-		(isYouTubeURL(s) && strings.Contains(s, "&list="))
-}
-
 func (a *SimpleApp) onSearchDone(key tcell.Key) {
 	if key == tcell.KeyEnter {
 		query := strings.TrimSpace(a.searchInput.GetText())
 		if query != "" {
-			if isPlaylistURL(query) {
+			if search.IsPlaylistURL(query) {
 				go a.searchPlaylistURL(query)
-			} else if isYouTubeURL(query) {
+			} else if search.IsYouTubeURL(query) {
 				go a.searchVideoURL(query)
 			} else {
 				go a.doSearch(query)
@@ -81,6 +66,7 @@ func (a *SimpleApp) searchPlaylistURL(url string) {
 
 func (a *SimpleApp) populateResults(results []search.Result) {
 	a.mu.Lock()
+	a.activeSmart = ""
 	a.tracks = make([]Track, len(results))
 	for i, r := range results {
 		a.tracks[i] = Track{
@@ -97,6 +83,10 @@ func (a *SimpleApp) populateResults(results []search.Result) {
 	copy(tracksCopy, a.tracks)
 	a.mu.Unlock()
 
+	a.app.QueueUpdateDraw(func() {
+		a.searchResults.SetFilter("")
+	})
+
 	a.pagination.SetTotalItems(len(tracksCopy))
 	a.pagination.Reset()
 
@@ -125,17 +115,15 @@ func (a *SimpleApp) addAllToPlaylist() {
 		return
 	}
 
-	for _, track := range tracks {
-		a.addToPlaylist(track)
-	}
-
-	count := len(tracks)
-	a.app.QueueUpdateDraw(func() {
-		a.setStatusf(a.theme.Green, "✓ "+a.strings.PlaylistImported, count)
-	})
+	a.addTracksToPlaylist(tracks)
 }
 
 func (a *SimpleApp) yankURL(focused interface{}) {
+	if list := a.focusedList(focused); list != nil && list.HasSelection() {
+		a.yankSelection(list)
+		return
+	}
+
 	var url string
 
 	a.mu.Lock()
@@ -182,6 +170,36 @@ func (a *SimpleApp) yankURL(focused interface{}) {
 	})
 }
 
+func (a *SimpleApp) yankSelection(list *CustomList) {
+	tracks := list.SelectedTracks()
+	urls := make([]string, 0, len(tracks))
+	for _, t := range tracks {
+		if t.URL != "" {
+			urls = append(urls, t.URL)
+		}
+	}
+
+	if len(urls) == 0 {
+		a.app.QueueUpdateDraw(func() {
+			a.setStatus(a.theme.Yellow, "⚠ "+a.strings.NoTrackSelected)
+		})
+		return
+	}
+
+	if err := copyToClipboard(strings.Join(urls, "\n")); err != nil {
+		a.app.QueueUpdateDraw(func() {
+			a.setStatusf(a.theme.Red, "❌ "+a.strings.ClipboardError, err)
+		})
+		return
+	}
+
+	a.app.QueueUpdateDraw(func() {
+		list.ClearSelection()
+		a.updateCommandBar()
+		a.setStatusf(a.theme.Green, "  "+a.strings.URLsCopied, len(urls))
+	})
+}
+
 func (a *SimpleApp) doSearch(query string) {
 	a.app.QueueUpdateDraw(func() {
 		a.setStatus(a.theme.Yellow, "  "+a.strings.Searching)
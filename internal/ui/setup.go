@@ -207,11 +207,16 @@ func (a *SimpleApp) getMainLayout() tview.Primitive {
 		AddItem(a.statusBar, 0, 1, false).
 		AddItem(a.modeBadge, 24, 0, false)
 
+	var bottom tview.Primitive = a.commandBar
+	if a.promptField != nil {
+		bottom = a.promptField
+	}
+
 	return tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(topFlex, 0, 1, true).
 		AddItem(a.playerBox, 5, 0, false).
 		AddItem(statusBarFlex, 1, 0, false).
-		AddItem(a.commandBar, 1, 0, false)
+		AddItem(bottom, 1, 0, false)
 }
 
 func (a *SimpleApp) setupResizeHandler() {
@@ -236,11 +241,20 @@ func (a *SimpleApp) setupInputHandlers() {
 		focused := a.app.GetFocus()
 
 		if event.Key() == tcell.KeyCtrlQ {
-			a.cleanup()
+			a.shutdownPlayer()
+			a.downloads.Stop()
 			a.app.Stop()
 			return nil
 		}
 
+		if a.promptField != nil && focused == a.promptField {
+			return event
+		}
+
+		if a.historySearch != nil && focused == a.historySearch {
+			return event
+		}
+
 		if event.Key() == tcell.KeyEsc {
 			if a.inModal {
 				a.inModal = false
@@ -259,6 +273,26 @@ func (a *SimpleApp) setupInputHandlers() {
 			return nil
 		}
 
+		if event.Key() == tcell.KeyCtrlD && !a.inModal {
+			a.showDownloads()
+			return nil
+		}
+
+		if event.Key() == tcell.KeyCtrlR && !a.inModal {
+			a.showHistory()
+			return nil
+		}
+
+		if event.Key() == tcell.KeyCtrlT && !a.inModal {
+			a.showStats()
+			return nil
+		}
+
+		if event.Key() == tcell.KeyCtrlL && !a.inModal {
+			go a.showLibrary()
+			return nil
+		}
+
 		if a.inModal {
 			return event
 		}
@@ -470,6 +504,7 @@ func (a *SimpleApp) cycleVideoQuality() {
 	a.mu.Lock()
 	a.videoQuality = next
 	a.mu.Unlock()
+	a.syncVideoSettings()
 
 	cfg, _ := config.LoadConfig()
 	cfg.Playback.VideoQuality = next
@@ -499,6 +534,7 @@ func (a *SimpleApp) cycleVideoCodec() {
 	a.mu.Lock()
 	a.videoCodec = next
 	a.mu.Unlock()
+	a.syncVideoSettings()
 
 	cfg, _ := config.LoadConfig()
 	cfg.Playback.VideoCodec = next
@@ -532,20 +568,31 @@ func parseLanguage(s string) Language {
 }
 
 func (a *SimpleApp) applyLanguage(lang Language) {
+	a.mu.Lock()
 	a.language = lang
 	a.strings = GetStrings(lang)
+	a.mu.Unlock()
 
 	cfg, _ := config.LoadConfig()
 	cfg.UI.Language = string(lang)
 	_ = config.SaveConfig(cfg)
 
+	setSearchTexts(a.strings)
+}
+
+func setSearchTexts(s Strings) {
 	search.SetTexts(search.Texts{
-		EmptyQuery:       a.strings.EmptyQuery,
-		NoResultsFor:     a.strings.NoResultsFor,
-		YtDlpNotFound:    a.strings.YtDlpNotFound,
-		YtDlpStartFailed: a.strings.YtDlpStartFailed,
-		YtDlpError:       a.strings.YtDlpError,
-		UnknownDate:      a.strings.UnknownDate,
-		NoDescription:    a.strings.NoDescription,
+		EmptyQuery:       s.EmptyQuery,
+		NoResultsFor:     s.NoResultsFor,
+		YtDlpNotFound:    s.YtDlpNotFound,
+		YtDlpStartFailed: s.YtDlpStartFailed,
+		YtDlpError:       s.YtDlpError,
+		UnknownDate:      s.UnknownDate,
+		NoDescription:    s.NoDescription,
 	})
 }
+
+func UseConfiguredLanguage() {
+	cfg, _ := config.LoadConfig()
+	setSearchTexts(GetStrings(Language(cfg.UI.Language)))
+}
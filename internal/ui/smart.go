@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/smart"
+)
+
+const smartKeys = "123456789abcdeghijklmnoprstuvwxyz"
+
+func (a *SimpleApp) loadSmartRules() ([]smart.Rule, error) {
+	path := config.GetSmartPlaylistsPath()
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.smartLoaded && modTime.Equal(a.smartModTime) {
+		return a.smartRules, nil
+	}
+
+	rules, err := smart.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	a.smartRules = rules
+	a.smartModTime = modTime
+	a.smartLoaded = true
+	return rules, nil
+}
+
+func (a *SimpleApp) evaluateSmart(rule smart.Rule) []Track {
+	a.mu.Lock()
+	playlist := slices.Clone(a.playlistTracks)
+	a.mu.Unlock()
+
+	cands := smart.Collect(a.historyEntries(), a.ratings.All(), a.lib.Entries(), playlist)
+	matches := rule.Evaluate(cands, time.Now())
+
+	tracks := make([]Track, len(matches))
+	for i, c := range matches {
+		tracks[i] = Track{
+			Title:     c.Title,
+			Author:    c.Author,
+			URL:       c.URL,
+			Thumbnail: c.Thumbnail,
+			Duration:  c.Duration,
+			AddedAt:   c.Added,
+		}
+	}
+	return tracks
+}
+
+func (a *SimpleApp) showSmartPlaylists() {
+	rules, err := a.loadSmartRules()
+	if err != nil {
+		a.setStatusf(a.theme.Red, "❌ "+a.strings.SmartPlaylistError, err)
+		return
+	}
+	if len(rules) == 0 {
+		a.setStatusf(a.theme.Yellow, "⚠ "+a.strings.SmartPlaylistsEmpty, config.GetSmartPlaylistsPath())
+		return
+	}
+
+	items := make([]menuItem, 0, len(rules)+1)
+	for i, rule := range rules {
+		var key rune
+		if i < len(smartKeys) {
+			key = rune(smartKeys[i])
+		}
+		name := rule.Name
+		items = append(items, menuItem{
+			fmt.Sprintf("%s (%d)", name, len(a.evaluateSmart(rule))),
+			key,
+			func() { go a.openSmartPlaylist(name) },
+		})
+	}
+	items = append(items, menuItem{a.strings.Close, 'q', nil})
+
+	a.showMenu(a.strings.SmartPlaylists, items)
+}
+
+func (a *SimpleApp) findSmartRule(name string) (smart.Rule, bool) {
+	rules, err := a.loadSmartRules()
+	if err != nil {
+		return smart.Rule{}, false
+	}
+	for _, r := range rules {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return smart.Rule{}, false
+}
+
+func (a *SimpleApp) openSmartPlaylist(name string) {
+	rule, ok := a.findSmartRule(name)
+	if !ok {
+		return
+	}
+	tracks := a.evaluateSmart(rule)
+
+	a.loadResultTracks(tracks, name)
+
+	a.app.QueueUpdateDraw(func() {
+		if len(tracks) == 0 {
+			a.setStatusf(a.theme.Yellow, "⚠ "+a.strings.SmartPlaylistNoMatches, name)
+		} else {
+			a.setStatusf(a.theme.Mauve, "✦ "+a.strings.SmartPlaylistLoaded, name, len(tracks))
+		}
+		a.app.SetFocus(a.searchResults.Flex)
+		a.updateCommandBar()
+	})
+}
+
+func (a *SimpleApp) refreshSmartPlaylist() {
+	a.mu.Lock()
+	name := a.activeSmart
+	a.mu.Unlock()
+	if name == "" {
+		return
+	}
+
+	rule, ok := a.findSmartRule(name)
+	if !ok {
+		return
+	}
+	tracks := a.evaluateSmart(rule)
+
+	a.mu.Lock()
+	if a.activeSmart != name || slices.EqualFunc(a.tracks, tracks, func(x, y Track) bool { return x.URL == y.URL }) {
+		a.mu.Unlock()
+		return
+	}
+	a.tracks = tracks
+	a.pagination.SetTotalItems(len(tracks))
+	if a.pagination.GetCurrentPage() >= a.pagination.GetTotalPages() {
+		a.pagination.Reset()
+	}
+	a.mu.Unlock()
+
+	a.displayCurrentPage()
+}
+
+func (a *SimpleApp) watchSmartPlaylists() {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		a.refreshSmartPlaylist()
+	}
+}
@@ -0,0 +1,250 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/history"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const statsTop = 10
+
+var barBlocks = []rune(" ▁▂▃▄▅▆▇█")
+
+func (a *SimpleApp) setupStatsPanel() {
+	a.statsView = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false)
+	a.statsView.SetBackgroundColor(a.theme.Base)
+	a.statsView.SetBorder(true).
+		SetTitle(" " + a.strings.Stats + " ").
+		SetTitleColor(a.theme.Text).
+		SetBorderColor(a.theme.Blue)
+
+	footer := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText(a.strings.StatsHint)
+	footer.SetBackgroundColor(a.theme.Mantle)
+	footer.SetTextColor(a.theme.Subtext0)
+
+	a.statsView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'w':
+			a.statsPeriod = history.PeriodWeek
+		case 'm':
+			a.statsPeriod = history.PeriodMonth
+		case 'y':
+			a.statsPeriod = history.PeriodYear
+		case 'a':
+			a.statsPeriod = history.PeriodAll
+		case 'c':
+			a.exportStats("csv")
+			return nil
+		case 'J':
+			a.exportStats("json")
+			return nil
+		default:
+			return event
+		}
+		a.refreshStats()
+		return nil
+	})
+
+	a.statsPanel = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.statsView, 0, 1, true).
+		AddItem(footer, 1, 0, false)
+}
+
+func (a *SimpleApp) showStats() {
+	if a.statsPanel == nil {
+		a.statsPeriod = history.PeriodMonth
+		a.setupStatsPanel()
+	}
+	a.refreshStats()
+
+	a.inModal = true
+	a.prevFocused = a.app.GetFocus()
+	a.app.SetRoot(a.statsPanel, true)
+	a.app.SetFocus(a.statsView)
+}
+
+func (a *SimpleApp) currentStats() history.Stats {
+	return history.Compute(a.historyEntries(), a.statsPeriod, time.Now(), statsTop)
+}
+
+func (a *SimpleApp) refreshStats() {
+	st := a.currentStats()
+
+	var b strings.Builder
+	b.WriteString(" ")
+	for _, p := range []struct {
+		period history.Period
+		key    rune
+		label  string
+	}{
+		{history.PeriodWeek, 'w', a.strings.StatsWeek},
+		{history.PeriodMonth, 'm', a.strings.StatsMonth},
+		{history.PeriodYear, 'y', a.strings.StatsYear},
+		{history.PeriodAll, 'a', a.strings.StatsAll},
+	} {
+		if p.period == st.Period {
+			fmt.Fprintf(&b, "[%s:%s:b] %c %s [-:-:-] ", colorTag(a.theme.Crust), colorTag(a.theme.Blue), p.key, p.label)
+		} else {
+			fmt.Fprintf(&b, "[%s] %c %s [-] ", colorTag(a.theme.Subtext0), p.key, p.label)
+		}
+	}
+	b.WriteString("\n\n")
+
+	if st.Plays == 0 {
+		fmt.Fprintf(&b, " [%s]%s[-]\n", colorTag(a.theme.Subtext0), a.strings.StatsEmpty)
+		a.statsView.SetText(b.String())
+		return
+	}
+
+	label := func(s string) string { return "[" + colorTag(a.theme.Subtext0) + "]" + s + "[-]" }
+	value := func(s string) string { return "[" + colorTag(a.theme.Text) + "::b]" + s + "[-::-]" }
+	fmt.Fprintf(&b, " %s %s   %s %s (%d %s)   %s %s\n",
+		label(a.strings.StatsTotal), value(formatListenTime(st.Seconds)),
+		label(a.strings.StatsPlays), value(fmt.Sprint(st.Plays)), st.Completed, a.strings.StatsCompleted,
+		label(a.strings.StatsActiveDays), value(fmt.Sprint(st.ActiveDays)))
+	fmt.Fprintf(&b, " %s\n\n", fmt.Sprintf(a.strings.StatsStreak, st.CurrentStreak, st.LongestStreak))
+
+	a.writeTopCounts(&b, a.strings.StatsTopTracks, st.TopTracks, true)
+	a.writeTopCounts(&b, a.strings.StatsTopChannels, st.TopChannels, false)
+
+	a.writeHourChart(&b, st.ByHour)
+
+	fmt.Fprintf(&b, " [%s::b]%s[-::-]\n", colorTag(a.theme.Mauve), a.strings.StatsByWeekday)
+	names := strings.Split(a.strings.StatsWeekdays, ",")
+	peak := 0.0
+	for _, secs := range st.ByWeekday {
+		peak = max(peak, secs)
+	}
+	for i := range 7 {
+		d := (i + 1) % 7
+		name := ""
+		if d < len(names) {
+			name = names[d]
+		}
+		fmt.Fprintf(&b, " %-4s %s [%s]%s[-]\n", name, a.statsBar(st.ByWeekday[d], peak, 30, a.theme.Green),
+			colorTag(a.theme.Subtext0), formatListenTime(st.ByWeekday[d]))
+	}
+
+	a.statsView.SetText(b.String())
+	a.statsView.ScrollToBeginning()
+}
+
+func (a *SimpleApp) writeTopCounts(b *strings.Builder, title string, counts []history.Count, withAuthor bool) {
+	fmt.Fprintf(b, " [%s::b]%s[-::-]\n", colorTag(a.theme.Mauve), title)
+	peak := 0
+	for _, c := range counts {
+		peak = max(peak, c.Plays)
+	}
+	for i, c := range counts {
+		name := truncate(c.Label, 40)
+		if withAuthor && c.Author != "" {
+			name = truncate(c.Label, 28) + " · " + truncate(c.Author, 14)
+		}
+		fmt.Fprintf(b, " %2d. %-45s %s [%s]%d× · %s[-]\n",
+			i+1, tview.Escape(name),
+			a.statsBar(float64(c.Plays), float64(peak), 20, a.theme.Blue),
+			colorTag(a.theme.Subtext0), c.Plays, formatListenTime(c.Seconds))
+	}
+	b.WriteString("\n")
+}
+
+func (a *SimpleApp) writeHourChart(b *strings.Builder, byHour [24]float64) {
+	const height = 6
+
+	fmt.Fprintf(b, " [%s::b]%s[-::-]\n", colorTag(a.theme.Mauve), a.strings.StatsByHour)
+	peak := 0.0
+	for _, secs := range byHour {
+		peak = max(peak, secs)
+	}
+
+	for level := height; level >= 1; level-- {
+		fmt.Fprintf(b, " [%s]", colorTag(a.theme.Sapphire))
+		for _, secs := range byHour {
+			fill := 0.0
+			if peak > 0 {
+				fill = secs / peak * height
+			}
+			block := ' '
+			switch {
+			case fill >= float64(level):
+				block = barBlocks[len(barBlocks)-1]
+			case fill > float64(level-1):
+				block = barBlocks[int((fill-float64(level-1))*float64(len(barBlocks)-1))]
+			}
+			b.WriteString(strings.Repeat(string(block), 2) + " ")
+		}
+		b.WriteString("[-]\n")
+	}
+
+	fmt.Fprintf(b, " [%s]", colorTag(a.theme.Subtext0))
+	for h := range 24 {
+		fmt.Fprintf(b, "%02d ", h)
+	}
+	b.WriteString("[-]\n\n")
+}
+
+func (a *SimpleApp) statsBar(v, peak float64, width int, color tcell.Color) string {
+	filled := 0
+	if peak > 0 {
+		filled = min(int(v/peak*float64(width)+0.5), width)
+	}
+	return fmt.Sprintf("[%s]%s[%s]%s[-]",
+		colorTag(color), strings.Repeat("█", filled),
+		colorTag(a.theme.Surface1), strings.Repeat("░", width-filled))
+}
+
+func (a *SimpleApp) exportStats(format string) {
+	st := a.currentStats()
+
+	path, err := writeStatsExport(st, format)
+	if err != nil {
+		a.setStatusf(a.theme.Red, "❌ "+a.strings.ExportError, err)
+		return
+	}
+	a.setStatusf(a.theme.Green, "✓ "+a.strings.StatsExported, path)
+}
+
+func writeStatsExport(st history.Stats, format string) (string, error) {
+	dir := config.GetExportDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("stats-%s-%s.%s", st.Period, time.Now().Format("20060102-150405"), format))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	if format == "json" {
+		err = st.WriteJSON(f)
+	} else {
+		err = st.WriteCSV(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return path, err
+}
+
+func formatListenTime(secs float64) string {
+	total := int(secs)
+	h, m := total/3600, total%3600/60
+	if h > 0 {
+		return fmt.Sprintf("%dh %02dm", h, m)
+	}
+	return fmt.Sprintf("%dm %02ds", m, total%60)
+}
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"bytes"
 	"context"
 	"crypto/md5"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/IvelOt/youtui-player/internal/library"
 	"github.com/nfnt/resize"
 )
 
@@ -51,6 +53,17 @@ func (tc *ThumbnailCache) getCachePath(url string) string {
 	return filepath.Join(tc.cacheDir, hash+".jpg")
 }
 
+func (tc *ThumbnailCache) CachedPath(url string) string {
+	if url == "" {
+		return ""
+	}
+	path := tc.getCachePath(url)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
 func (tc *ThumbnailCache) downloadImageWithContext(ctx context.Context, url string) (image.Image, error) {
 	client := &http.Client{
 		Timeout: 5 * time.Second,
@@ -82,6 +95,20 @@ func (tc *ThumbnailCache) downloadImageWithContext(ctx context.Context, url stri
 	return img, nil
 }
 
+func (tc *ThumbnailCache) loadEmbeddedCover(path string) (image.Image, error) {
+	data, err := library.Cover(path)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode failed: %w", err)
+	}
+
+	return img, nil
+}
+
 func (tc *ThumbnailCache) GetThumbnailImage(url string) (image.Image, error) {
 	return tc.GetThumbnailImageWithContext(context.Background(), url)
 }
@@ -108,7 +135,13 @@ func (tc *ThumbnailCache) GetThumbnailImageWithContext(ctx context.Context, url
 		}
 	}
 
-	img, err := tc.downloadImageWithContext(ctx, url)
+	var img image.Image
+	var err error
+	if path, ok := library.PathFromURL(url); ok {
+		img, err = tc.loadEmbeddedCover(path)
+	} else {
+		img, err = tc.downloadImageWithContext(ctx, url)
+	}
 	if err != nil {
 		return nil, err
 	}
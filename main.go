@@ -1,15 +1,53 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"os"
 
+	"github.com/IvelOt/youtui-player/internal/cli"
+	"github.com/IvelOt/youtui-player/internal/config"
+	"github.com/IvelOt/youtui-player/internal/daemon"
 	"github.com/IvelOt/youtui-player/internal/ui"
 )
 
 var Version = "dev"
 
+var subcommands = map[string]func(args []string, stdout, stderr io.Writer) int{
+	"ctl":      cli.Ctl,
+	"search":   cli.Search,
+	"playlist": cli.Playlist,
+	"status":   cli.Status,
+}
+
 func main() {
-	app := ui.NewSimpleApp(Version)
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			ui.UseConfiguredLanguage()
+			os.Exit(run(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
+	runDaemon := flag.Bool("daemon", false, "run the player engine headless and serve the control socket")
+	attach := flag.Bool("attach", false, "attach to a running daemon instead of starting a player")
+	flag.Parse()
+
+	if *runDaemon {
+		if err := ui.RunDaemon(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	client, err := daemon.Dial(config.GetSocketPath())
+	if err != nil && *attach {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	app := ui.NewSimpleApp(Version, client)
 
 	if err := app.Run(); err != nil {
 		log.Fatal(err)